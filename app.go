@@ -12,15 +12,23 @@ type App struct {
 	server *http.Server
 	route  *router
 	Router
-	errorHandler ErrorHandler
-	binder       *binder
-	isDebug      bool
-	render       Renderer
-	Logger       Logger
+	errorHandler     ErrorHandler
+	binder           *binder
+	validator        *Validator
+	isDebug          bool
+	render           Renderer
+	jsonCodec        JSONCodec
+	secureJSONPrefix string
+	renderers        map[string]renderer
+	bodyLimit        int64
+	cookieKeys       *keyring
+	trustedProxies   []*net.IPNet
+	Logger           Logger
 }
 
 func New() *App {
 	r := newRouter()
+	v := NewValidator()
 	a := &App{
 		route: r,
 		Router: &Group{
@@ -28,9 +36,16 @@ func New() *App {
 			route:    r,
 			isRoot:   true,
 		},
-		errorHandler: defaultErrorHandler,
-		binder:       &binder{},
-		Logger:       NewLogger(os.Stdout),
+		errorHandler:     defaultErrorHandler,
+		binder:           &binder{},
+		validator:        v,
+		jsonCodec:        stdJSONCodec{},
+		secureJSONPrefix: defaultSecureJSONPrefix,
+		Logger:           NewLogger(os.Stdout),
+	}
+	a.renderers = a.defaultRenderers()
+	v.report = func(err error) {
+		a.Logger.Error(err)
 	}
 	r.app = a
 	return a
@@ -58,6 +73,65 @@ func (a *App) SetValidator(fc Validate, trans ...ValidateTranslate) {
 	}
 }
 
+// Validator returns the built-in validator, e.g. to register rules. Ctx.Bind
+// does not validate until it is enabled with
+// app.SetValidator(app.Validator().Validate), so validate tags written for
+// another validator are left alone. Unknown rules in validate tags are ignored
+// and logged once per struct type; Validator.Check reports them up front.
+func (a *App) Validator() *Validator {
+	return a.validator
+}
+
+// SetValidateTranslate sets the translator applied to validation errors, e.g. to localize messages.
+func (a *App) SetValidateTranslate(trans ValidateTranslate) {
+	a.binder.validateTranslate = trans
+}
+
+// SetBindPrecedence sets the sources used by Ctx.Bind, highest precedence first.
+// Sources that are not listed are not bound.
+func (a *App) SetBindPrecedence(types ...BinderType) {
+	if len(types) == 0 {
+		return
+	}
+	a.binder.precedence = types
+}
+
+// SetJSONDecodeOptions configures the decoder used to bind JSON request bodies.
+func (a *App) SetJSONDecodeOptions(opts JSONDecodeOptions) {
+	a.binder.jsonOptions = opts
+}
+
+// SetJSONCodec replaces encoding/json for JSON responses and binding.
+func (a *App) SetJSONCodec(codec JSONCodec) {
+	if codec == nil {
+		return
+	}
+	a.jsonCodec = codec
+}
+
+// SetSecureJSONPrefix sets the prefix written by Ctx.SecureJSON.
+func (a *App) SetSecureJSONPrefix(prefix string) {
+	a.secureJSONPrefix = prefix
+}
+
+// SetBodyLimit limits every request body to limit bytes. Reading beyond it fails
+// with ErrBodyTooLarge, answered with 413 by the default error handler.
+// Ctx.SetBodyLimit overrides the limit of a single request.
+func (a *App) SetBodyLimit(limit int64) {
+	a.bodyLimit = limit
+}
+
+// SetCookieKeys sets the keys of signed and encrypted cookies. The first key
+// signs and encrypts, all of them verify and decrypt, so that keys can be
+// rotated by prepending a new one. Keys should be at least 32 random bytes.
+func (a *App) SetCookieKeys(keys ...[]byte) {
+	if len(keys) == 0 {
+		a.cookieKeys = nil
+		return
+	}
+	a.cookieKeys = newKeyring(keys)
+}
+
 func (a *App) SetErrorHandler(fc ErrorHandler) {
 	if fc == nil {
 		return
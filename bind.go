@@ -1,8 +1,9 @@
 package bytego
 
 import (
-	"encoding/json"
 	"encoding/xml"
+	"fmt"
+	"net/http"
 	"reflect"
 	"strconv"
 	"strings"
@@ -19,29 +20,85 @@ const (
 	MIMEMultipartPOSTForm = "multipart/form-data"
 	MIMEPROTOBUF          = "application/x-protobuf"
 	MIMEYAML              = "application/x-yaml"
+
+	defaultMultipartMemory = 32 << 20 // 32 MB
+	defaultTag             = "default"
+)
+
+type BinderType uint8
+
+const (
+	BinderParam BinderType = iota + 1
+	BinderQuery
+	BinderHeader
+	BinderBody
+	BinderJSON
+	BinderXML
+	BinderForm
 )
 
+func (t BinderType) String() string {
+	switch t {
+	case BinderParam:
+		return "param"
+	case BinderQuery:
+		return "query"
+	case BinderHeader:
+		return "header"
+	case BinderBody:
+		return "body"
+	case BinderJSON:
+		return "json"
+	case BinderXML:
+		return "xml"
+	case BinderForm:
+		return "form"
+	}
+	return "unknown"
+}
+
+// defaultBindPrecedence lists the sources used by Bind, highest precedence first.
+var defaultBindPrecedence = []BinderType{BinderBody, BinderHeader, BinderQuery, BinderParam}
+
 type binder struct {
 	validate          Validate
 	validateTranslate ValidateTranslate
+	precedence        []BinderType
+	jsonOptions       JSONDecodeOptions
 }
 
 func (b *binder) Bind(c *Ctx, i interface{}) error {
-	if err := b.bindDefault(i, "default"); err != nil {
-		return err
+	precedence := b.precedence
+	if len(precedence) == 0 {
+		precedence = defaultBindPrecedence
 	}
-	if err := b.bindParams(c, i); err != nil {
-		return err
-	}
-	if err := b.bindQueries(c, i); err != nil {
-		return err
+	return b.BindWith(c, i, precedence...)
+}
+
+// BindWith binds default values and then the given sources into i. Sources are
+// listed highest precedence first, so a value found in an earlier source is
+// never overwritten by a later one.
+func (b *binder) BindWith(c *Ctx, i interface{}, types ...BinderType) error {
+	var errs BindErrors
+	if err := b.bindDefault(i, defaultTag); err != nil {
+		if !errs.merge(err) {
+			return err
+		}
 	}
-	if err := b.bindHeaders(c, i); err != nil {
-		return err
+	for j := len(types) - 1; j >= 0; j-- {
+		if err := b.bindSource(c, i, types[j]); err != nil {
+			if !errs.merge(err) {
+				return err
+			}
+		}
 	}
-	if err := b.bindBody(c, i); err != nil {
-		return err
+	if len(errs) > 0 {
+		return errs
 	}
+	return b.validateStruct(c, i)
+}
+
+func (b *binder) validateStruct(c *Ctx, i interface{}) error {
 	if b.validate != nil {
 		err := b.validate(i)
 		if err != nil && b.validateTranslate != nil {
@@ -52,20 +109,41 @@ func (b *binder) Bind(c *Ctx, i interface{}) error {
 	return nil
 }
 
+func (b *binder) bindSource(c *Ctx, i interface{}, t BinderType) error {
+	switch t {
+	case BinderParam:
+		return b.bindParams(c, i)
+	case BinderQuery:
+		return b.bindQueries(c, i)
+	case BinderHeader:
+		return b.bindHeaders(c, i)
+	case BinderBody:
+		return b.bindBody(c, i)
+	case BinderJSON:
+		return b.bindJSON(c, i)
+	case BinderXML:
+		return b.bindXML(c, i)
+	case BinderForm:
+		return b.bindForm(c, i)
+	}
+	return fmt.Errorf("bind: unknown binder type %d", t)
+}
+
 func (b *binder) bindParams(c *Ctx, i interface{}) error {
-	params := map[string][]string{}
+	if len(c.Params) == 0 {
+		return nil
+	}
+	params := make(map[string][]string, len(c.Params))
 	for _, p := range c.Params {
 		params[p.Key] = []string{p.Value}
 	}
-	if err := b.bindData(i, params, "param", ""); err != nil {
-		return err
-	}
-	return nil
+	return b.bindData(i, params, "param", "")
 }
 
 func (b *binder) bindQueries(c *Ctx, i interface{}) error {
-	return b.bindData(i, c.Request.URL.Query(), "query", "")
+	return b.bindData(i, c.queryValues(), "query", "")
 }
+
 func (b *binder) bindHeaders(c *Ctx, i interface{}) error {
 	return b.bindData(i, c.Request.Header, "header", "")
 }
@@ -76,206 +154,210 @@ func (b *binder) bindBody(c *Ctx, i interface{}) error {
 	}
 	switch c.ContentType() {
 	case MIMEJSON:
-		return json.NewDecoder(c.Request.Body).Decode(i)
+		return b.bindJSON(c, i)
 	case MIMEXML, MIMEXML2:
-		return xml.NewDecoder(c.Request.Body).Decode(i)
+		return b.bindXML(c, i)
 	case MIMEPOSTForm, MIMEMultipartPOSTForm:
-		if err := c.Request.ParseForm(); err != nil {
+		return b.bindForm(c, i)
+	}
+	return nil
+}
+
+func (b *binder) bindJSON(c *Ctx, i interface{}) error {
+	if c.Request.Body == nil || c.Request.ContentLength == 0 {
+		return nil
+	}
+	dec := c.app.jsonCodec.NewDecoder(c.Request.Body)
+	if b.jsonOptions.DisallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	if b.jsonOptions.UseNumber {
+		dec.UseNumber()
+	}
+	if err := dec.Decode(i); err != nil {
+		return newDecodeBindError(BinderJSON.String(), err)
+	}
+	return nil
+}
+
+func (b *binder) bindXML(c *Ctx, i interface{}) error {
+	if c.Request.Body == nil || c.Request.ContentLength == 0 {
+		return nil
+	}
+	if err := xml.NewDecoder(c.Request.Body).Decode(i); err != nil {
+		return newDecodeBindError(BinderXML.String(), err)
+	}
+	return nil
+}
+
+func (b *binder) bindForm(c *Ctx, i interface{}) error {
+	if c.ContentType() == MIMEMultipartPOSTForm {
+		if err := c.Request.ParseMultipartForm(defaultMultipartMemory); err != nil && err != http.ErrNotMultipart {
 			return err
 		}
-		return b.bindData(i, c.Request.PostForm, "form", "")
+	} else if err := c.Request.ParseForm(); err != nil {
+		return err
 	}
-	return nil
+	return b.bindData(i, c.Request.PostForm, "form", "")
 }
 
 func (b *binder) bindData(dest interface{}, data map[string][]string, tag string, parentTagValue string) error {
 	if dest == nil || len(data) == 0 {
 		return nil
 	}
-	dtype := reflect.TypeOf(dest).Elem()
 	dval := reflect.ValueOf(dest).Elem()
-	if dtype.Kind() == reflect.Map {
+	switch dval.Kind() {
+	case reflect.Map:
 		for k, v := range data {
 			dval.SetMapIndex(reflect.ValueOf(k), reflect.ValueOf(v[0]))
 		}
-		return nil
-	}
-	if dtype.Kind() != reflect.Struct {
-		return nil
+	case reflect.Struct:
+		var errs BindErrors
+		b.bindStruct(dval, data, tag, parentTagValue, &errs)
+		if len(errs) > 0 {
+			return errs
+		}
 	}
+	return nil
+}
 
-	//struct
-	for i := 0; i < dtype.NumField(); i++ {
-		field := dtype.Field(i)
-		filedVal := dval.Field(i)
-		if field.Anonymous {
-			if filedVal.Kind() == reflect.Ptr {
-				if filedVal.IsNil() {
-					ptr := reflect.New(filedVal.Type().Elem())
-					filedVal.Set(ptr)
-				}
-				if err := b.bindData(filedVal.Interface(), data, tag, ""); err != nil {
-					return err
-				}
-			} else if filedVal.Kind() == reflect.Struct {
-				if err := b.bindData(filedVal.Addr().Interface(), data, tag, ""); err != nil {
-					return err
-				}
-			}
+// bindStruct binds data into dval, collecting conversion failures of all fields into errs.
+func (b *binder) bindStruct(dval reflect.Value, data map[string][]string, tag string, parentTagValue string, errs *BindErrors) {
+	plan := cachedStructPlan(dval.Type(), tag)
+	for i := range plan.fields {
+		fp := &plan.fields[i]
+		fieldVal := dval.Field(fp.index)
+		switch fp.kind {
+		case fieldEmbedded:
+			b.bindStruct(fieldVal, data, tag, "", errs)
 			continue
-		}
-		if !filedVal.CanSet() {
-			continue
-		}
-		tagValue, ok := field.Tag.Lookup(tag)
-		tagName := b.getTag(tagValue) //header,param,query,param
-		if tagName == "-" {
+		case fieldEmbeddedPtr:
+			if fieldVal.IsNil() {
+				fieldVal.Set(reflect.New(fp.elemType))
+			}
+			b.bindStruct(fieldVal.Elem(), data, tag, "", errs)
 			continue
 		}
-		var fullTagName string
-		if tagName == "" {
-			fullTagName = field.Name
-		} else {
-			fullTagName = tagName
-		}
+
+		fullTagName := fp.name
 		if parentTagValue != "" {
 			fullTagName = parentTagValue + "." + fullTagName
 		}
 
-		switch filedVal.Kind() {
-		case reflect.Struct:
-			if err := b.bindData(filedVal.Addr().Interface(), data, tag, fullTagName); err != nil {
-				return err
+		switch fp.kind {
+		case fieldStruct:
+			b.bindStruct(fieldVal, data, tag, fullTagName, errs)
+		case fieldStructPtr:
+			if fieldVal.IsNil() && hasPrefixData(data, fullTagName+".") {
+				fieldVal.Set(reflect.New(fp.elemType))
 			}
-			continue
-		case reflect.Ptr:
-			if filedVal.IsNil() && fullTagName != "" {
-				for k := range data {
-					if strings.HasPrefix(strings.ToLower(k), strings.ToLower(fullTagName+".")) {
-						ptr := reflect.New(filedVal.Type().Elem())
-						filedVal.Set(ptr)
-						break
-					}
-				}
+			if !fieldVal.IsNil() {
+				b.bindStruct(fieldVal.Elem(), data, tag, fullTagName, errs)
 			}
-			if !filedVal.IsNil() {
-				if err := b.bindData(filedVal.Interface(), data, tag, fullTagName); err != nil {
-					return err
+		case fieldSlice:
+			val, exists := b.findIgnoreCaseData(data, fullTagName)
+			if !exists || len(val) == 0 {
+				continue
+			}
+			slice := reflect.MakeSlice(fieldVal.Type(), len(val), len(val))
+			failed := false
+			for i, v := range val {
+				if err := fp.set(slice.Index(i), v); err != nil {
+					*errs = append(*errs, newBindError(fullTagName+"["+strconv.Itoa(i)+"]", tag, v, slice.Index(i).Type(), err))
+					failed = true
 				}
 			}
-			continue
-		case reflect.Slice:
+			if !failed {
+				fieldVal.Set(slice)
+			}
+		case fieldValue, fieldValuePtr:
+			if !fp.hasTag {
+				continue
+			}
 			val, exists := b.findIgnoreCaseData(data, fullTagName)
 			if !exists || len(val) == 0 {
 				continue
 			}
-			slice := reflect.MakeSlice(filedVal.Type(), len(val), len(val))
-			for i, v := range val {
-				if err := b.setField(slice.Index(i), field, v); err != nil {
-					return err
+			if fp.kind == fieldValuePtr {
+				if fieldVal.IsNil() {
+					fieldVal.Set(reflect.New(fp.elemType))
 				}
+				fieldVal = fieldVal.Elem()
+			}
+			if err := fp.set(fieldVal, val[0]); err != nil {
+				*errs = append(*errs, newBindError(fullTagName, tag, val[0], fieldVal.Type(), err))
 			}
-			filedVal.Set(slice)
-			continue
-		}
-
-		if !ok {
-			continue
-		}
-		val, exists := b.findIgnoreCaseData(data, fullTagName)
-		if !exists || len(val) == 0 {
-			continue
-		}
-		err := b.setField(filedVal, field, val[0])
-		if err != nil {
-			return err
 		}
 	}
-	return nil
 }
 
 func (b *binder) bindDefault(dest interface{}, defaultTagName string) error {
 	if dest == nil || len(defaultTagName) == 0 {
 		return nil
 	}
-	dtype := reflect.TypeOf(dest).Elem()
 	dval := reflect.ValueOf(dest).Elem()
-	if dtype.Kind() != reflect.Struct {
+	if dval.Kind() != reflect.Struct {
 		return nil
 	}
+	return b.bindStructDefault(dval, defaultTagName)
+}
 
-	//struct
-	for i := 0; i < dtype.NumField(); i++ {
-		field := dtype.Field(i)
-		filedVal := dval.Field(i)
-		if field.Anonymous { //embedded
-			if filedVal.Kind() == reflect.Ptr {
-				if filedVal.IsNil() {
-					ptr := reflect.New(filedVal.Type().Elem())
-					filedVal.Set(ptr)
-				}
-				if err := b.bindDefault(filedVal.Interface(), defaultTagName); err != nil {
-					return err
-				}
-			}
-		}
-		if !filedVal.CanSet() {
-			continue
-		}
-
-		val, ok := field.Tag.Lookup(defaultTagName)
-		defaultValue := b.getTag(val)
-
-		switch filedVal.Kind() {
-		case reflect.Struct:
-			if err := b.bindDefault(filedVal.Addr().Interface(), defaultTagName); err != nil {
+func (b *binder) bindStructDefault(dval reflect.Value, defaultTagName string) error {
+	plan := cachedStructPlan(dval.Type(), defaultTagName)
+	for i := range plan.fields {
+		fp := &plan.fields[i]
+		fieldVal := dval.Field(fp.index)
+		switch fp.kind {
+		case fieldEmbedded, fieldStruct:
+			if err := b.bindStructDefault(fieldVal, defaultTagName); err != nil {
 				return err
 			}
-			continue
-		case reflect.Ptr:
-			if filedVal.IsNil() && defaultValue != "" {
-				ptr := reflect.New(filedVal.Type().Elem())
-				filedVal.Set(ptr)
+		case fieldEmbeddedPtr, fieldStructPtr:
+			if fieldVal.IsNil() && (fp.kind == fieldEmbeddedPtr || fp.name != "") {
+				fieldVal.Set(reflect.New(fp.elemType))
 			}
-			if !filedVal.IsNil() {
-				kind := filedVal.Type().Elem().Kind()
-				if kind == reflect.Struct {
-					if err := b.bindDefault(filedVal.Interface(), defaultTagName); err != nil {
-						return err
-					}
-				} else {
-					if err := b.setField(filedVal.Elem(), field, defaultValue); err != nil {
-						return err
-					}
+			if !fieldVal.IsNil() {
+				if err := b.bindStructDefault(fieldVal.Elem(), defaultTagName); err != nil {
+					return err
 				}
 			}
-			continue
-		case reflect.Slice:
-			if val != "" {
-				vals := strings.Split(val, ",")
-				if len(vals) > 0 {
-					slice := reflect.MakeSlice(filedVal.Type(), len(vals), len(vals))
-					for i, v := range vals {
-						if err := b.setField(slice.Index(i), field, strings.TrimSpace(v)); err != nil {
-							return err
-						}
-					}
-					filedVal.Set(slice)
+		case fieldSlice:
+			if len(fp.defaults) == 0 {
+				continue
+			}
+			slice := reflect.MakeSlice(fieldVal.Type(), len(fp.defaults), len(fp.defaults))
+			for i, v := range fp.defaults {
+				if err := fp.set(slice.Index(i), v); err != nil {
+					return defaultValueError(dval.Type(), fp.index, v, err)
 				}
 			}
-			continue
-		}
-		if !ok {
-			continue
-		}
-		err := b.setField(filedVal, field, defaultValue)
-		if err != nil {
-			return err
+			fieldVal.Set(slice)
+		case fieldValuePtr:
+			if !fp.hasTag || fp.name == "" {
+				continue
+			}
+			if fieldVal.IsNil() {
+				fieldVal.Set(reflect.New(fp.elemType))
+			}
+			if err := fp.set(fieldVal.Elem(), fp.name); err != nil {
+				return defaultValueError(dval.Type(), fp.index, fp.name, err)
+			}
+		case fieldValue:
+			if !fp.hasTag {
+				continue
+			}
+			if err := fp.set(fieldVal, fp.name); err != nil {
+				return defaultValueError(dval.Type(), fp.index, fp.name, err)
+			}
 		}
 	}
 	return nil
 }
 
+func defaultValueError(t reflect.Type, index int, val string, err error) error {
+	return fmt.Errorf("bind: invalid default value %q for field %s.%s: %w", val, t.Name(), t.Field(index).Name, err)
+}
+
 func (b *binder) findIgnoreCaseData(data map[string][]string, key string) (val []string, exists bool) {
 	val, exists = data[key]
 	if !exists {
@@ -290,58 +372,13 @@ func (b *binder) findIgnoreCaseData(data map[string][]string, key string) (val [
 	return
 }
 
-func (b *binder) getTag(tag string) string {
-	idx := strings.Index(tag, ",")
-	if idx < 0 {
-		return tag
-	}
-	return tag[:idx]
-}
-
-func (b *binder) setField(fieldVal reflect.Value, field reflect.StructField, val string) error {
-	switch fieldVal.Kind() {
-	case reflect.String:
-		fieldVal.SetString(val)
-	case reflect.Int:
-		return setIntField(fieldVal, val, 0)
-	case reflect.Int8:
-		return setIntField(fieldVal, val, 8)
-	case reflect.Int16:
-		return setIntField(fieldVal, val, 16)
-	case reflect.Int32:
-		return setIntField(fieldVal, val, 32)
-	case reflect.Int64:
-		switch fieldVal.Interface().(type) {
-		case time.Duration:
-			return setTimeDuration(fieldVal, val)
+func hasPrefixData(data map[string][]string, prefix string) bool {
+	for k := range data {
+		if len(k) >= len(prefix) && strings.EqualFold(k[:len(prefix)], prefix) {
+			return true
 		}
-		return setIntField(fieldVal, val, 64)
-	case reflect.Uint:
-		return setUintField(fieldVal, val, 0)
-	case reflect.Uint8:
-		return setUintField(fieldVal, val, 8)
-	case reflect.Uint16:
-		return setUintField(fieldVal, val, 16)
-	case reflect.Uint32:
-		return setUintField(fieldVal, val, 32)
-	case reflect.Uint64:
-		return setUintField(fieldVal, val, 64)
-	case reflect.Bool:
-		return setBoolField(fieldVal, val)
-	case reflect.Float32:
-		return setFloatField(fieldVal, val, 32)
-	case reflect.Float64:
-		return setFloatField(fieldVal, val, 64)
-	case reflect.Struct:
-		switch fieldVal.Interface().(type) {
-		case time.Time:
-			return setTimeField(fieldVal, val, field)
-		}
-		return json.Unmarshal(stringToBytes(val), fieldVal.Addr().Interface())
-	case reflect.Map:
-		return json.Unmarshal(stringToBytes(val), fieldVal.Addr().Interface())
 	}
-	return nil
+	return false
 }
 
 func setIntField(field reflect.Value, val string, bitSize int) error {
@@ -360,7 +397,7 @@ func setTimeDuration(field reflect.Value, val string) error {
 	if err != nil {
 		return err
 	}
-	field.Set(reflect.ValueOf(d))
+	field.SetInt(int64(d))
 	return nil
 }
 
@@ -397,12 +434,7 @@ func setFloatField(field reflect.Value, val string, bitSize int) error {
 	return err
 }
 
-func setTimeField(value reflect.Value, val string, structField reflect.StructField) error {
-	timeFormat := structField.Tag.Get("time_format")
-	if timeFormat == "" {
-		timeFormat = time.RFC3339
-	}
-
+func setTimeField(value reflect.Value, val string, timeFormat string, l *time.Location) error {
 	switch tf := strings.ToLower(timeFormat); tf {
 	case "unix", "unixnano":
 		tv, err := strconv.ParseInt(val, 10, 64)
@@ -415,34 +447,21 @@ func setTimeField(value reflect.Value, val string, structField reflect.StructFie
 			d = time.Second
 		}
 
-		t := time.Unix(tv/int64(d), tv%int64(d))
-		value.Set(reflect.ValueOf(t))
+		*value.Addr().Interface().(*time.Time) = time.Unix(tv/int64(d), tv%int64(d))
 		return nil
 	}
 
 	if val == "" {
-		value.Set(reflect.ValueOf(time.Time{}))
+		*value.Addr().Interface().(*time.Time) = time.Time{}
 		return nil
 	}
 
-	l := time.Local
-	if isUTC, _ := strconv.ParseBool(structField.Tag.Get("time_utc")); isUTC {
-		l = time.UTC
-	}
-
-	if locTag := structField.Tag.Get("time_location"); locTag != "" {
-		loc, err := time.LoadLocation(locTag)
-		if err != nil {
-			return err
-		}
-		l = loc
-	}
-
 	t, err := time.ParseInLocation(timeFormat, val, l)
 	if err != nil {
 		return err
 	}
 
-	value.Set(reflect.ValueOf(t))
+	// set through the pointer, reflect.ValueOf(t) would allocate to box t
+	*value.Addr().Interface().(*time.Time) = t
 	return nil
 }
@@ -0,0 +1,202 @@
+package bytego
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type fieldKind uint8
+
+const (
+	fieldEmbedded fieldKind = iota
+	fieldEmbeddedPtr
+	fieldStruct
+	fieldStructPtr
+	fieldSlice
+	fieldValue
+	fieldValuePtr
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+type fieldSetter func(v reflect.Value, val string) error
+
+// fieldPlan is the precomputed binding metadata of a single struct field for one tag.
+type fieldPlan struct {
+	index    int
+	kind     fieldKind
+	name     string // tag name, or the field name when the tag is empty
+	hasTag   bool
+	elemType reflect.Type // pointed-to type for pointer kinds
+	set      fieldSetter  // converter of the value, slice element or pointed-to value
+	defaults []string     // comma separated values of a slice default tag
+}
+
+type structPlan struct {
+	fields []fieldPlan
+}
+
+type planKey struct {
+	typ reflect.Type
+	tag string
+}
+
+// structPlans caches *structPlan by planKey.
+var structPlans sync.Map
+
+func cachedStructPlan(t reflect.Type, tag string) *structPlan {
+	key := planKey{typ: t, tag: tag}
+	if p, ok := structPlans.Load(key); ok {
+		return p.(*structPlan)
+	}
+	p, _ := structPlans.LoadOrStore(key, newStructPlan(t, tag))
+	return p.(*structPlan)
+}
+
+func newStructPlan(t reflect.Type, tag string) *structPlan {
+	plan := &structPlan{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" { //unexported
+			continue
+		}
+		fp := fieldPlan{index: i}
+		ft := field.Type
+		if field.Anonymous {
+			switch {
+			case ft.Kind() == reflect.Struct:
+				fp.kind = fieldEmbedded
+			case ft.Kind() == reflect.Ptr && ft.Elem().Kind() == reflect.Struct:
+				fp.kind = fieldEmbeddedPtr
+				fp.elemType = ft.Elem()
+			default:
+				continue
+			}
+			plan.fields = append(plan.fields, fp)
+			continue
+		}
+
+		tagValue, ok := field.Tag.Lookup(tag)
+		fp.hasTag = ok
+		fp.name = getTag(tagValue)
+		if fp.name == "-" {
+			continue
+		}
+		if fp.name == "" && tag != defaultTag {
+			fp.name = field.Name
+		}
+
+		switch {
+		case ft.Kind() == reflect.Struct && ft != timeType:
+			fp.kind = fieldStruct
+		case ft.Kind() == reflect.Ptr && ft.Elem().Kind() == reflect.Struct && ft.Elem() != timeType:
+			fp.kind = fieldStructPtr
+			fp.elemType = ft.Elem()
+		case ft.Kind() == reflect.Ptr:
+			fp.kind = fieldValuePtr
+			fp.elemType = ft.Elem()
+			fp.set = newFieldSetter(ft.Elem(), field)
+		case ft.Kind() == reflect.Slice:
+			fp.kind = fieldSlice
+			fp.set = newFieldSetter(ft.Elem(), field)
+			if tag == defaultTag && tagValue != "" {
+				for _, v := range strings.Split(tagValue, ",") {
+					fp.defaults = append(fp.defaults, strings.TrimSpace(v))
+				}
+			}
+		default:
+			fp.kind = fieldValue
+			fp.set = newFieldSetter(ft, field)
+		}
+		if fp.set == nil && fp.kind >= fieldSlice {
+			continue
+		}
+		plan.fields = append(plan.fields, fp)
+	}
+	return plan
+}
+
+func newFieldSetter(t reflect.Type, field reflect.StructField) fieldSetter {
+	switch t.Kind() {
+	case reflect.String:
+		return func(v reflect.Value, val string) error {
+			v.SetString(val)
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if t == durationType {
+			return setTimeDuration
+		}
+		bitSize := t.Bits()
+		if t.Kind() == reflect.Int {
+			bitSize = 0
+		}
+		return func(v reflect.Value, val string) error {
+			return setIntField(v, val, bitSize)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		bitSize := t.Bits()
+		if t.Kind() == reflect.Uint {
+			bitSize = 0
+		}
+		return func(v reflect.Value, val string) error {
+			return setUintField(v, val, bitSize)
+		}
+	case reflect.Bool:
+		return setBoolField
+	case reflect.Float32, reflect.Float64:
+		bitSize := t.Bits()
+		return func(v reflect.Value, val string) error {
+			return setFloatField(v, val, bitSize)
+		}
+	case reflect.Struct:
+		if t == timeType {
+			return newTimeSetter(field)
+		}
+		return setJSONField
+	case reflect.Map:
+		return setJSONField
+	}
+	return nil
+}
+
+func setJSONField(v reflect.Value, val string) error {
+	return json.Unmarshal(stringToBytes(val), v.Addr().Interface())
+}
+
+// newTimeSetter resolves the time_format, time_utc and time_location tags once.
+func newTimeSetter(field reflect.StructField) fieldSetter {
+	timeFormat := field.Tag.Get("time_format")
+	if timeFormat == "" {
+		timeFormat = time.RFC3339
+	}
+	l := time.Local
+	if isUTC, _ := strconv.ParseBool(field.Tag.Get("time_utc")); isUTC {
+		l = time.UTC
+	}
+	var locErr error
+	if locTag := field.Tag.Get("time_location"); locTag != "" {
+		l, locErr = time.LoadLocation(locTag)
+	}
+	return func(v reflect.Value, val string) error {
+		if locErr != nil {
+			return locErr
+		}
+		return setTimeField(v, val, timeFormat, l)
+	}
+}
+
+func getTag(tag string) string {
+	idx := strings.Index(tag, ",")
+	if idx < 0 {
+		return tag
+	}
+	return tag[:idx]
+}
@@ -1,7 +1,13 @@
 package bytego
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func Test_binder_bindDefault(t *testing.T) {
@@ -26,7 +32,7 @@ func Test_binder_bindDefault(t *testing.T) {
 	t.Run("bind default test", func(t *testing.T) {
 		b := &binder{}
 		v := &struct1{}
-		if err := b.bindDefault(v, "default"); err != nil {
+		if err := b.bindDefault(v, defaultTag); err != nil {
 			t.Errorf("binder.bindDefault() error = %v", err)
 		}
 		if v.Int != 1 {
@@ -58,3 +64,309 @@ func Test_binder_bindDefault(t *testing.T) {
 		}
 	})
 }
+
+func newTestCtx(app *App, req *http.Request, params ...Param) *Ctx {
+	c := &Ctx{app: app}
+	c.reset()
+	c.Request = req
+	c.writer = newResponseWriter(httptest.NewRecorder(), app)
+	c.Response = c.writer
+	c.Params = params
+	return c
+}
+
+func Test_binder_bindWith(t *testing.T) {
+	type form struct {
+		ID   int    `param:"id" query:"id" json:"id"`
+		Name string `header:"name" query:"name" json:"name"`
+		Page int    `query:"page" default:"1"`
+	}
+	newReq := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/users/7?id=8&name=query", strings.NewReader(`{"name":"body"}`))
+		req.Header.Set("Content-Type", MIMEJSON)
+		req.Header.Set("Name", "header")
+		return req
+	}
+
+	t.Run("bind single source", func(t *testing.T) {
+		app := New()
+		v := &form{}
+		if err := newTestCtx(app, newReq(), Param{Key: "id", Value: "7"}).BindQuery(v); err != nil {
+			t.Fatal(err)
+		}
+		if v.ID != 8 || v.Name != "query" || v.Page != 1 {
+			t.Errorf("BindQuery() = %+v", v)
+		}
+		v = &form{}
+		if err := newTestCtx(app, newReq()).BindJSON(v); err != nil {
+			t.Fatal(err)
+		}
+		if v.ID != 0 || v.Name != "body" {
+			t.Errorf("BindJSON() = %+v", v)
+		}
+	})
+
+	t.Run("bind default precedence", func(t *testing.T) {
+		app := New()
+		v := &form{}
+		if err := newTestCtx(app, newReq(), Param{Key: "id", Value: "7"}).Bind(v); err != nil {
+			t.Fatal(err)
+		}
+		if v.ID != 8 || v.Name != "body" {
+			t.Errorf("Bind() = %+v", v)
+		}
+	})
+
+	t.Run("bind custom precedence", func(t *testing.T) {
+		app := New()
+		app.SetBindPrecedence(BinderParam, BinderHeader)
+		v := &form{}
+		if err := newTestCtx(app, newReq(), Param{Key: "id", Value: "7"}).Bind(v); err != nil {
+			t.Fatal(err)
+		}
+		if v.ID != 7 || v.Name != "header" {
+			t.Errorf("Bind() = %+v", v)
+		}
+	})
+}
+
+type benchBindForm struct {
+	ID      int       `query:"id" default:"1"`
+	Name    string    `query:"name" default:"guest"`
+	Tags    []string  `query:"tags"`
+	Score   float64   `query:"score"`
+	Active  bool      `query:"active"`
+	Created time.Time `query:"created" time_format:"2006-01-02"`
+	Page    struct {
+		Num  int `query:"num" default:"1"`
+		Size int `query:"size" default:"20"`
+	} `query:"page"`
+}
+
+var benchBindData = map[string][]string{
+	"id":        {"42"},
+	"name":      {"bytego"},
+	"tags":      {"a", "b", "c"},
+	"score":     {"9.5"},
+	"active":    {"true"},
+	"created":   {"2022-01-02"},
+	"page.num":  {"3"},
+	"page.size": {"50"},
+}
+
+func Benchmark_binder_bind(b *testing.B) {
+	bd := &binder{}
+	// reflect walks the struct fields and tags on every call, as binding did
+	// before the field plans were cached, and is kept as the baseline.
+	b.Run("reflect", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			v := &benchBindForm{}
+			if err := reflectBindDefault(reflect.ValueOf(v).Elem()); err != nil {
+				b.Fatal(err)
+			}
+			if err := reflectBindData(reflect.ValueOf(v).Elem(), benchBindData, "query", ""); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("plan", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			v := &benchBindForm{}
+			if err := bd.bindDefault(v, defaultTag); err != nil {
+				b.Fatal(err)
+			}
+			if err := bd.bindData(v, benchBindData, "query", ""); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func reflectBindData(dval reflect.Value, data map[string][]string, tag string, parentTagValue string) error {
+	dtype := dval.Type()
+	for i := 0; i < dtype.NumField(); i++ {
+		field := dtype.Field(i)
+		fieldVal := dval.Field(i)
+		if !fieldVal.CanSet() {
+			continue
+		}
+		tagValue, ok := field.Tag.Lookup(tag)
+		fullTagName := getTag(tagValue)
+		if fullTagName == "-" {
+			continue
+		}
+		if fullTagName == "" {
+			fullTagName = field.Name
+		}
+		if parentTagValue != "" {
+			fullTagName = parentTagValue + "." + fullTagName
+		}
+		if fieldVal.Kind() == reflect.Struct && field.Type != timeType {
+			if err := reflectBindData(fieldVal, data, tag, fullTagName); err != nil {
+				return err
+			}
+			continue
+		}
+		if !ok {
+			continue
+		}
+		val, exists := (&binder{}).findIgnoreCaseData(data, fullTagName)
+		if !exists || len(val) == 0 {
+			continue
+		}
+		if fieldVal.Kind() == reflect.Slice {
+			slice := reflect.MakeSlice(fieldVal.Type(), len(val), len(val))
+			for j, v := range val {
+				if err := reflectSetField(slice.Index(j), field, v); err != nil {
+					return err
+				}
+			}
+			fieldVal.Set(slice)
+			continue
+		}
+		if err := reflectSetField(fieldVal, field, val[0]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func reflectBindDefault(dval reflect.Value) error {
+	dtype := dval.Type()
+	for i := 0; i < dtype.NumField(); i++ {
+		field := dtype.Field(i)
+		fieldVal := dval.Field(i)
+		if fieldVal.Kind() == reflect.Struct && field.Type != timeType {
+			if err := reflectBindDefault(fieldVal); err != nil {
+				return err
+			}
+			continue
+		}
+		val, ok := field.Tag.Lookup(defaultTag)
+		if !ok || fieldVal.Kind() == reflect.Slice {
+			continue
+		}
+		if err := reflectSetField(fieldVal, field, getTag(val)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func reflectSetField(fieldVal reflect.Value, field reflect.StructField, val string) error {
+	switch fieldVal.Kind() {
+	case reflect.String:
+		fieldVal.SetString(val)
+	case reflect.Int:
+		return setIntField(fieldVal, val, 0)
+	case reflect.Bool:
+		return setBoolField(fieldVal, val)
+	case reflect.Float64:
+		return setFloatField(fieldVal, val, 64)
+	case reflect.Struct:
+		timeFormat := field.Tag.Get("time_format")
+		if timeFormat == "" {
+			timeFormat = time.RFC3339
+		}
+		return setTimeField(fieldVal, val, timeFormat, time.Local)
+	}
+	return nil
+}
+
+func Test_binder_bindData(t *testing.T) {
+	v := &benchBindForm{}
+	b := &binder{}
+	if err := b.bindData(v, benchBindData, "query", ""); err != nil {
+		t.Fatal(err)
+	}
+	if v.ID != 42 || v.Name != "bytego" || len(v.Tags) != 3 || v.Score != 9.5 || !v.Active {
+		t.Errorf("bindData() = %+v", v)
+	}
+	if v.Created.Format("2006-01-02") != "2022-01-02" {
+		t.Errorf("bind time error: %v", v.Created)
+	}
+	if v.Page.Num != 3 || v.Page.Size != 50 {
+		t.Errorf("bind nested struct error: %+v", v.Page)
+	}
+}
+
+func Test_binder_bindErrors(t *testing.T) {
+	type form struct {
+		ID    int    `query:"id"`
+		Page  uint8  `query:"page"`
+		Tags  []int  `query:"tags"`
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+	app := New()
+	req := httptest.NewRequest(http.MethodPost, "/?id=abc&page=300&tags=1&tags=x", strings.NewReader(`{"name":"a","count":"b"}`))
+	req.Header.Set("Content-Type", MIMEJSON)
+	c := newTestCtx(app, req)
+	err := c.Bind(&form{})
+	errs, ok := err.(BindErrors)
+	if !ok {
+		t.Fatalf("Bind() error = %v, want BindErrors", err)
+	}
+	want := []string{"query id int", "query page uint8", "query tags[1] int", "json count int"}
+	got := make([]string, len(errs))
+	for i, e := range errs {
+		got[i] = e.Source + " " + e.Field + " " + e.Type
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Bind() errors = %v, want %v", got, want)
+	}
+
+	rec := httptest.NewRecorder()
+	c.writer = newResponseWriter(rec, app)
+	c.Response = c.writer
+	c.HandleError(err)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), `"field":"tags[1]"`) {
+		t.Errorf("HandleError() = %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func Test_binder_bindJSONOptions(t *testing.T) {
+	type form struct {
+		Name string      `json:"name"`
+		Data interface{} `json:"data"`
+	}
+	newReq := func(body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", MIMEJSON)
+		return req
+	}
+
+	t.Run("strict decoding", func(t *testing.T) {
+		app := New()
+		app.SetJSONDecodeOptions(JSONDecodeOptions{DisallowUnknownFields: true, UseNumber: true})
+		v := &form{}
+		if err := newTestCtx(app, newReq(`{"name":"a","data":1}`)).BindJSON(v); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := v.Data.(json.Number); !ok {
+			t.Errorf("UseNumber: data = %T", v.Data)
+		}
+		err := newTestCtx(app, newReq(`{"name":"a","age":1}`)).BindJSON(&form{})
+		if _, ok := err.(BindErrors); !ok {
+			t.Errorf("DisallowUnknownFields: error = %v", err)
+		}
+	})
+
+	t.Run("body limit", func(t *testing.T) {
+		app := New()
+		app.SetBodyLimit(8)
+		c := newTestCtx(app, newReq(`{"name":"bytego"}`))
+		c.SetBodyLimit(app.bodyLimit)
+		if err := c.BindJSON(&form{}); err != ErrBodyTooLarge {
+			t.Errorf("BindJSON() error = %v, want ErrBodyTooLarge", err)
+		}
+		c = newTestCtx(app, newReq(`{"name":"bytego"}`))
+		c.SetBodyLimit(app.bodyLimit)
+		c.SetBodyLimit(1 << 10)
+		if err := c.BindJSON(&form{}); err != nil {
+			t.Errorf("BindJSON() error = %v", err)
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package bytego
+
+import (
+	"io"
+	"net/http"
+)
+
+// ErrBodyTooLarge is returned when reading a request body beyond its limit.
+var ErrBodyTooLarge = NewHTTPError(http.StatusRequestEntityTooLarge)
+
+// limitedBody fails reads of the request body once more than limit bytes were read.
+type limitedBody struct {
+	rc    io.ReadCloser
+	limit int64 // <= 0 means unlimited
+	read  int64
+}
+
+func (b *limitedBody) Read(p []byte) (n int, err error) {
+	if b.limit <= 0 {
+		n, err = b.rc.Read(p)
+		b.read += int64(n)
+		return
+	}
+	if b.read > b.limit {
+		return 0, ErrBodyTooLarge
+	}
+	// read one byte more than allowed to detect an oversized body
+	if remain := b.limit - b.read + 1; int64(len(p)) > remain {
+		p = p[:remain]
+	}
+	n, err = b.rc.Read(p)
+	b.read += int64(n)
+	if b.read > b.limit {
+		n -= int(b.read - b.limit)
+		err = ErrBodyTooLarge
+	}
+	return
+}
+
+func (b *limitedBody) Close() error {
+	return b.rc.Close()
+}
+
+// SetBodyLimit limits the request body to limit bytes; reads beyond it fail
+// with ErrBodyTooLarge. A limit <= 0 removes the limit.
+func (c *Ctx) SetBodyLimit(limit int64) {
+	body := c.Request.Body
+	if body == nil || body == http.NoBody {
+		return
+	}
+	if lb, ok := body.(*limitedBody); ok {
+		lb.limit = limit
+		return
+	}
+	if limit <= 0 {
+		return
+	}
+	c.Request.Body = &limitedBody{rc: body, limit: limit}
+}
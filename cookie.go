@@ -0,0 +1,219 @@
+package bytego
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+var (
+	// ErrNoCookieKeys is returned by the signed and encrypted cookie methods
+	// when App.SetCookieKeys was not called.
+	ErrNoCookieKeys = errors.New("bytego: no cookie keys set")
+	// ErrInvalidCookie is returned for a signed or encrypted cookie that was
+	// tampered with or made with an unknown key.
+	ErrInvalidCookie = errors.New("bytego: invalid cookie")
+)
+
+// keyring holds the keys derived from the app cookie keys, the first ones
+// being used to sign and encrypt, all of them to verify and decrypt.
+type keyring struct {
+	signKeys [][]byte
+	aeads    []cipher.AEAD
+}
+
+func newKeyring(keys [][]byte) *keyring {
+	kr := &keyring{}
+	for _, key := range keys {
+		kr.signKeys = append(kr.signKeys, deriveKey(key, "bytego cookie signing"))
+		block, _ := aes.NewCipher(deriveKey(key, "bytego cookie encryption")) // 32 byte keys never fail
+		aead, _ := cipher.NewGCM(block)
+		kr.aeads = append(kr.aeads, aead)
+	}
+	return kr
+}
+
+func deriveKey(key []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+func cookieMAC(key []byte, name, payload string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(name))
+	mac.Write([]byte{'='})
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// sign returns the value readable as base64 followed by a MAC binding it to
+// the cookie name.
+func (kr *keyring) sign(name, value string) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(value))
+	return payload + "." + base64.RawURLEncoding.EncodeToString(cookieMAC(kr.signKeys[0], name, payload))
+}
+
+func (kr *keyring) verify(name, signed string) (string, error) {
+	i := strings.LastIndexByte(signed, '.')
+	if i < 0 {
+		return "", ErrInvalidCookie
+	}
+	payload := signed[:i]
+	sig, err := base64.RawURLEncoding.DecodeString(signed[i+1:])
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+	for _, key := range kr.signKeys {
+		if hmac.Equal(sig, cookieMAC(key, name, payload)) {
+			value, err := base64.RawURLEncoding.DecodeString(payload)
+			if err != nil {
+				return "", ErrInvalidCookie
+			}
+			return string(value), nil
+		}
+	}
+	return "", ErrInvalidCookie
+}
+
+// encrypt seals value with AES-GCM, authenticating the cookie name with it.
+func (kr *keyring) encrypt(name, value string) (string, error) {
+	aead := kr.aeads[0]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(value)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, []byte(value), []byte(name))), nil
+}
+
+func (kr *keyring) decrypt(name, encrypted string) (string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+	for _, aead := range kr.aeads {
+		if len(data) < aead.NonceSize() {
+			break
+		}
+		if value, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], []byte(name)); err == nil {
+			return string(value), nil
+		}
+	}
+	return "", ErrInvalidCookie
+}
+
+// Cookie returns the named cookie with its value unescaped, as set by
+// SetCookie and SetCookieWith.
+func (c *Ctx) Cookie(name string) (*http.Cookie, error) {
+	cookie, err := c.Request.Cookie(name)
+	if err != nil {
+		return nil, err
+	}
+	if value, err := url.QueryUnescape(cookie.Value); err == nil {
+		cookie.Value = value
+	}
+	return cookie, nil
+}
+
+func (c *Ctx) SetCookie(name, value string, maxAge int, path, domain string, secure, httpOnly bool) {
+	c.SetCookieWith(&http.Cookie{
+		Name:     name,
+		Value:    value,
+		MaxAge:   maxAge,
+		Path:     path,
+		Domain:   domain,
+		Secure:   secure,
+		HttpOnly: httpOnly,
+	})
+}
+
+// SetCookieWith adds a Set-Cookie header for cookie with its value escaped.
+// The path defaults to "/" and the SameSite attribute to the one set with
+// SetSameSite. Cookies that are SameSite=None or Partitioned (Go 1.23) are
+// always Secure.
+func (c *Ctx) SetCookieWith(cookie *http.Cookie) {
+	cp := *cookie
+	cp.Value = url.QueryEscape(cookie.Value)
+	if cp.Path == "" {
+		cp.Path = "/"
+	}
+	if cp.SameSite == 0 {
+		cp.SameSite = c.sameSite
+	}
+	if cp.SameSite == http.SameSiteNoneMode || partitioned(&cp) {
+		cp.Secure = true
+	}
+	http.SetCookie(c.Response, &cp)
+}
+
+// partitioned reports whether the Partitioned field, added in Go 1.23, is set.
+func partitioned(cookie *http.Cookie) bool {
+	f := reflect.ValueOf(cookie).Elem().FieldByName("Partitioned")
+	return f.IsValid() && f.Kind() == reflect.Bool && f.Bool()
+}
+
+// SetSignedCookie sets cookie with its value signed with the first app cookie
+// key. The value stays readable by the client but cannot be changed.
+func (c *Ctx) SetSignedCookie(cookie *http.Cookie) error {
+	kr := c.app.cookieKeys
+	if kr == nil {
+		return ErrNoCookieKeys
+	}
+	cp := *cookie
+	cp.Value = kr.sign(cookie.Name, cookie.Value)
+	c.SetCookieWith(&cp)
+	return nil
+}
+
+// SignedCookie returns the value of a cookie set with SetSignedCookie,
+// verified with any of the app cookie keys.
+func (c *Ctx) SignedCookie(name string) (string, error) {
+	kr := c.app.cookieKeys
+	if kr == nil {
+		return "", ErrNoCookieKeys
+	}
+	cookie, err := c.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	return kr.verify(name, cookie.Value)
+}
+
+// SetEncryptedCookie sets cookie with its value encrypted and authenticated
+// with the first app cookie key.
+func (c *Ctx) SetEncryptedCookie(cookie *http.Cookie) error {
+	kr := c.app.cookieKeys
+	if kr == nil {
+		return ErrNoCookieKeys
+	}
+	cp := *cookie
+	value, err := kr.encrypt(cookie.Name, cookie.Value)
+	if err != nil {
+		return err
+	}
+	cp.Value = value
+	c.SetCookieWith(&cp)
+	return nil
+}
+
+// EncryptedCookie returns the value of a cookie set with SetEncryptedCookie,
+// decrypted with any of the app cookie keys.
+func (c *Ctx) EncryptedCookie(name string) (string, error) {
+	kr := c.app.cookieKeys
+	if kr == nil {
+		return "", ErrNoCookieKeys
+	}
+	cookie, err := c.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	return kr.decrypt(name, cookie.Value)
+}
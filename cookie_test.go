@@ -0,0 +1,101 @@
+package bytego
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// roundTrip sets cookies with set and returns a Ctx whose request carries them.
+func roundTrip(app *App, set func(c *Ctx)) (*Ctx, []*http.Cookie) {
+	rec := httptest.NewRecorder()
+	c := newTestCtx(app, httptest.NewRequest(http.MethodGet, "/", nil))
+	c.writer = newResponseWriter(rec, app)
+	c.Response = c.writer
+	set(c)
+	cookies := rec.Result().Cookies()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, cookie := range cookies {
+		req.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+	}
+	return newTestCtx(app, req), cookies
+}
+
+func TestCtx_SetCookieWith(t *testing.T) {
+	app := New()
+	expires := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	c, cookies := roundTrip(app, func(c *Ctx) {
+		c.SetSameSite(http.SameSiteLaxMode)
+		c.SetCookieWith(&http.Cookie{Name: "a", Value: "x y;z", Expires: expires})
+		c.SetCookieWith(&http.Cookie{Name: "b", Value: "1", SameSite: http.SameSiteNoneMode})
+		c.SetCookie("c", "100%", 60, "", "", false, true)
+	})
+	if len(cookies) != 3 {
+		t.Fatalf("cookies = %v", cookies)
+	}
+	if a := cookies[0]; a.Path != "/" || a.SameSite != http.SameSiteLaxMode || !a.Expires.Equal(expires) {
+		t.Errorf("a = %v", a)
+	}
+	if b := cookies[1]; !b.Secure || b.SameSite != http.SameSiteNoneMode {
+		t.Errorf("SameSite=None cookie not secure: %v", b)
+	}
+	for name, want := range map[string]string{"a": "x y;z", "b": "1", "c": "100%"} {
+		cookie, err := c.Cookie(name)
+		if err != nil || cookie.Value != want {
+			t.Errorf("Cookie(%q) = %v, %v, want %q", name, cookie, err, want)
+		}
+	}
+}
+
+func TestCtx_SignedCookie(t *testing.T) {
+	app := New()
+	c := newTestCtx(app, httptest.NewRequest(http.MethodGet, "/", nil))
+	if err := c.SetSignedCookie(&http.Cookie{Name: "s"}); err != ErrNoCookieKeys {
+		t.Fatalf("err = %v, want ErrNoCookieKeys", err)
+	}
+
+	oldKey := []byte("old key, at least 32 bytes long..")
+	app.SetCookieKeys(oldKey)
+	c, cookies := roundTrip(app, func(c *Ctx) {
+		_ = c.SetSignedCookie(&http.Cookie{Name: "signed", Value: "user=42"})
+		_ = c.SetEncryptedCookie(&http.Cookie{Name: "secret", Value: "user=42"})
+	})
+	if strings.Contains(cookies[1].Value, "user") {
+		t.Errorf("encrypted value readable: %q", cookies[1].Value)
+	}
+	if v, err := c.SignedCookie("signed"); err != nil || v != "user=42" {
+		t.Errorf("SignedCookie = %q, %v", v, err)
+	}
+	if v, err := c.EncryptedCookie("secret"); err != nil || v != "user=42" {
+		t.Errorf("EncryptedCookie = %q, %v", v, err)
+	}
+
+	app.SetCookieKeys([]byte("new key, at least 32 bytes long.."), oldKey)
+	if v, err := c.SignedCookie("signed"); err != nil || v != "user=42" {
+		t.Errorf("rotated SignedCookie = %q, %v", v, err)
+	}
+	if v, err := c.EncryptedCookie("secret"); err != nil || v != "user=42" {
+		t.Errorf("rotated EncryptedCookie = %q, %v", v, err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "other", Value: cookies[0].Value})
+	req.AddCookie(&http.Cookie{Name: "secret", Value: cookies[1].Value[:len(cookies[1].Value)-2] + "AA"})
+	c = newTestCtx(app, req)
+	if _, err := c.SignedCookie("other"); err != ErrInvalidCookie {
+		t.Errorf("renamed cookie: err = %v", err)
+	}
+	if _, err := c.EncryptedCookie("secret"); err != ErrInvalidCookie {
+		t.Errorf("tampered cookie: err = %v", err)
+	}
+	if _, err := c.SignedCookie("missing"); err != http.ErrNoCookie {
+		t.Errorf("missing cookie: err = %v", err)
+	}
+
+	app.SetCookieKeys([]byte("unrelated key, 32 bytes long....."))
+	if _, err := c.SignedCookie("other"); err != ErrInvalidCookie {
+		t.Errorf("unknown key: err = %v", err)
+	}
+}
@@ -3,15 +3,18 @@ package bytego
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"encoding/xml"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
+
+	"github.com/gostack-labs/bytego/internal/respctl"
 )
 
 type Ctx struct {
@@ -26,6 +29,10 @@ type Ctx struct {
 	sameSite     http.SameSite
 	routePath    string
 	errorHandled bool
+	query        url.Values
+	requestID    string
+	hop          hop
+	hopParsed    bool
 	m            Map
 	mu           sync.RWMutex
 }
@@ -39,6 +46,9 @@ func (c *Ctx) reset() {
 	c.Request = nil
 	c.errorHandled = false
 	c.routePath = ""
+	c.query = nil
+	c.requestID = ""
+	c.hop, c.hopParsed = hop{}, false
 	c.m = nil
 }
 
@@ -52,15 +62,18 @@ func (c *Ctx) Param(key string) string {
 }
 
 func (c *Ctx) Query(key string) string {
-	return c.Request.URL.Query().Get(key)
+	return c.queryValues().Get(key)
 }
 
-func (c *Ctx) Form(key string) string {
-	return c.Request.FormValue(key)
+func (c *Ctx) queryValues() url.Values {
+	if c.query == nil {
+		c.query = c.Request.URL.Query()
+	}
+	return c.query
 }
 
-func (c *Ctx) Cookie(name string) (*http.Cookie, error) {
-	return c.Request.Cookie(name)
+func (c *Ctx) Form(key string) string {
+	return c.Request.FormValue(key)
 }
 
 func (c *Ctx) Status(code int) {
@@ -105,40 +118,46 @@ func (c *Ctx) String(code int, s string) error {
 }
 
 func (c *Ctx) JSON(code int, i interface{}) error {
-	bs, err := json.Marshal(i)
-	if err != nil {
-		return err
-	}
-	c.writeContentType(jsonContentType)
-	c.Status(code)
-	_, err = c.Response.Write(bs)
-	return err
+	return c.writeJSON(code, jsonContentType, i, jsonRender{escapeHTML: true})
+}
+
+// PureJSON writes i as JSON without escaping <, > and & in strings.
+func (c *Ctx) PureJSON(code int, i interface{}) error {
+	return c.writeJSON(code, jsonContentType, i, jsonRender{})
 }
 
+// IndentedJSON writes i as JSON indented with four spaces.
+func (c *Ctx) IndentedJSON(code int, i interface{}) error {
+	return c.writeJSON(code, jsonContentType, i, jsonRender{escapeHTML: true, indent: "    "})
+}
+
+// SecureJSON writes i as JSON preceded by the secure JSON prefix of the app,
+// "while(1);" by default, so that the response cannot be hijacked as a script.
+func (c *Ctx) SecureJSON(code int, i interface{}) error {
+	return c.writeJSON(code, jsonContentType, i, jsonRender{escapeHTML: true, prefix: c.app.secureJSONPrefix})
+}
+
+// AsciiJSON writes i as JSON with every non-ASCII character escaped as \uXXXX.
+func (c *Ctx) AsciiJSON(code int, i interface{}) error {
+	return c.writeJSON(code, jsonContentType, i, jsonRender{escapeHTML: true, ascii: true})
+}
+
+// JSONP writes i as a call of the callback query parameter, or as JSON when the
+// parameter is absent. Callbacks that are not dotted JavaScript identifiers are
+// rejected with ErrInvalidCallback.
 func (c *Ctx) JSONP(code int, i interface{}) error {
 	callback := c.Query("callback")
 	if callback == "" {
 		return c.JSON(code, i)
 	}
-	b, err := json.Marshal(i)
-	if err != nil {
-		return err
+	if !validCallback(callback) {
+		return ErrInvalidCallback
 	}
-	c.writeContentType(jsonContentType)
-	c.Status(code)
-	if _, err = c.Response.Write(stringToBytes(callback)); err != nil {
-		return err
-	}
-	if _, err = c.Response.Write([]byte{'('}); err != nil {
-		return err
-	}
-	if _, err = c.Response.Write(b); err != nil {
-		return err
-	}
-	if _, err = c.Response.Write([]byte{')', ';'}); err != nil {
-		return err
-	}
-	return err
+	return c.writeJSON(code, javascriptContentType, i, jsonRender{
+		escapeHTML: true,
+		prefix:     "/**/" + callback + "(",
+		suffix:     ");",
+	})
 }
 
 func (c *Ctx) XML(code int, i interface{}) error {
@@ -170,6 +189,39 @@ func (c *Ctx) Blob(code int, contentType string, b []byte) (err error) {
 	return
 }
 
+// Stream calls step and flushes the response until step returns false or the
+// client goes away. It reports whether the client disconnected.
+func (c *Ctx) Stream(step func(w io.Writer) bool) bool {
+	done := c.Context().Done()
+	for {
+		select {
+		case <-done:
+			return true
+		default:
+			keepOpen := step(c.Response)
+			_ = respctl.Flush(c.Response)
+			if !keepOpen {
+				return false
+			}
+		}
+	}
+}
+
+// DataFromReader writes the headers and copies reader to the response. A negative
+// contentLength leaves the Content-Length header unset.
+func (c *Ctx) DataFromReader(code int, contentLength int64, contentType string, reader io.Reader, headers map[string]string) error {
+	for k, v := range headers {
+		c.SetHeader(k, v)
+	}
+	if contentLength >= 0 {
+		c.SetHeader(HeaderContentLength, strconv.FormatInt(contentLength, 10))
+	}
+	c.writeContentType(contentType)
+	c.Status(code)
+	_, err := io.Copy(c.Response, reader)
+	return err
+}
+
 func (c *Ctx) View(code int, name string, data interface{}) error {
 	buf := new(bytes.Buffer)
 	if c.app.render == nil {
@@ -229,20 +281,6 @@ func (c *Ctx) RemoteIP() string {
 	return ip
 }
 
-func (c *Ctx) ClientIP() string {
-	if ip := c.Request.Header.Get(HeaderXForwardedFor); ip != "" {
-		i := strings.IndexAny(ip, ",")
-		if i > 0 {
-			return strings.TrimSpace(ip[:i])
-		}
-		return ip
-	}
-	if ip := c.Request.Header.Get(HeaderXRealIP); ip != "" {
-		return ip
-	}
-	return c.RemoteIP()
-}
-
 func (c *Ctx) ContentType() string {
 	contentType := c.Request.Header.Get("Content-Type")
 	for i, char := range contentType {
@@ -257,22 +295,6 @@ func (c *Ctx) SetSameSite(samesite http.SameSite) {
 	c.sameSite = samesite
 }
 
-func (c *Ctx) SetCookie(name, value string, maxAge int, path, domain string, secure, httpOnly bool) {
-	if path == "" {
-		path = "/"
-	}
-	http.SetCookie(c.Response, &http.Cookie{
-		Name:     name,
-		Value:    url.QueryEscape(value),
-		MaxAge:   maxAge,
-		Path:     path,
-		Domain:   domain,
-		SameSite: c.sameSite,
-		Secure:   secure,
-		HttpOnly: httpOnly,
-	})
-}
-
 func (c *Ctx) IsDebug() bool {
 	return c.app.isDebug
 }
@@ -281,6 +303,35 @@ func (c *Ctx) Bind(i interface{}) error {
 	return c.app.binder.Bind(c, i)
 }
 
+func (c *Ctx) BindParams(i interface{}) error {
+	return c.app.binder.BindWith(c, i, BinderParam)
+}
+
+func (c *Ctx) BindQuery(i interface{}) error {
+	return c.app.binder.BindWith(c, i, BinderQuery)
+}
+
+func (c *Ctx) BindHeader(i interface{}) error {
+	return c.app.binder.BindWith(c, i, BinderHeader)
+}
+
+func (c *Ctx) BindJSON(i interface{}) error {
+	return c.app.binder.BindWith(c, i, BinderJSON)
+}
+
+func (c *Ctx) BindXML(i interface{}) error {
+	return c.app.binder.BindWith(c, i, BinderXML)
+}
+
+func (c *Ctx) BindForm(i interface{}) error {
+	return c.app.binder.BindWith(c, i, BinderForm)
+}
+
+// ShouldBindWith binds i from the given source only, after applying defaults.
+func (c *Ctx) ShouldBindWith(i interface{}, binderType BinderType) error {
+	return c.app.binder.BindWith(c, i, binderType)
+}
+
 func (c *Ctx) writeContentType(contentType string) {
 	header := c.Response.Header()
 	if headers := header["Content-Type"]; len(headers) == 0 {
@@ -288,10 +339,31 @@ func (c *Ctx) writeContentType(contentType string) {
 	}
 }
 
+// Logger returns the app logger, prefixing messages with the request ID when there is one.
 func (c *Ctx) Logger() Logger {
+	if c.requestID != "" {
+		return &requestLogger{Logger: c.app.Logger, prefix: "request_id=" + c.requestID + " "}
+	}
 	return c.app.Logger
 }
 
+// RequestID returns the ID set with SetRequestID, or else the X-Request-ID
+// header of the response or of the request.
+func (c *Ctx) RequestID() string {
+	if c.requestID != "" {
+		return c.requestID
+	}
+	if id := c.Response.Header().Get(HeaderXRequestID); id != "" {
+		return id
+	}
+	return c.Request.Header.Get(HeaderXRequestID)
+}
+
+// SetRequestID sets the ID returned by RequestID and logged by Logger.
+func (c *Ctx) SetRequestID(id string) {
+	c.requestID = id
+}
+
 func (c *Ctx) Context() context.Context {
 	if c.Request != nil {
 		return c.Request.Context()
@@ -314,3 +386,51 @@ func (c *Ctx) Get(key string) (val interface{}, exists bool) {
 	val, exists = c.m[key]
 	return
 }
+
+// Keys returns a copy of the values stored with Set.
+func (c *Ctx) Keys() Map {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	m := make(Map, len(c.m))
+	for k, v := range c.m {
+		m[k] = v
+	}
+	return m
+}
+
+// Copy returns a copy of c that stays valid after the request is done, for use
+// in another goroutine. It shares the request and response, which must not be
+// used once the request is done, and continues the handler chain where c is.
+func (c *Ctx) Copy() *Ctx {
+	cp := &Ctx{
+		app:          c.app,
+		path:         c.path,
+		index:        c.index,
+		handlers:     c.handlers,
+		writer:       c.writer,
+		Response:     c.Response,
+		Request:      c.Request,
+		Params:       append(Params(nil), c.Params...),
+		sameSite:     c.sameSite,
+		routePath:    c.routePath,
+		errorHandled: c.errorHandled,
+		requestID:    c.requestID,
+		hop:          c.hop,
+		hopParsed:    c.hopParsed,
+	}
+	for k, v := range c.query {
+		if cp.query == nil {
+			cp.query = make(url.Values, len(c.query))
+		}
+		cp.query[k] = append([]string(nil), v...)
+	}
+	c.mu.RLock()
+	for k, v := range c.m {
+		if cp.m == nil {
+			cp.m = make(Map, len(c.m))
+		}
+		cp.m[k] = v
+	}
+	c.mu.RUnlock()
+	return cp
+}
@@ -1,6 +1,13 @@
 package bytego
 
-import "net/http"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+)
 
 type ErrorHandler func(error, *Ctx)
 type ErrorCode interface {
@@ -8,15 +15,156 @@ type ErrorCode interface {
 	ErrCode() int
 }
 
+var (
+	ErrNotFound     = NewHTTPError(http.StatusNotFound)
+	ErrForbidden    = NewHTTPError(http.StatusForbidden)
+	ErrUnauthorized = NewHTTPError(http.StatusUnauthorized)
+)
+
+// HTTPError is an error answered with its HTTP status code by the default error handler.
+type HTTPError struct {
+	Code    int
+	Message string
+	Err     error
+}
+
+func NewHTTPError(code int, message ...string) *HTTPError {
+	e := &HTTPError{Code: code, Message: http.StatusText(code)}
+	if len(message) > 0 {
+		e.Message = message[0]
+	}
+	return e
+}
+
+func (e *HTTPError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("code=%d, message=%s, err=%v", e.Code, e.Message, e.Err)
+	}
+	return fmt.Sprintf("code=%d, message=%s", e.Code, e.Message)
+}
+
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+// WithErr returns a copy of e wrapping err.
+func (e *HTTPError) WithErr(err error) *HTTPError {
+	return &HTTPError{Code: e.Code, Message: e.Message, Err: err}
+}
+
+// BindError describes a request value that could not be bound to a field.
+type BindError struct {
+	Field  string
+	Source string
+	Value  string
+	Type   string
+	Err    error
+}
+
+func newBindError(field, source, value string, typ reflect.Type, err error) *BindError {
+	return &BindError{
+		Field:  field,
+		Source: source,
+		Value:  value,
+		Type:   typ.String(),
+		Err:    err,
+	}
+}
+
+func newDecodeBindError(source string, err error) error {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return err
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return BindErrors{{
+			Field:  typeErr.Field,
+			Source: source,
+			Value:  typeErr.Value,
+			Type:   typeErr.Type.String(),
+			Err:    err,
+		}}
+	}
+	return BindErrors{{Source: source, Err: err}}
+}
+
+func (e *BindError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("bind %s: %v", e.Source, e.Err)
+	}
+	return fmt.Sprintf("bind %s %q: invalid value %q, expected %s", e.Source, e.Field, e.Value, e.Type)
+}
+
+func (e *BindError) Unwrap() error {
+	return e.Err
+}
+
+// BindErrors aggregates the BindError of every field that failed to bind.
+type BindErrors []*BindError
+
+func (e BindErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// merge appends the errors of err if it is a BindErrors and reports whether it was.
+func (e *BindErrors) merge(err error) bool {
+	errs, ok := err.(BindErrors)
+	if ok {
+		*e = append(*e, errs...)
+	}
+	return ok
+}
+
 func defaultErrorHandler(err error, c *Ctx) {
-	errCode, ok := err.(ErrorCode)
 	var statusCode int
 	var code int
 	var msg string
-	if ok { //normal
+	var bindErrs BindErrors
+	var validationErrs ValidationErrors
+	var httpErr *HTTPError
+	body := Map{}
+	if errCode, ok := err.(ErrorCode); ok { //normal
 		statusCode = http.StatusOK
 		code = errCode.ErrCode()
 		msg = err.Error()
+	} else if errors.As(err, &bindErrs) {
+		statusCode = http.StatusBadRequest
+		code = statusCode
+		msg = http.StatusText(http.StatusBadRequest)
+		details := make([]Map, len(bindErrs))
+		for i, e := range bindErrs {
+			details[i] = Map{
+				"field":  e.Field,
+				"source": e.Source,
+				"value":  e.Value,
+				"type":   e.Type,
+				"msg":    e.Error(),
+			}
+		}
+		body["errors"] = details
+	} else if errors.As(err, &validationErrs) {
+		statusCode = http.StatusBadRequest
+		code = statusCode
+		msg = http.StatusText(http.StatusBadRequest)
+		details := make([]Map, len(validationErrs))
+		for i, e := range validationErrs {
+			details[i] = Map{
+				"field": e.Field,
+				"rule":  e.Rule,
+				"param": e.Param,
+				"msg":   e.Message,
+			}
+		}
+		body["errors"] = details
+	} else if errors.As(err, &httpErr) {
+		statusCode = httpErr.Code
+		code = statusCode
+		msg = httpErr.Message
 	} else {
 		statusCode = http.StatusInternalServerError
 		code = statusCode
@@ -30,9 +178,8 @@ func defaultErrorHandler(err error, c *Ctx) {
 	if c.Request.Method == http.MethodHead {
 		c.Status(statusCode)
 	} else {
-		_ = c.JSON(statusCode, Map{
-			"code": code,
-			"msg":  msg,
-		})
+		body["code"] = code
+		body["msg"] = msg
+		_ = c.JSON(statusCode, body)
 	}
 }
@@ -0,0 +1,140 @@
+package bytego
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// File serves the named file, or the index.html of a directory, with support for
+// Range, If-Modified-Since and ETag conditional requests.
+func (c *Ctx) File(file string) error {
+	return c.file(file, "")
+}
+
+// file serves file with the Content-Disposition disposition, which is only set
+// once the file is open so that errors are not sent as a download.
+func (c *Ctx) file(file, disposition string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return fileError(err)
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return fileError(err)
+	}
+	if fi.IsDir() {
+		return c.file(filepath.Join(file, "index.html"), disposition)
+	}
+	if disposition != "" {
+		c.SetHeader(HeaderContentDisposition, disposition)
+	}
+	return c.serveContent(fi.Name(), fi.ModTime(), fi.Size(), f)
+}
+
+// FileFromFS serves the named file of fsys like File.
+func (c *Ctx) FileFromFS(name string, fsys fs.FS) error {
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	if name == "" {
+		name = "."
+	}
+	f, err := fsys.Open(name)
+	if err != nil {
+		return fileError(err)
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return fileError(err)
+	}
+	if fi.IsDir() {
+		return c.FileFromFS(path.Join(name, "index.html"), fsys)
+	}
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		b, err := io.ReadAll(f)
+		if err != nil {
+			return err
+		}
+		content = bytes.NewReader(b)
+	}
+	return c.serveContent(fi.Name(), fi.ModTime(), fi.Size(), content)
+}
+
+// Attachment serves the file as a download named filename.
+func (c *Ctx) Attachment(file, filename string) error {
+	return c.file(file, contentDisposition("attachment", filename))
+}
+
+// Inline serves the file for display in the browser, named filename when saved.
+func (c *Ctx) Inline(file, filename string) error {
+	return c.file(file, contentDisposition("inline", filename))
+}
+
+func (c *Ctx) serveContent(name string, modtime time.Time, size int64, content io.ReadSeeker) error {
+	header := c.Response.Header()
+	if header.Get("Etag") == "" && !modtime.IsZero() {
+		header.Set("Etag", `W/"`+strconv.FormatInt(size, 16)+"-"+strconv.FormatInt(modtime.UnixNano(), 16)+`"`)
+	}
+	http.ServeContent(c.Response, c.Request, name, modtime, content)
+	return nil
+}
+
+func fileError(err error) error {
+	switch {
+	case os.IsNotExist(err):
+		return ErrNotFound.WithErr(err)
+	case os.IsPermission(err):
+		return ErrForbidden.WithErr(err)
+	}
+	return err
+}
+
+// contentDisposition formats a Content-Disposition header (RFC 6266) with an
+// ASCII filename fallback and a UTF-8 filename* parameter (RFC 5987).
+func contentDisposition(dispositionType, filename string) string {
+	if filename == "" {
+		return dispositionType
+	}
+	var fallback strings.Builder
+	ascii := true
+	for _, r := range filename {
+		switch {
+		case r == '"' || r == '\\' || r < 0x20 || r > 0x7e:
+			ascii = false
+			fallback.WriteByte('_')
+		default:
+			fallback.WriteRune(r)
+		}
+	}
+	v := dispositionType + `; filename="` + fallback.String() + `"`
+	if !ascii {
+		v += "; filename*=UTF-8''" + encodeRFC5987(filename)
+	}
+	return v
+}
+
+func encodeRFC5987(s string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if ('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z') || ('0' <= ch && ch <= '9') ||
+			strings.IndexByte("!#$&+-.^_`|~", ch) >= 0 {
+			b.WriteByte(ch)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[ch>>4])
+		b.WriteByte(hex[ch&0xf])
+	}
+	return b.String()
+}
@@ -0,0 +1,63 @@
+package bytego
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestCtx_File(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "report.txt")
+	if err := os.WriteFile(file, []byte("0123456789"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fsys := fstest.MapFS{"docs/a.txt": {Data: []byte("from fs"), ModTime: time.Now()}}
+
+	app := New()
+	app.GET("/file", func(c *Ctx) error { return c.File(file) })
+	app.GET("/missing", func(c *Ctx) error { return c.File(filepath.Join(dir, "missing")) })
+	app.GET("/download", func(c *Ctx) error { return c.Attachment(file, "rapport été.txt") })
+	app.GET("/download-missing", func(c *Ctx) error { return c.Attachment(filepath.Join(dir, "missing"), "missing.txt") })
+	app.GET("/fs", func(c *Ctx) error { return c.FileFromFS("docs/a.txt", fsys) })
+
+	serve := func(path string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		rec := httptest.NewRecorder()
+		app.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve("/file", nil)
+	etag := rec.Header().Get("Etag")
+	if rec.Code != http.StatusOK || rec.Body.String() != "0123456789" || etag == "" {
+		t.Fatalf("File() = %d %q etag=%q", rec.Code, rec.Body.String(), etag)
+	}
+	if rec = serve("/file", http.Header{"Range": {"bytes=2-4"}}); rec.Code != http.StatusPartialContent || rec.Body.String() != "234" {
+		t.Errorf("range = %d %q", rec.Code, rec.Body.String())
+	}
+	if rec = serve("/file", http.Header{"If-None-Match": {etag}}); rec.Code != http.StatusNotModified {
+		t.Errorf("If-None-Match = %d", rec.Code)
+	}
+	if rec = serve("/missing", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("missing = %d", rec.Code)
+	}
+	rec = serve("/download", nil)
+	want := `attachment; filename="rapport _t_.txt"; filename*=UTF-8''rapport%20%C3%A9t%C3%A9.txt`
+	if got := rec.Header().Get(HeaderContentDisposition); got != want {
+		t.Errorf("Content-Disposition = %q, want %q", got, want)
+	}
+	if rec = serve("/download-missing", nil); rec.Code != http.StatusNotFound || rec.Header().Get(HeaderContentDisposition) != "" {
+		t.Errorf("missing download = %d, Content-Disposition %q", rec.Code, rec.Header().Get(HeaderContentDisposition))
+	}
+	if rec = serve("/fs", nil); rec.Body.String() != "from fs" {
+		t.Errorf("FileFromFS() = %d %q", rec.Code, rec.Body.String())
+	}
+}
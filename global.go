@@ -3,13 +3,20 @@ package bytego
 import "net/http"
 
 const (
-	HeaderContentLength   = "Content-Length"
-	HeaderOrigin          = "Origin"
-	HeaderVary            = "Vary"
-	HeaderXForwardedFor   = "X-Forwarded-For"
-	HeaderXForwardedProto = "X-Forwarded-Proto"
-	HeaderXRealIP         = "X-Real-Ip"
-	HeaderXRequestID      = "X-Request-ID"
+	HeaderAccept             = "Accept"
+	HeaderAcceptEncoding     = "Accept-Encoding"
+	HeaderAcceptLanguage     = "Accept-Language"
+	HeaderContentDisposition = "Content-Disposition"
+	HeaderContentLength      = "Content-Length"
+	HeaderForwarded          = "Forwarded"
+	HeaderLastEventID        = "Last-Event-ID"
+	HeaderOrigin             = "Origin"
+	HeaderVary               = "Vary"
+	HeaderXForwardedFor      = "X-Forwarded-For"
+	HeaderXForwardedHost     = "X-Forwarded-Host"
+	HeaderXForwardedProto    = "X-Forwarded-Proto"
+	HeaderXRealIP            = "X-Real-Ip"
+	HeaderXRequestID         = "X-Request-ID"
 
 	// Access control
 	HeaderAccessControlRequestMethod    = "Access-Control-Request-Method"
@@ -21,9 +28,12 @@ const (
 	HeaderAccessControlExposeHeaders    = "Access-Control-Expose-Headers"
 	HeaderAccessControlMaxAge           = "Access-Control-Max-Age"
 
-	jsonContentType = "application/json; charset=utf-8"
-	xmlContentType  = "application/xml; charset=utf-8"
-	htmlContentType = "text/html; charset=utf-8"
+	jsonContentType       = "application/json; charset=utf-8"
+	xmlContentType        = "application/xml; charset=utf-8"
+	htmlContentType       = "text/html; charset=utf-8"
+	plainContentType      = "text/plain; charset=utf-8"
+	yamlContentType       = "application/x-yaml; charset=utf-8"
+	javascriptContentType = "application/javascript; charset=utf-8"
 )
 
 var (
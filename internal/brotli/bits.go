@@ -0,0 +1,68 @@
+package brotli
+
+import (
+	"io"
+)
+
+// bitReader reads the bits of a stream least significant bit first, as
+// brotli packs them. It never reads a byte before its bits are needed, except
+// to peek at a prefix code. The first error is kept in err and further reads
+// return zeros.
+type bitReader struct {
+	r    io.ByteReader
+	bits uint64
+	n    uint
+	eof  bool
+	err  error
+}
+
+// fill buffers at least k bits, k being at most 56, or as many as the stream
+// still has.
+func (br *bitReader) fill(k uint) {
+	for br.n < k && !br.eof {
+		c, err := br.r.ReadByte()
+		if err != nil {
+			br.eof = true
+			if err != io.EOF && br.err == nil {
+				br.err = err
+			}
+			return
+		}
+		br.bits |= uint64(c) << br.n
+		br.n += 8
+	}
+}
+
+func (br *bitReader) consume(k uint) {
+	if k > br.n {
+		if br.err == nil {
+			br.err = io.ErrUnexpectedEOF
+		}
+		br.bits, br.n = 0, 0
+		return
+	}
+	br.bits >>= k
+	br.n -= k
+}
+
+// read returns the next k bits, k being at most 32.
+func (br *bitReader) read(k uint) uint32 {
+	if k == 0 {
+		return 0
+	}
+	br.fill(k)
+	v := uint32(br.bits & (1<<k - 1))
+	br.consume(k)
+	if br.err != nil {
+		return 0
+	}
+	return v
+}
+
+// align skips to the next byte boundary, the skipped bits having to be zero.
+func (br *bitReader) align() error {
+	if pad := br.n % 8; pad > 0 && br.read(pad) != 0 {
+		return errFormat("padding")
+	}
+	return br.err
+}
@@ -0,0 +1,255 @@
+package brotli
+
+import (
+	_ "embed"
+)
+
+// dictionary is the static dictionary of RFC 7932 appendix A, whose SHA-256
+// is 20e42eb1b511c21806d4d227d07e5dd06877d8ce7b3a817f378f313653f35c70.
+//
+//go:embed dictionary.bin
+var dictionary []byte
+
+const (
+	minWordLength = 4
+	maxWordLength = 24
+)
+
+// wordBits is the log2 of the number of dictionary words of each length.
+var wordBits = [maxWordLength + 1]uint8{
+	4: 10, 5: 10, 6: 11, 7: 11, 8: 10, 9: 10, 10: 10, 11: 10, 12: 10, 13: 9,
+	14: 9, 15: 8, 16: 7, 17: 7, 18: 8, 19: 7, 20: 7, 21: 6, 22: 6, 23: 5, 24: 5,
+}
+
+// wordOffsets is the offset in the dictionary of the words of each length.
+var wordOffsets [maxWordLength + 2]int
+
+func init() {
+	wordOffsets[minWordLength] = 0
+	for l := minWordLength; l <= maxWordLength; l++ {
+		wordOffsets[l+1] = wordOffsets[l] + l<<wordBits[l]
+	}
+}
+
+// word returns the transformed word of a dictionary reference of length
+// bytes and the given id, the id being the distance beyond the window.
+func word(length, id int) ([]byte, error) {
+	if length < minWordLength || length > maxWordLength {
+		return nil, errFormat("dictionary reference")
+	}
+	bits := wordBits[length]
+	t := id >> bits
+	if t >= len(transforms) {
+		return nil, errFormat("dictionary transform")
+	}
+	offset := wordOffsets[length] + (id&(1<<bits-1))*length
+	return transforms[t].apply(dictionary[offset : offset+length]), nil
+}
+
+const (
+	identity = iota
+	omitLast1
+	omitLast2
+	omitLast3
+	omitLast4
+	omitLast5
+	omitLast6
+	omitLast7
+	omitLast8
+	omitLast9
+	uppercaseFirst
+	uppercaseAll
+	omitFirst1
+	omitFirst2
+	omitFirst3
+	omitFirst4
+	omitFirst5
+	omitFirst6
+	omitFirst7
+	omitFirst8
+	omitFirst9
+)
+
+type transform struct {
+	prefix string
+	op     int
+	suffix string
+}
+
+func (t transform) apply(w []byte) []byte {
+	switch {
+	case t.op >= omitLast1 && t.op <= omitLast9:
+		if t.op > len(w) {
+			w = w[:0]
+		} else {
+			w = w[:len(w)-t.op]
+		}
+	case t.op >= omitFirst1:
+		if n := t.op - omitFirst1 + 1; n > len(w) {
+			w = w[len(w):]
+		} else {
+			w = w[n:]
+		}
+	}
+	out := make([]byte, 0, len(t.prefix)+len(w)+len(t.suffix))
+	out = append(out, t.prefix...)
+	out = append(out, w...)
+	body := out[len(t.prefix):]
+	switch t.op {
+	case uppercaseFirst:
+		if len(body) > 0 {
+			ferment(body)
+		}
+	case uppercaseAll:
+		for i := 0; i < len(body); {
+			i += ferment(body[i:])
+		}
+	}
+	return append(out, t.suffix...)
+}
+
+// ferment upper cases the first character of w the approximate way of RFC
+// 7932 section 8 and returns its length in bytes.
+func ferment(w []byte) int {
+	switch {
+	case w[0] < 192:
+		if w[0] >= 'a' && w[0] <= 'z' {
+			w[0] ^= 32
+		}
+		return 1
+	case w[0] < 224:
+		if len(w) > 1 {
+			w[1] ^= 32
+		}
+		return 2
+	}
+	if len(w) > 2 {
+		w[2] ^= 5
+	}
+	return 3
+}
+
+// transforms are the word transforms of RFC 7932 appendix B.
+var transforms = [...]transform{
+	{"", identity, ""},
+	{"", identity, " "},
+	{" ", identity, " "},
+	{"", omitFirst1, ""},
+	{"", uppercaseFirst, " "},
+	{"", identity, " the "},
+	{" ", identity, ""},
+	{"s ", identity, " "},
+	{"", identity, " of "},
+	{"", uppercaseFirst, ""},
+	{"", identity, " and "},
+	{"", omitFirst2, ""},
+	{"", omitLast1, ""},
+	{", ", identity, " "},
+	{"", identity, ", "},
+	{" ", uppercaseFirst, " "},
+	{"", identity, " in "},
+	{"", identity, " to "},
+	{"e ", identity, " "},
+	{"", identity, "\""},
+	{"", identity, "."},
+	{"", identity, "\">"},
+	{"", identity, "\n"},
+	{"", omitLast3, ""},
+	{"", identity, "]"},
+	{"", identity, " for "},
+	{"", omitFirst3, ""},
+	{"", omitLast2, ""},
+	{"", identity, " a "},
+	{"", identity, " that "},
+	{" ", uppercaseFirst, ""},
+	{"", identity, ". "},
+	{".", identity, ""},
+	{" ", identity, ", "},
+	{"", omitFirst4, ""},
+	{"", identity, " with "},
+	{"", identity, "'"},
+	{"", identity, " from "},
+	{"", identity, " by "},
+	{"", omitFirst5, ""},
+	{"", omitFirst6, ""},
+	{" the ", identity, ""},
+	{"", omitLast4, ""},
+	{"", identity, ". The "},
+	{"", uppercaseAll, ""},
+	{"", identity, " on "},
+	{"", identity, " as "},
+	{"", identity, " is "},
+	{"", omitLast7, ""},
+	{"", omitLast1, "ing "},
+	{"", identity, "\n\t"},
+	{"", identity, ":"},
+	{" ", identity, ". "},
+	{"", identity, "ed "},
+	{"", omitFirst9, ""},
+	{"", omitFirst7, ""},
+	{"", omitLast6, ""},
+	{"", identity, "("},
+	{"", uppercaseFirst, ", "},
+	{"", omitLast8, ""},
+	{"", identity, " at "},
+	{"", identity, "ly "},
+	{" the ", identity, " of "},
+	{"", omitLast5, ""},
+	{"", omitLast9, ""},
+	{" ", uppercaseFirst, ", "},
+	{"", uppercaseFirst, "\""},
+	{".", identity, "("},
+	{"", uppercaseAll, " "},
+	{"", uppercaseFirst, "\">"},
+	{"", identity, "=\""},
+	{" ", identity, "."},
+	{".com/", identity, ""},
+	{" the ", identity, " of the "},
+	{"", uppercaseFirst, "'"},
+	{"", identity, ". This "},
+	{"", identity, ","},
+	{".", identity, " "},
+	{"", uppercaseFirst, "("},
+	{"", uppercaseFirst, "."},
+	{"", identity, " not "},
+	{" ", identity, "=\""},
+	{"", identity, "er "},
+	{" ", uppercaseAll, " "},
+	{"", identity, "al "},
+	{" ", uppercaseAll, ""},
+	{"", identity, "='"},
+	{"", uppercaseAll, "\""},
+	{"", uppercaseFirst, ". "},
+	{" ", identity, "("},
+	{"", identity, "ful "},
+	{" ", uppercaseFirst, ". "},
+	{"", identity, "ive "},
+	{"", identity, "less "},
+	{"", uppercaseAll, "'"},
+	{"", identity, "est "},
+	{" ", uppercaseFirst, "."},
+	{"", uppercaseAll, "\">"},
+	{" ", identity, "='"},
+	{"", uppercaseFirst, ","},
+	{"", identity, "ize "},
+	{"", uppercaseAll, "."},
+	{"\xc2\xa0", identity, ""},
+	{" ", identity, ","},
+	{"", uppercaseFirst, "=\""},
+	{"", uppercaseAll, "=\""},
+	{"", identity, "ous "},
+	{"", uppercaseAll, ", "},
+	{"", uppercaseFirst, "='"},
+	{" ", uppercaseFirst, ","},
+	{" ", uppercaseAll, "=\""},
+	{" ", uppercaseAll, ", "},
+	{"", uppercaseAll, ","},
+	{"", uppercaseAll, "("},
+	{"", uppercaseAll, ". "},
+	{" ", uppercaseAll, "."},
+	{"", uppercaseAll, "='"},
+	{" ", uppercaseAll, ". "},
+	{" ", uppercaseFirst, "=\""},
+	{" ", uppercaseAll, "='"},
+	{" ", uppercaseFirst, "='"},
+}
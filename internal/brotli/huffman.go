@@ -0,0 +1,204 @@
+package brotli
+
+const maxCodeLength = 15
+
+// huffman is a canonical prefix code, decoded a bit at a time.
+type huffman struct {
+	count   [maxCodeLength + 1]uint16 // number of codes of each length
+	symbols []uint16                  // symbols ordered by code
+}
+
+// build sets the code of the given code lengths, zero lengths leaving the
+// symbol out. A code of a single symbol has no bits.
+func (h *huffman) build(lengths []uint8) {
+	h.count = [maxCodeLength + 1]uint16{}
+	for _, l := range lengths {
+		h.count[l]++
+	}
+	h.count[0] = 0
+	var offs [maxCodeLength + 2]uint16
+	for l := 1; l <= maxCodeLength; l++ {
+		offs[l+1] = offs[l] + h.count[l]
+	}
+	h.symbols = make([]uint16, offs[maxCodeLength+1])
+	for sym, l := range lengths {
+		if l != 0 {
+			h.symbols[offs[l]] = uint16(sym)
+			offs[l]++
+		}
+	}
+}
+
+func (h *huffman) decode(br *bitReader) int {
+	if len(h.symbols) == 1 {
+		return int(h.symbols[0])
+	}
+	br.fill(maxCodeLength)
+	bits := br.bits
+	code, first, index := 0, 0, 0
+	for l := uint(1); l <= maxCodeLength; l++ {
+		code |= int(bits & 1)
+		bits >>= 1
+		count := int(h.count[l])
+		if code-count < first {
+			br.consume(l)
+			return int(h.symbols[index+code-first])
+		}
+		index += count
+		first = (first + count) << 1
+		code <<= 1
+	}
+	// incomplete codes are rejected when read, so the stream ended
+	br.consume(maxCodeLength + 1)
+	return 0
+}
+
+// codeLengthOrder is the order the code lengths of the code length code are sent in.
+var codeLengthOrder = [18]uint8{1, 2, 3, 4, 0, 5, 17, 6, 16, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// readHuffman reads a prefix code of an alphabet of size symbols (RFC 7932 3.4 and 3.5).
+func (r *Reader) readHuffman(h *huffman, size int) error {
+	br := &r.br
+	lengths := make([]uint8, size)
+	hskip := br.read(2)
+	if hskip == 1 {
+		return r.readSimpleHuffman(h, lengths)
+	}
+
+	var clens [18]uint8
+	space, codes := 32, 0
+	for _, sym := range codeLengthOrder[hskip:] {
+		// the code lengths are themselves sent with a fixed variable length code
+		br.fill(4)
+		var l uint8
+		switch p := br.bits & 15; {
+		case p&3 == 0:
+			l = 0
+			br.consume(2)
+		case p&3 == 1:
+			l = 4
+			br.consume(2)
+		case p&3 == 2:
+			l = 3
+			br.consume(2)
+		case p&7 == 3:
+			l = 2
+			br.consume(3)
+		case p == 7:
+			l = 1
+			br.consume(4)
+		default:
+			l = 5
+			br.consume(4)
+		}
+		clens[sym] = l
+		if l != 0 {
+			space -= 32 >> l
+			codes++
+			if space <= 0 {
+				break
+			}
+		}
+	}
+	if br.err != nil {
+		return br.err
+	}
+	if codes != 1 && space != 0 {
+		return errFormat("code length code")
+	}
+	var clcode huffman
+	clcode.build(clens[:])
+
+	prev, repeat, repeatLen := uint8(8), 0, uint8(0)
+	space = 1 << 15
+	for sym := 0; sym < size && space > 0; {
+		l := clcode.decode(br)
+		if br.err != nil {
+			return br.err
+		}
+		if l < 16 {
+			repeat = 0
+			lengths[sym] = uint8(l)
+			sym++
+			if l != 0 {
+				prev = uint8(l)
+				space -= 1 << 15 >> l
+			}
+			continue
+		}
+		// 16 repeats the previous non-zero length and 17 repeats zero, a
+		// run of repeats adding up to a larger count
+		extra, newLen := uint(2), prev
+		if l == 17 {
+			extra, newLen = 3, 0
+		}
+		if repeatLen != newLen {
+			repeat, repeatLen = 0, newLen
+		}
+		old := repeat
+		if repeat > 0 {
+			repeat = (repeat - 2) << extra
+		}
+		repeat += int(br.read(extra)) + 3
+		delta := repeat - old
+		if sym+delta > size {
+			return errFormat("code length repeat")
+		}
+		for i := 0; i < delta; i++ {
+			lengths[sym] = repeatLen
+			sym++
+		}
+		if repeatLen != 0 {
+			space -= delta << (15 - repeatLen)
+		}
+	}
+	if br.err != nil {
+		return br.err
+	}
+	if space != 0 {
+		return errFormat("prefix code")
+	}
+	h.build(lengths)
+	return nil
+}
+
+// readSimpleHuffman reads a code of one to four symbols with implied lengths.
+func (r *Reader) readSimpleHuffman(h *huffman, lengths []uint8) error {
+	br := &r.br
+	var bits uint
+	for 1<<bits < len(lengths) {
+		bits++
+	}
+	n := int(br.read(2)) + 1
+	var syms [4]int
+	for i := 0; i < n; i++ {
+		syms[i] = int(br.read(bits))
+		if syms[i] >= len(lengths) {
+			return errFormat("simple prefix code")
+		}
+		for _, s := range syms[:i] {
+			if s == syms[i] {
+				return errFormat("simple prefix code")
+			}
+		}
+	}
+	var lens [4]uint8
+	switch n {
+	case 1:
+		lens = [4]uint8{1}
+	case 2:
+		lens = [4]uint8{1, 1}
+	case 3:
+		lens = [4]uint8{1, 2, 2}
+	case 4:
+		lens = [4]uint8{2, 2, 2, 2}
+		if br.read(1) == 1 {
+			lens = [4]uint8{1, 2, 3, 3}
+		}
+	}
+	for i := 0; i < n; i++ {
+		lengths[syms[i]] = lens[i]
+	}
+	h.build(lengths)
+	return br.err
+}
@@ -0,0 +1,571 @@
+// Package brotli implements a decoder of the brotli compressed data format
+// (RFC 7932), used to read request bodies sent with Content-Encoding: br.
+package brotli
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+func errFormat(what string) error {
+	return errors.New("brotli: invalid " + what)
+}
+
+// chunkSize is about how much is decoded ahead of the reads.
+const chunkSize = 32 << 10
+
+type decodeState uint8
+
+const (
+	stateHeader decodeState = iota
+	stateStored
+	stateCommand
+	stateInsert
+	stateDistance
+	stateCopy
+	stateDone
+)
+
+// blockTypes tracks the block types of one category of symbols: literals,
+// insert-and-copy lengths or distances (RFC 7932 6).
+type blockTypes struct {
+	n      int
+	types  huffman
+	counts huffman
+	cur    int // last type
+	prev   int // second-to-last type
+	left   int // symbols left in the current block
+}
+
+// Reader decodes a brotli stream.
+type Reader struct {
+	br     bitReader
+	err    error
+	window int
+	state  decodeState
+
+	// hist holds the decoded bytes, of which the last window ones are kept
+	// for back references, and out the index of the next one to return.
+	hist []byte
+	out  int
+	pos  int64 // bytes decoded
+
+	dist [4]int // last distances, the last one first
+
+	// current meta-block
+	last      bool
+	remaining int
+	blocks    [3]blockTypes // literal, insert-and-copy and distance block types
+	postfix   uint
+	direct    int
+	modes     []uint8
+	literalCM []uint8
+	distCM    []uint8
+	literals  []huffman
+	commands  []huffman
+	distances []huffman
+
+	// current command
+	insert   int
+	copyLen  int
+	copyDist int
+	implicit bool
+}
+
+// NewReader returns a Reader of the decoded content of r, reading the
+// stream header. r is read a byte at a time and wrapped in a bufio.Reader
+// unless it is an io.ByteReader.
+func NewReader(r io.Reader) (*Reader, error) {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	d := &Reader{br: bitReader{r: br}, dist: [4]int{4, 11, 15, 16}}
+	wbits, err := d.readWindowBits()
+	if err != nil {
+		return nil, err
+	}
+	d.window = 1<<wbits - 16
+	return d, nil
+}
+
+// readWindowBits reads the stream header (RFC 7932 9.1).
+func (r *Reader) readWindowBits() (uint, error) {
+	br := &r.br
+	var wbits uint = 16
+	if br.read(1) == 1 {
+		if n := br.read(3); n != 0 {
+			wbits = 17 + uint(n)
+		} else {
+			switch n := br.read(3); n {
+			case 0:
+				wbits = 17
+			case 1:
+				return 0, errFormat("window size")
+			default:
+				wbits = 8 + uint(n)
+			}
+		}
+	}
+	if br.err != nil {
+		if br.err == io.ErrUnexpectedEOF {
+			return 0, io.EOF
+		}
+		return 0, br.err
+	}
+	return wbits, nil
+}
+
+func (r *Reader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	for r.out == len(r.hist) {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.compact()
+		r.err = r.decode()
+	}
+	n := copy(p, r.hist[r.out:])
+	r.out += n
+	return n, nil
+}
+
+// Close releases the decoded bytes, it does not close the underlying reader.
+func (r *Reader) Close() error {
+	r.hist, r.out = nil, 0
+	if r.err == nil {
+		r.err = errors.New("brotli: reader closed")
+	}
+	return nil
+}
+
+// compact drops the returned bytes that are beyond the window.
+func (r *Reader) compact() {
+	if len(r.hist) < 2*r.window {
+		return
+	}
+	n := copy(r.hist, r.hist[len(r.hist)-r.window:])
+	r.hist = r.hist[:n]
+	r.out = n
+}
+
+// decode decodes about chunkSize bytes into hist. It returns io.EOF at the
+// end of the stream.
+func (r *Reader) decode() error {
+	br := &r.br
+	for start := len(r.hist); len(r.hist)-start < chunkSize; {
+		if br.err != nil {
+			return br.err
+		}
+		switch r.state {
+		case stateHeader:
+			if err := r.readMetaBlockHeader(); err != nil {
+				return err
+			}
+		case stateStored:
+			if r.remaining == 0 {
+				r.state = stateHeader
+				continue
+			}
+			r.emit(byte(br.read(8)))
+			r.remaining--
+		case stateCommand:
+			if r.remaining == 0 {
+				r.state = stateHeader
+				if r.last {
+					if err := br.align(); err != nil {
+						return err
+					}
+					r.state = stateDone
+				}
+				continue
+			}
+			if err := r.readCommand(); err != nil {
+				return err
+			}
+		case stateInsert:
+			if r.insert == 0 {
+				// the copy of the command that ends a meta-block is left out
+				r.state = stateDistance
+				if r.remaining == 0 {
+					r.state = stateCommand
+				}
+				continue
+			}
+			r.readLiteral()
+			r.insert--
+			r.remaining--
+		case stateDistance:
+			if err := r.readDistance(); err != nil {
+				return err
+			}
+		case stateCopy:
+			if r.copyLen == 0 {
+				r.state = stateCommand
+				continue
+			}
+			n := r.copyLen
+			if n > chunkSize {
+				n = chunkSize
+			}
+			for i := 0; i < n; i++ {
+				r.hist = append(r.hist, r.hist[len(r.hist)-r.copyDist])
+			}
+			r.pos += int64(n)
+			r.copyLen -= n
+			r.remaining -= n
+		case stateDone:
+			if len(r.hist) > start {
+				return nil
+			}
+			return io.EOF
+		}
+	}
+	return nil
+}
+
+func (r *Reader) emit(b byte) {
+	r.hist = append(r.hist, b)
+	r.pos++
+}
+
+// readMetaBlockHeader reads the header of the next meta-block (RFC 7932 9.2).
+func (r *Reader) readMetaBlockHeader() error {
+	br := &r.br
+	r.last = br.read(1) == 1
+	if r.last && br.read(1) == 1 {
+		r.state = stateDone
+		return br.align()
+	}
+	nibbles := uint(br.read(2)) + 4
+	if nibbles == 7 {
+		return r.skipMetadata()
+	}
+	mlen := br.read(nibbles * 4)
+	if nibbles > 4 && mlen>>((nibbles-1)*4) == 0 {
+		return errFormat("meta-block length")
+	}
+	r.remaining = int(mlen) + 1
+	if !r.last && br.read(1) == 1 {
+		r.state = stateStored
+		return br.align()
+	}
+
+	for i := range r.blocks {
+		if err := r.readBlockTypes(&r.blocks[i]); err != nil {
+			return err
+		}
+	}
+	r.postfix = uint(br.read(2))
+	r.direct = int(br.read(4)) << r.postfix
+	r.modes = make([]uint8, r.blocks[0].n)
+	for i := range r.modes {
+		r.modes[i] = uint8(br.read(2))
+	}
+	var literalTrees, distTrees int
+	var err error
+	if r.literalCM, literalTrees, err = r.readContextMap(r.blocks[0].n << 6); err != nil {
+		return err
+	}
+	if r.distCM, distTrees, err = r.readContextMap(r.blocks[2].n << 2); err != nil {
+		return err
+	}
+	if r.literals, err = r.readHuffmans(literalTrees, 256); err != nil {
+		return err
+	}
+	if r.commands, err = r.readHuffmans(r.blocks[1].n, 704); err != nil {
+		return err
+	}
+	if r.distances, err = r.readHuffmans(distTrees, 16+r.direct+48<<r.postfix); err != nil {
+		return err
+	}
+	r.state = stateCommand
+	return br.err
+}
+
+// skipMetadata skips a metadata meta-block.
+func (r *Reader) skipMetadata() error {
+	br := &r.br
+	if br.read(1) != 0 {
+		return errFormat("meta-block reserved bit")
+	}
+	skipBytes := br.read(2)
+	skip := 0
+	for i := uint32(0); i < skipBytes; i++ {
+		b := int(br.read(8))
+		if i > 0 && i == skipBytes-1 && b == 0 {
+			return errFormat("metadata length")
+		}
+		skip |= b << (8 * i)
+	}
+	if skipBytes > 0 {
+		skip++
+	}
+	if err := br.align(); err != nil {
+		return err
+	}
+	for ; skip > 0 && br.err == nil; skip-- {
+		br.read(8)
+	}
+	r.state = stateHeader
+	if r.last {
+		r.state = stateDone
+	}
+	return br.err
+}
+
+// readCount reads a number of block types or of prefix codes, from 1 to 256.
+func (r *Reader) readCount() int {
+	br := &r.br
+	if br.read(1) == 0 {
+		return 1
+	}
+	n := uint(br.read(3))
+	if n == 0 {
+		return 2
+	}
+	return 1<<n + int(br.read(n)) + 1
+}
+
+func (r *Reader) readBlockTypes(b *blockTypes) error {
+	*b = blockTypes{n: r.readCount(), prev: 1}
+	if b.n < 2 {
+		return r.br.err
+	}
+	if err := r.readHuffman(&b.types, b.n+2); err != nil {
+		return err
+	}
+	if err := r.readHuffman(&b.counts, 26); err != nil {
+		return err
+	}
+	b.left = r.readBlockCount(b)
+	return r.br.err
+}
+
+var blockCountBase = [26]int{
+	1, 5, 9, 13, 17, 25, 33, 41, 49, 65, 81, 97, 113, 145, 177, 209,
+	241, 305, 369, 497, 753, 1265, 2289, 4337, 8433, 16625,
+}
+
+var blockCountBits = [26]uint{
+	2, 2, 2, 2, 3, 3, 3, 3, 4, 4, 4, 4, 5, 5, 5, 5,
+	6, 6, 7, 8, 9, 10, 11, 12, 13, 24,
+}
+
+func (r *Reader) readBlockCount(b *blockTypes) int {
+	sym := b.counts.decode(&r.br)
+	return blockCountBase[sym] + int(r.br.read(blockCountBits[sym]))
+}
+
+// nextBlock switches to the next block of b when the current one is over.
+func (r *Reader) nextBlock(b *blockTypes) {
+	if b.n < 2 {
+		return
+	}
+	if b.left > 0 {
+		b.left--
+		return
+	}
+	t := b.types.decode(&r.br)
+	switch t {
+	case 0:
+		t = b.prev
+	case 1:
+		t = (b.cur + 1) % b.n
+	default:
+		t -= 2
+	}
+	b.prev, b.cur = b.cur, t
+	b.left = r.readBlockCount(b) - 1
+}
+
+// readContextMap reads a context map of size entries and returns it with the
+// number of prefix codes it maps to (RFC 7932 7.3).
+func (r *Reader) readContextMap(size int) ([]uint8, int, error) {
+	br := &r.br
+	trees := r.readCount()
+	cmap := make([]uint8, size)
+	if trees < 2 {
+		return cmap, trees, br.err
+	}
+	rleMax := 0
+	if br.read(1) == 1 {
+		rleMax = int(br.read(4)) + 1
+	}
+	var h huffman
+	if err := r.readHuffman(&h, trees+rleMax); err != nil {
+		return nil, 0, err
+	}
+	for i := 0; i < size; {
+		sym := h.decode(br)
+		if br.err != nil {
+			return nil, 0, br.err
+		}
+		switch {
+		case sym == 0:
+			i++
+		case sym <= rleMax:
+			zeros := 1<<uint(sym) + int(br.read(uint(sym)))
+			if i+zeros > size {
+				return nil, 0, errFormat("context map")
+			}
+			i += zeros
+		default:
+			cmap[i] = uint8(sym - rleMax)
+			i++
+		}
+	}
+	if br.read(1) == 1 {
+		// inverse move-to-front transform
+		var mtf [256]uint8
+		for i := range mtf {
+			mtf[i] = uint8(i)
+		}
+		for i, idx := range cmap {
+			v := mtf[idx]
+			cmap[i] = v
+			copy(mtf[1:int(idx)+1], mtf[:idx])
+			mtf[0] = v
+		}
+	}
+	return cmap, trees, br.err
+}
+
+func (r *Reader) readHuffmans(n, size int) ([]huffman, error) {
+	hs := make([]huffman, n)
+	for i := range hs {
+		if err := r.readHuffman(&hs[i], size); err != nil {
+			return nil, err
+		}
+	}
+	return hs, nil
+}
+
+// insert-and-copy length code cells (RFC 7932 5)
+var (
+	insertCellBase = [11]int{0, 0, 0, 0, 8, 8, 0, 16, 8, 16, 16}
+	copyCellBase   = [11]int{0, 8, 0, 8, 0, 8, 16, 0, 16, 8, 16}
+
+	insertBase = [24]int{
+		0, 1, 2, 3, 4, 5, 6, 8, 10, 14, 18, 26, 34, 50, 66, 98,
+		130, 194, 322, 578, 1090, 2114, 6210, 22594,
+	}
+	insertBits = [24]uint{
+		0, 0, 0, 0, 0, 0, 1, 1, 2, 2, 3, 3, 4, 4, 5, 5,
+		6, 7, 8, 9, 10, 12, 14, 24,
+	}
+	copyBase = [24]int{
+		2, 3, 4, 5, 6, 7, 8, 9, 10, 12, 14, 18, 22, 30, 38, 54,
+		70, 102, 134, 198, 326, 582, 1094, 2118,
+	}
+	copyBits = [24]uint{
+		0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 2, 2, 3, 3, 4, 4,
+		5, 5, 6, 7, 8, 9, 10, 24,
+	}
+)
+
+func (r *Reader) readCommand() error {
+	br := &r.br
+	b := &r.blocks[1]
+	r.nextBlock(b)
+	sym := r.commands[b.cur].decode(br)
+	cell := sym >> 6
+	insertCode := insertCellBase[cell] + sym>>3&7
+	copyCode := copyCellBase[cell] + sym&7
+	r.insert = insertBase[insertCode] + int(br.read(insertBits[insertCode]))
+	r.copyLen = copyBase[copyCode] + int(br.read(copyBits[copyCode]))
+	r.implicit = cell < 2
+	if r.insert > r.remaining {
+		return errFormat("insert length")
+	}
+	r.state = stateInsert
+	return br.err
+}
+
+func (r *Reader) readLiteral() {
+	b := &r.blocks[0]
+	r.nextBlock(b)
+	var p1, p2 byte
+	if n := len(r.hist); n > 1 {
+		p1, p2 = r.hist[n-1], r.hist[n-2]
+	} else if n == 1 {
+		p1 = r.hist[0]
+	}
+	tree := r.literalCM[b.cur<<6|literalContext(r.modes[b.cur], p1, p2)]
+	r.emit(byte(r.literals[tree].decode(&r.br)))
+}
+
+// readDistance reads the distance of the copy and emits a dictionary word
+// when it reaches beyond the decoded bytes (RFC 7932 4).
+func (r *Reader) readDistance() error {
+	br := &r.br
+	code := 0
+	if !r.implicit {
+		b := &r.blocks[2]
+		r.nextBlock(b)
+		ctx := r.copyLen - 2
+		if ctx > 3 {
+			ctx = 3
+		}
+		code = r.distances[r.distCM[b.cur<<2|ctx]].decode(br)
+	}
+	var dist int
+	switch {
+	case code < 16:
+		dist = r.dist[distRing[code]] + distDelta[code]
+		if dist <= 0 {
+			return errFormat("distance")
+		}
+	case code < 16+r.direct:
+		dist = code - 15
+	default:
+		d := code - 16 - r.direct
+		hcode := d >> r.postfix
+		lcode := d & (1<<r.postfix - 1)
+		nbits := 1 + uint(hcode>>1)
+		offset := (2+hcode&1)<<nbits - 4
+		dist = (offset+int(br.read(nbits)))<<r.postfix + lcode + r.direct + 1
+	}
+	if br.err != nil {
+		return br.err
+	}
+
+	maxDist := int64(r.window)
+	if r.pos < maxDist {
+		maxDist = r.pos
+	}
+	if int64(dist) > maxDist {
+		w, err := word(r.copyLen, dist-int(maxDist)-1)
+		if err != nil {
+			return err
+		}
+		if len(w) > r.remaining {
+			return errFormat("dictionary word length")
+		}
+		for _, c := range w {
+			r.emit(c)
+		}
+		r.remaining -= len(w)
+		r.state = stateCommand
+		return nil
+	}
+	if code != 0 {
+		r.dist = [4]int{dist, r.dist[0], r.dist[1], r.dist[2]}
+	}
+	if r.copyLen > r.remaining {
+		return errFormat("copy length")
+	}
+	r.copyDist = dist
+	r.state = stateCopy
+	return nil
+}
+
+// distRing and distDelta give the distances of the codes below 16, relative
+// to the last ones.
+var (
+	distRing  = [16]int{0, 1, 2, 3, 0, 0, 0, 0, 0, 0, 1, 1, 1, 1, 1, 1}
+	distDelta = [16]int{0, 0, 0, 0, -1, 1, -2, 2, -3, 3, -1, 1, -2, 2, -3, 3}
+)
@@ -0,0 +1,226 @@
+package brotli
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// testText returns the text compressed in testdata: sentences of common
+// words picked by a linear congruential generator.
+func testText() []byte {
+	words := strings.Fields("the of and to in is that for it as with was on be by at this " +
+		"from have or one had not but what all were when we there can an your which their " +
+		"time people year way day man thing woman life child world school state family")
+	var b bytes.Buffer
+	seed := uint32(1)
+	next := func(n int) int {
+		seed = seed*1664525 + 1013904223
+		return int(seed>>16) % n
+	}
+	for b.Len() < 16<<10 {
+		n := 4 + next(12)
+		for i := 0; i < n; i++ {
+			w := words[next(len(words))]
+			if i == 0 {
+				w = strings.ToUpper(w[:1]) + w[1:]
+			} else {
+				b.WriteByte(' ')
+			}
+			b.WriteString(w)
+		}
+		b.WriteString(". ")
+		if next(8) == 0 {
+			b.WriteString("\n")
+		}
+	}
+	return b.Bytes()
+}
+
+func TestReader(t *testing.T) {
+	want := testText()
+	// compressed by the reference encoder at the quality and window size of their name
+	for _, name := range []string{"text.q1.br", "text.q5.br", "text.q11.br"} {
+		b, err := os.ReadFile("testdata/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := NewReader(iotest.OneByteReader(bytes.NewReader(b)))
+		if err != nil {
+			t.Fatalf("%s: NewReader() error = %v", name, err)
+		}
+		got, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("%s: Read() error = %v", name, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s: decoded %d bytes, want %d", name, len(got), len(want))
+		}
+
+		r, _ = NewReader(bytes.NewReader(b[:len(b)/2]))
+		if _, err := io.ReadAll(r); err != io.ErrUnexpectedEOF {
+			t.Errorf("%s: truncated Read() error = %v, want io.ErrUnexpectedEOF", name, err)
+		}
+	}
+}
+
+// bitWriter writes the streams of the tests least significant bit first.
+type bitWriter struct {
+	buf  []byte
+	bits uint64
+	n    uint
+}
+
+func (w *bitWriter) write(v int, n uint) {
+	w.bits |= uint64(v) << w.n
+	w.n += n
+	for w.n >= 8 {
+		w.buf = append(w.buf, byte(w.bits))
+		w.bits >>= 8
+		w.n -= 8
+	}
+}
+
+func (w *bitWriter) bytes() []byte {
+	if w.n > 0 {
+		w.write(0, 8-w.n)
+	}
+	return w.buf
+}
+
+func (w *bitWriter) metadata(p string) {
+	w.write(0, 1) // ISLAST
+	w.write(3, 2) // MNIBBLES 0
+	w.write(0, 1) // reserved
+	w.write(1, 2) // MSKIPBYTES
+	w.write(len(p)-1, 8)
+	w.bytes()
+	w.buf = append(w.buf, p...)
+}
+
+func (w *bitWriter) stored(p string) {
+	w.write(0, 1) // ISLAST
+	w.write(0, 2) // MNIBBLES 4
+	w.write(len(p)-1, 16)
+	w.write(1, 1) // ISUNCOMPRESSED
+	w.bytes()
+	w.buf = append(w.buf, p...)
+}
+
+// copy writes a last meta-block of mlen bytes of copies of length bytes at
+// distance dist, a single command when mlen is length.
+func (w *bitWriter) copy(dist, length, mlen int) {
+	w.write(1, 1) // ISLAST
+	w.write(0, 1) // ISLASTEMPTY
+	w.write(0, 2) // MNIBBLES 4
+	w.write(mlen-1, 16)
+	w.write(0, 3) // one block type of each category
+	w.write(0, 6) // NPOSTFIX, NDIRECT
+	w.write(0, 2) // CMODE
+	w.write(0, 2) // one literal and one distance prefix code
+	d := dist - 1
+	hcode, nbits, offset := 0, uint(1), 0
+	for ; d >= offset+1<<nbits; hcode++ {
+		nbits = uint(1 + (hcode+1)>>1)
+		offset = (2+(hcode+1)&1)<<nbits - 4
+	}
+	// prefix codes of a single symbol, which take no bits in the commands
+	for _, sym := range [][2]int{{0, 8}, {128 + length - 2, 10}, {16 + hcode, 6}} {
+		w.write(1, 2) // HSKIP
+		w.write(0, 2) // NSYM-1
+		w.write(sym[0], uint(sym[1]))
+	}
+	w.write(d-offset, nbits)
+}
+
+func TestReader_Stream(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream func(w *bitWriter)
+		want   string
+		err    string
+	}{
+		{"empty", func(w *bitWriter) { w.write(1, 1); w.write(1, 1) }, "", ""},
+		{"blocks", func(w *bitWriter) {
+			w.metadata("skipped")
+			w.stored("stored ")
+			w.copy(7+1+44<<10, 4, 4) // dictionary word beyond the 7 bytes
+		}, "stored TIME", ""},
+		{"transform out of range", func(w *bitWriter) {
+			w.copy(1+121<<10, 4, 4)
+		}, "", "brotli: invalid dictionary transform"},
+		{"copy beyond meta-block", func(w *bitWriter) {
+			// the second command copies 4 bytes at distance 1 where 1 is left
+			w.copy(1, 4, 5)
+		}, "time", "brotli: invalid copy length"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bitWriter{}
+			w.write(0, 1) // WBITS 16
+			tt.stream(w)
+			r, err := NewReader(bytes.NewReader(w.bytes()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := io.ReadAll(r)
+			if string(got) != tt.want || (err == nil) != (tt.err == "") || (err != nil && err.Error() != tt.err) {
+				t.Errorf("Read() = %q, %v, want %q, %s", got, err, tt.want, tt.err)
+			}
+		})
+	}
+
+	// bytes beyond twice the window are dropped while reading
+	stored := make([]byte, 40000)
+	for i := range stored {
+		stored[i] = byte(i % 251)
+	}
+	w := &bitWriter{}
+	w.write(0x21, 7) // WBITS 10
+	w.stored(string(stored))
+	w.copy(1000, 9, 9)
+	r, err := NewReader(bytes.NewReader(w.bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(r)
+	if want := append(stored, stored[len(stored)-1000:][:9]...); err != nil || !bytes.Equal(got, want) {
+		t.Errorf("Read() with a small window = %d bytes, %v", len(got), err)
+	}
+
+	if _, err := NewReader(bytes.NewReader([]byte{0x11})); err == nil {
+		t.Error("NewReader() with the reserved window size did not fail")
+	}
+	if _, err := NewReader(bytes.NewReader(nil)); err != io.EOF {
+		t.Errorf("NewReader() of an empty body error = %v, want io.EOF", err)
+	}
+}
+
+func TestWord(t *testing.T) {
+	tests := []struct {
+		length, id int
+		want       string
+	}{
+		{4, 0, "time"},
+		{4, 5 << 10, "time the "},
+		{4, 9 << 10, "Time"},
+		{4, 44 << 10, "TIME"},
+		{4, 12 << 10, "tim"},
+		{9, 3 << 10, "esources"},
+		{4, 40 << 10, ""},
+		{6, 44<<11 | 627, "\xd8\x91\xd8\x8f\xd9\xa8"},
+		{6, 44<<11 | 628, "\xe4\xb8\xa8\xe6\x96\x82"},
+	}
+	for _, tt := range tests {
+		got, err := word(tt.length, tt.id)
+		if err != nil || string(got) != tt.want {
+			t.Errorf("word(%d, %d) = %q, %v, want %q", tt.length, tt.id, got, err, tt.want)
+		}
+	}
+	if _, err := word(3, 0); err == nil {
+		t.Error("word() of length 3 did not fail")
+	}
+}
@@ -0,0 +1,115 @@
+// Package bufwriter implements a response writer holding back the response of
+// a handler, for middlewares that inspect it before it is sent.
+package bufwriter
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"net/http"
+
+	"github.com/gostack-labs/bytego"
+	"github.com/gostack-labs/bytego/internal/respctl"
+)
+
+// Writer holds back the status and body until Release is called. It switches
+// to writing through when the handler flushes or hijacks.
+type Writer struct {
+	bytego.ResponseWriter
+	buf         bytes.Buffer
+	status      int
+	wroteHeader bool
+	passthrough bool
+}
+
+func New(w bytego.ResponseWriter) *Writer {
+	return &Writer{ResponseWriter: w}
+}
+
+// Buffered reports whether a response is held back, i.e. the handler wrote
+// one and did not flush or hijack.
+func (w *Writer) Buffered() bool {
+	return w.wroteHeader && !w.passthrough
+}
+
+// Bytes returns the held back body.
+func (w *Writer) Bytes() []byte {
+	return w.buf.Bytes()
+}
+
+func (w *Writer) WriteHeader(code int) {
+	if w.passthrough {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if !w.wroteHeader {
+		w.status = code
+		w.wroteHeader = true
+	}
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.passthrough {
+		return w.ResponseWriter.Write(p)
+	}
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.buf.Write(p)
+}
+
+func (w *Writer) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *Writer) Status() int {
+	if w.Buffered() {
+		return w.status
+	}
+	return w.ResponseWriter.Status()
+}
+
+func (w *Writer) Size() int {
+	return w.buf.Len() + w.ResponseWriter.Size()
+}
+
+func (w *Writer) Committed() bool {
+	return w.wroteHeader || w.ResponseWriter.Committed()
+}
+
+func (w *Writer) Flush() {
+	if !w.passthrough {
+		if !w.wroteHeader {
+			w.WriteHeader(http.StatusOK)
+		}
+		_ = w.Release(nil)
+	}
+	_ = respctl.Flush(w.ResponseWriter)
+}
+
+func (w *Writer) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.passthrough = true
+	return respctl.Hijack(w.ResponseWriter)
+}
+
+func (w *Writer) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Release writes the held back response, if any, and switches to writing
+// through. It returns err, or else the write error.
+func (w *Writer) Release(err error) error {
+	if w.passthrough {
+		return err
+	}
+	w.passthrough = true
+	if !w.wroteHeader {
+		return err
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	if _, werr := w.ResponseWriter.Write(w.buf.Bytes()); err == nil {
+		err = werr
+	}
+	w.buf.Reset()
+	return err
+}
@@ -0,0 +1,97 @@
+// Package extractor reads tokens and keys from the places named by the lookup
+// strings of the auth middlewares, e.g. "header:Authorization,query:token".
+package extractor
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gostack-labs/bytego"
+)
+
+const headerAuthorization = "Authorization"
+
+// Extractor returns the value it looks up, or "" when the request has none.
+type Extractor func(c *bytego.Ctx) string
+
+// Lookup is a list of extractors tried in order.
+type Lookup []Extractor
+
+// Extract returns the first value found by the extractors.
+func (l Lookup) Extract(c *bytego.Ctx) string {
+	for _, extract := range l {
+		if v := extract(c); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// Parse returns the extractors of a comma separated list of "<source>:<name>"
+// pairs, each source being one of sources: header, query, cookie or form. The
+// Authorization header is only read when it starts with scheme, which is then
+// stripped, unless scheme is empty.
+func Parse(lookup, scheme string, sources ...string) (Lookup, error) {
+	var l Lookup
+	for _, part := range strings.Split(lookup, ",") {
+		part = strings.TrimSpace(part)
+		i := strings.IndexByte(part, ':')
+		if i < 0 || i == len(part)-1 {
+			return nil, errors.New("invalid lookup " + part)
+		}
+		source, name := part[:i], part[i+1:]
+		if !contains(sources, source) {
+			return nil, errors.New("unknown lookup source " + source)
+		}
+		switch source {
+		case "header":
+			l = append(l, FromHeader(name, scheme))
+		case "query":
+			l = append(l, func(c *bytego.Ctx) string {
+				return c.Query(name)
+			})
+		case "cookie":
+			l = append(l, func(c *bytego.Ctx) string {
+				cookie, err := c.Cookie(name)
+				if err != nil {
+					return ""
+				}
+				return cookie.Value
+			})
+		case "form":
+			l = append(l, func(c *bytego.Ctx) string {
+				return c.Form(name)
+			})
+		default:
+			return nil, errors.New("unknown lookup source " + source)
+		}
+	}
+	return l, nil
+}
+
+// FromHeader reads the header name, stripping the auth scheme from the
+// Authorization header.
+func FromHeader(name, scheme string) Extractor {
+	if scheme == "" || !strings.EqualFold(name, headerAuthorization) {
+		return func(c *bytego.Ctx) string {
+			return c.Header(name)
+		}
+	}
+	prefix := scheme + " "
+	return func(c *bytego.Ctx) string {
+		v := c.Header(name)
+		if len(v) > len(prefix) && strings.EqualFold(v[:len(prefix)], prefix) {
+			return strings.TrimSpace(v[len(prefix):])
+		}
+		return ""
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
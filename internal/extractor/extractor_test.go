@@ -0,0 +1,59 @@
+package extractor
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gostack-labs/bytego"
+)
+
+func TestParse(t *testing.T) {
+	lookup, err := Parse("header:Authorization,header:X-Key,query:key,cookie:key,form:key", "Bearer",
+		"header", "query", "cookie", "form")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		req  func(r *http.Request)
+		want string
+	}{
+		{"authorization", func(r *http.Request) { r.Header.Set("Authorization", "bearer  a") }, "a"},
+		{"other scheme", func(r *http.Request) { r.Header.Set("Authorization", "Basic a") }, ""},
+		{"header", func(r *http.Request) { r.Header.Set("X-Key", "b") }, "b"},
+		{"query", func(r *http.Request) { r.URL.RawQuery = "key=c" }, "c"},
+		{"cookie", func(r *http.Request) { r.AddCookie(&http.Cookie{Name: "key", Value: "d"}) }, "d"},
+		{"form", func(r *http.Request) {
+			r.Method = http.MethodPost
+			r.Body = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("key=e")).Body
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}, "e"},
+		{"order", func(r *http.Request) {
+			r.Header.Set("X-Key", "b")
+			r.URL.RawQuery = "key=c"
+		}, "b"},
+		{"none", func(r *http.Request) {}, ""},
+	}
+	for _, tt := range tests {
+		var got string
+		app := bytego.New()
+		app.Any("/", func(c *bytego.Ctx) error {
+			got = lookup.Extract(c)
+			return nil
+		})
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		tt.req(req)
+		app.Handler().ServeHTTP(httptest.NewRecorder(), req)
+		if got != tt.want {
+			t.Errorf("%s: Extract() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	for _, lookup := range []string{"header", "header:", "cookie:key", "param:id"} {
+		if _, err := Parse(lookup, "", "header", "query"); err == nil {
+			t.Errorf("Parse(%q) did not fail", lookup)
+		}
+	}
+}
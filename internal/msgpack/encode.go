@@ -0,0 +1,344 @@
+// Package msgpack implements a minimal MessagePack encoder for response rendering.
+package msgpack
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Marshal returns the MessagePack encoding of v. Structs are encoded as maps
+// with fields named by their `msgpack` tag, then their `json` tag, then the
+// field name. time.Time uses the timestamp extension type.
+func Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type Encoder struct {
+	w   io.Writer
+	buf bytes.Buffer
+}
+
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Encode writes the MessagePack encoding of v to the underlying writer.
+func (e *Encoder) Encode(v interface{}) error {
+	e.buf.Reset()
+	if err := e.value(reflect.ValueOf(v)); err != nil {
+		return err
+	}
+	_, err := e.w.Write(e.buf.Bytes())
+	return err
+}
+
+var (
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	timeType          = reflect.TypeOf(time.Time{})
+)
+
+func (e *Encoder) value(v reflect.Value) error {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			break
+		}
+		if v.Kind() == reflect.Ptr && v.Type().Implements(textMarshalerType) && v.Type().Elem() != timeType {
+			break
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() || ((v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil()) {
+		e.buf.WriteByte(0xc0)
+		return nil
+	}
+	if v.Type() == timeType {
+		e.time(v.Interface().(time.Time))
+		return nil
+	}
+	if v.Type().Implements(textMarshalerType) {
+		b, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return err
+		}
+		e.str(string(b))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			e.buf.WriteByte(0xc3)
+		} else {
+			e.buf.WriteByte(0xc2)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.int(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.uint(v.Uint())
+	case reflect.Float32:
+		e.buf.WriteByte(0xca)
+		e.uint32(math.Float32bits(float32(v.Float())))
+	case reflect.Float64:
+		e.buf.WriteByte(0xcb)
+		e.uint64(math.Float64bits(v.Float()))
+	case reflect.String:
+		e.str(v.String())
+	case reflect.Slice:
+		if v.IsNil() {
+			e.buf.WriteByte(0xc0)
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			e.bin(v.Bytes())
+			return nil
+		}
+		return e.array(v)
+	case reflect.Array:
+		return e.array(v)
+	case reflect.Map:
+		if v.IsNil() {
+			e.buf.WriteByte(0xc0)
+			return nil
+		}
+		return e.mapping(v)
+	case reflect.Struct:
+		return e.structure(v)
+	default:
+		return fmt.Errorf("msgpack: unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+func (e *Encoder) int(i int64) {
+	switch {
+	case i >= 0:
+		e.uint(uint64(i))
+	case i >= -32:
+		e.buf.WriteByte(byte(i))
+	case i >= math.MinInt8:
+		e.buf.Write([]byte{0xd0, byte(i)})
+	case i >= math.MinInt16:
+		e.buf.WriteByte(0xd1)
+		e.uint16(uint16(i))
+	case i >= math.MinInt32:
+		e.buf.WriteByte(0xd2)
+		e.uint32(uint32(i))
+	default:
+		e.buf.WriteByte(0xd3)
+		e.uint64(uint64(i))
+	}
+}
+
+func (e *Encoder) uint(u uint64) {
+	switch {
+	case u <= 0x7f:
+		e.buf.WriteByte(byte(u))
+	case u <= math.MaxUint8:
+		e.buf.Write([]byte{0xcc, byte(u)})
+	case u <= math.MaxUint16:
+		e.buf.WriteByte(0xcd)
+		e.uint16(uint16(u))
+	case u <= math.MaxUint32:
+		e.buf.WriteByte(0xce)
+		e.uint32(uint32(u))
+	default:
+		e.buf.WriteByte(0xcf)
+		e.uint64(u)
+	}
+}
+
+func (e *Encoder) str(s string) {
+	n := len(s)
+	switch {
+	case n < 32:
+		e.buf.WriteByte(0xa0 | byte(n))
+	case n <= math.MaxUint8:
+		e.buf.Write([]byte{0xd9, byte(n)})
+	case n <= math.MaxUint16:
+		e.buf.WriteByte(0xda)
+		e.uint16(uint16(n))
+	default:
+		e.buf.WriteByte(0xdb)
+		e.uint32(uint32(n))
+	}
+	e.buf.WriteString(s)
+}
+
+func (e *Encoder) bin(b []byte) {
+	n := len(b)
+	switch {
+	case n <= math.MaxUint8:
+		e.buf.Write([]byte{0xc4, byte(n)})
+	case n <= math.MaxUint16:
+		e.buf.WriteByte(0xc5)
+		e.uint16(uint16(n))
+	default:
+		e.buf.WriteByte(0xc6)
+		e.uint32(uint32(n))
+	}
+	e.buf.Write(b)
+}
+
+func (e *Encoder) array(v reflect.Value) error {
+	n := v.Len()
+	switch {
+	case n < 16:
+		e.buf.WriteByte(0x90 | byte(n))
+	case n <= math.MaxUint16:
+		e.buf.WriteByte(0xdc)
+		e.uint16(uint16(n))
+	default:
+		e.buf.WriteByte(0xdd)
+		e.uint32(uint32(n))
+	}
+	for i := 0; i < n; i++ {
+		if err := e.value(v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *Encoder) mapHeader(n int) {
+	switch {
+	case n < 16:
+		e.buf.WriteByte(0x80 | byte(n))
+	case n <= math.MaxUint16:
+		e.buf.WriteByte(0xde)
+		e.uint16(uint16(n))
+	default:
+		e.buf.WriteByte(0xdf)
+		e.uint32(uint32(n))
+	}
+}
+
+// mapping writes the entries of v sorted by key for a deterministic output.
+func (e *Encoder) mapping(v reflect.Value) error {
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+	})
+	e.mapHeader(len(keys))
+	for _, k := range keys {
+		if err := e.value(k); err != nil {
+			return err
+		}
+		if err := e.value(v.MapIndex(k)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type field struct {
+	name string
+	val  reflect.Value
+}
+
+func (e *Encoder) structure(v reflect.Value) error {
+	fields := structFields(v)
+	e.mapHeader(len(fields))
+	for _, f := range fields {
+		e.str(f.name)
+		if err := e.value(f.val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func structFields(v reflect.Value) []field {
+	var fields []field
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue
+		}
+		name, omitempty, skip := fieldName(sf)
+		if skip {
+			continue
+		}
+		fv := v.Field(i)
+		if sf.Anonymous && name == "" {
+			for fv.Kind() == reflect.Ptr && !fv.IsNil() {
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				fields = append(fields, structFields(fv)...)
+			}
+			continue
+		}
+		if sf.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		if omitempty && fv.IsZero() {
+			continue
+		}
+		fields = append(fields, field{name: name, val: fv})
+	}
+	return fields
+}
+
+func fieldName(sf reflect.StructField) (name string, omitempty bool, skip bool) {
+	tag, ok := sf.Tag.Lookup("msgpack")
+	if !ok {
+		tag = sf.Tag.Get("json")
+	}
+	if tag == "-" {
+		return "", false, true
+	}
+	parts := strings.Split(tag, ",")
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitempty = true
+		}
+	}
+	return parts[0], omitempty, false
+}
+
+// time writes t with the timestamp extension type -1, in its 32 bit form when
+// it has no fraction and fits, and in its 96 bit form otherwise.
+func (e *Encoder) time(t time.Time) {
+	sec, nsec := t.Unix(), t.Nanosecond()
+	if nsec == 0 && sec >= 0 && sec <= math.MaxUint32 {
+		e.buf.Write([]byte{0xd6, 0xff})
+		e.uint32(uint32(sec))
+		return
+	}
+	e.buf.Write([]byte{0xc7, 12, 0xff})
+	e.uint32(uint32(nsec))
+	e.uint64(uint64(sec))
+}
+
+func (e *Encoder) uint16(n uint16) {
+	var b [2]byte
+	binary.BigEndian.PutUint16(b[:], n)
+	e.buf.Write(b[:])
+}
+
+func (e *Encoder) uint32(n uint32) {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], n)
+	e.buf.Write(b[:])
+}
+
+func (e *Encoder) uint64(n uint64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], n)
+	e.buf.Write(b[:])
+}
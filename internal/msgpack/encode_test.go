@@ -0,0 +1,298 @@
+package msgpack
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// decoder reads every MessagePack format back, with integers as int64 (or
+// uint64 above math.MaxInt64), str as string, bin as []byte and the timestamp
+// extension as time.Time.
+type decoder struct {
+	b []byte
+}
+
+func decode(t *testing.T, data []byte) interface{} {
+	t.Helper()
+	d := &decoder{b: data}
+	v, err := d.value()
+	if err == nil && len(d.b) > 0 {
+		err = fmt.Errorf("%d trailing bytes", len(d.b))
+	}
+	if err != nil {
+		t.Fatalf("decode % x: %v", data, err)
+	}
+	return v
+}
+
+func (d *decoder) next(n int) ([]byte, error) {
+	if len(d.b) < n {
+		return nil, fmt.Errorf("short buffer: need %d, have %d", n, len(d.b))
+	}
+	b := d.b[:n]
+	d.b = d.b[n:]
+	return b, nil
+}
+
+func (d *decoder) uint(n int) (uint64, error) {
+	b, err := d.next(n)
+	if err != nil {
+		return 0, err
+	}
+	var u uint64
+	for _, c := range b {
+		u = u<<8 | uint64(c)
+	}
+	return u, nil
+}
+
+func (d *decoder) value() (interface{}, error) {
+	b, err := d.next(1)
+	if err != nil {
+		return nil, err
+	}
+	c := b[0]
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xf0 == 0x80:
+		return d.mapping(int(c & 0x0f))
+	case c&0xf0 == 0x90:
+		return d.array(int(c & 0x0f))
+	case c&0xe0 == 0xa0:
+		return d.str(int(c & 0x1f))
+	}
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2, 0xc3:
+		return c == 0xc3, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.uint(1 << (c - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.next(int(n))
+		return append([]byte{}, b...), err
+	case 0xca:
+		u, err := d.uint(4)
+		return math.Float32frombits(uint32(u)), err
+	case 0xcb:
+		u, err := d.uint(8)
+		return math.Float64frombits(u), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		u, err := d.uint(1 << (c - 0xcc))
+		if u > math.MaxInt64 {
+			return u, err
+		}
+		return int64(u), err
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		n := 1 << (c - 0xd0)
+		u, err := d.uint(n)
+		shift := 64 - 8*n
+		return int64(u<<shift) >> shift, err
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.uint(1 << (c - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return d.str(int(n))
+	case 0xdc, 0xdd:
+		n, err := d.uint(2 << (c - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.array(int(n))
+	case 0xde, 0xdf:
+		n, err := d.uint(2 << (c - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.mapping(int(n))
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.ext(1 << (c - 0xd4))
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.uint(1 << (c - 0xc7))
+		if err != nil {
+			return nil, err
+		}
+		return d.ext(int(n))
+	}
+	return nil, fmt.Errorf("invalid format 0x%02x", c)
+}
+
+func (d *decoder) str(n int) (interface{}, error) {
+	b, err := d.next(n)
+	return string(b), err
+}
+
+func (d *decoder) array(n int) (interface{}, error) {
+	arr := make([]interface{}, n)
+	for i := range arr {
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		arr[i] = v
+	}
+	return arr, nil
+}
+
+func (d *decoder) mapping(n int) (interface{}, error) {
+	m := make(map[interface{}]interface{}, n)
+	for i := 0; i < n; i++ {
+		k, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := m[k]; ok {
+			return nil, fmt.Errorf("duplicate key %v", k)
+		}
+		m[k] = v
+	}
+	return m, nil
+}
+
+func (d *decoder) ext(n int) (interface{}, error) {
+	typ, err := d.next(1)
+	if err != nil {
+		return nil, err
+	}
+	data, err := d.next(n)
+	if err != nil {
+		return nil, err
+	}
+	if int8(typ[0]) != -1 {
+		return nil, fmt.Errorf("unknown extension type %d", int8(typ[0]))
+	}
+	switch n {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(data)), 0), nil
+	case 8:
+		u := binary.BigEndian.Uint64(data)
+		return time.Unix(int64(u&(1<<34-1)), int64(u>>34)), nil
+	case 12:
+		return time.Unix(int64(binary.BigEndian.Uint64(data[4:])), int64(binary.BigEndian.Uint32(data))), nil
+	}
+	return nil, fmt.Errorf("invalid timestamp length %d", n)
+}
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		v    interface{}
+		want string
+	}{
+		{nil, "c0"},
+		{false, "c2"},
+		{true, "c3"},
+		{0, "00"},
+		{127, "7f"},
+		{128, "cc 80"},
+		{255, "cc ff"},
+		{256, "cd 01 00"},
+		{65536, "ce 00 01 00 00"},
+		{uint64(math.MaxUint64), "cf ff ff ff ff ff ff ff ff"},
+		{-1, "ff"},
+		{-32, "e0"},
+		{-33, "d0 df"},
+		{-129, "d1 ff 7f"},
+		{-32769, "d2 ff ff 7f ff"},
+		{int64(math.MinInt64), "d3 80 00 00 00 00 00 00 00"},
+		{float32(1.5), "ca 3f c0 00 00"},
+		{1.5, "cb 3f f8 00 00 00 00 00 00"},
+		{"", "a0"},
+		{"abc", "a3 61 62 63"},
+		{[]byte{1, 2}, "c4 02 01 02"},
+		{[]int{}, "90"},
+		{[]int(nil), "c0"},
+		{[2]bool{true, false}, "92 c3 c2"},
+		{map[string]int{"b": 2, "a": 1}, "82 a1 61 01 a1 62 02"},
+		{time.Unix(1, 0), "d6 ff 00 00 00 01"},
+		{time.Unix(1, 2), "c7 0c ff 00 00 00 02 00 00 00 00 00 00 00 01"},
+		{net.IPv4(10, 0, 0, 1), "a8 31 30 2e 30 2e 30 2e 31"},
+		{big.NewInt(42), "a2 34 32"},
+	}
+	for _, tt := range tests {
+		got, err := Marshal(tt.v)
+		if err != nil {
+			t.Errorf("Marshal(%#v): %v", tt.v, err)
+			continue
+		}
+		if fmt.Sprintf("% x", got) != tt.want {
+			t.Errorf("Marshal(%#v) = % x, want %s", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestMarshal_RoundTrip(t *testing.T) {
+	type Base struct {
+		ID int `msgpack:"id"`
+	}
+	type inner struct {
+		Name string `json:"name"`
+	}
+	type doc struct {
+		Base
+		Int     int8                   `msgpack:"int"`
+		Uint    uint16                 `msgpack:"uint"`
+		Float   float32                `msgpack:"float"`
+		Inf     float64                `msgpack:"inf"`
+		Str     string                 `msgpack:"str"`
+		Bytes   []byte                 `msgpack:"bytes"`
+		Time    time.Time              `msgpack:"time"`
+		Before  time.Time              `msgpack:"before"`
+		Nil     *inner                 `msgpack:"nil"`
+		Ptr     *inner                 `msgpack:"ptr"`
+		Skipped string                 `msgpack:"-"`
+		Empty   string                 `msgpack:"empty,omitempty"`
+		List    []interface{}          `msgpack:"list"`
+		Map     map[int]string         `msgpack:"map"`
+		Any     map[string]interface{} `msgpack:"any"`
+		Default string
+	}
+	long := strings.Repeat("x", 70000)
+	many := make([]int, 20)
+	v := doc{
+		Base: Base{ID: 7}, Int: -100, Uint: 60000, Float: 0.25, Inf: math.Inf(1), Str: long,
+		Bytes: bytes.Repeat([]byte{7}, 300), Time: time.Unix(1700000000, 123), Before: time.Unix(-1, 0),
+		Ptr: &inner{Name: "p"}, Skipped: "x", List: []interface{}{1, "a", nil, many},
+		Map: map[int]string{1: "one", 2: "two"}, Any: map[string]interface{}{"k": []string{"v"}}, Default: "d",
+	}
+	data, err := Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manyWant := make([]interface{}, 20)
+	for i := range manyWant {
+		manyWant[i] = int64(0)
+	}
+	want := map[interface{}]interface{}{
+		"id": int64(7), "int": int64(-100), "uint": int64(60000), "float": float32(0.25), "inf": math.Inf(1),
+		"str": long, "bytes": bytes.Repeat([]byte{7}, 300), "time": time.Unix(1700000000, 123), "before": time.Unix(-1, 0),
+		"nil": nil, "ptr": map[interface{}]interface{}{"name": "p"},
+		"list": []interface{}{int64(1), "a", nil, manyWant},
+		"map":  map[interface{}]interface{}{int64(1): "one", int64(2): "two"},
+		"any":  map[interface{}]interface{}{"k": []interface{}{"v"}}, "Default": "d",
+	}
+	if got := decode(t, data); !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip = %#v\nwant %#v", got, want)
+	}
+
+	if _, err := Marshal(map[string]interface{}{"a": make(chan int)}); err == nil {
+		t.Error("unsupported type encoded")
+	}
+}
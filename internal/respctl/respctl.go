@@ -0,0 +1,42 @@
+// Package respctl flushes and hijacks response writers through the Unwrap
+// methods of their wrappers, like http.ResponseController of Go 1.20.
+package respctl
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+)
+
+type unwrapper interface {
+	Unwrap() http.ResponseWriter
+}
+
+// Flush flushes the first writer of the chain implementing http.Flusher.
+func Flush(w http.ResponseWriter) error {
+	for {
+		switch t := w.(type) {
+		case http.Flusher:
+			t.Flush()
+			return nil
+		case unwrapper:
+			w = t.Unwrap()
+		default:
+			return http.ErrNotSupported
+		}
+	}
+}
+
+// Hijack hijacks the first writer of the chain implementing http.Hijacker.
+func Hijack(w http.ResponseWriter) (net.Conn, *bufio.ReadWriter, error) {
+	for {
+		switch t := w.(type) {
+		case http.Hijacker:
+			return t.Hijack()
+		case unwrapper:
+			w = t.Unwrap()
+		default:
+			return nil, nil, http.ErrNotSupported
+		}
+	}
+}
@@ -0,0 +1,352 @@
+// Package toml implements a minimal TOML encoder for response rendering.
+package toml
+
+import (
+	"bytes"
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Marshal returns the TOML encoding of v, which must be a map or a struct.
+// Struct fields are named by their `toml` tag, then their `json` tag, then the
+// field name. Nil values are left out since TOML has no null.
+func Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type Encoder struct {
+	w   io.Writer
+	buf bytes.Buffer
+}
+
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Encode writes the TOML encoding of v to the underlying writer.
+func (e *Encoder) Encode(v interface{}) error {
+	e.buf.Reset()
+	rv := indirect(reflect.ValueOf(v))
+	if !isTable(rv) {
+		return errors.New("toml: top level value must be a map or a struct")
+	}
+	if err := e.table(rv, nil); err != nil {
+		return err
+	}
+	_, err := e.w.Write(e.buf.Bytes())
+	return err
+}
+
+type entry struct {
+	key string
+	val reflect.Value
+}
+
+var (
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	timeType          = reflect.TypeOf(time.Time{})
+)
+
+// table writes the key/value pairs of v, followed by its sub tables and arrays
+// of tables, which TOML requires to come last.
+func (e *Encoder) table(v reflect.Value, path []string) error {
+	entries, err := mapEntries(v)
+	if err != nil {
+		return err
+	}
+	var tables, arrays []entry
+	for _, en := range entries {
+		switch {
+		case !en.val.IsValid():
+		case isTable(en.val):
+			tables = append(tables, en)
+		case isArrayOfTables(en.val):
+			arrays = append(arrays, en)
+		default:
+			s, err := inline(en.val)
+			if err != nil {
+				return err
+			}
+			e.buf.WriteString(quoteKey(en.key))
+			e.buf.WriteString(" = ")
+			e.buf.WriteString(s)
+			e.buf.WriteByte('\n')
+		}
+	}
+	for _, en := range tables {
+		sub := appendPath(path, en.key)
+		e.header("[", sub, "]")
+		if err := e.table(en.val, sub); err != nil {
+			return err
+		}
+	}
+	for _, en := range arrays {
+		sub := appendPath(path, en.key)
+		for i := 0; i < en.val.Len(); i++ {
+			e.header("[[", sub, "]]")
+			if err := e.table(indirect(en.val.Index(i)), sub); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (e *Encoder) header(open string, path []string, close string) {
+	if e.buf.Len() > 0 {
+		e.buf.WriteByte('\n')
+	}
+	e.buf.WriteString(open)
+	for i, key := range path {
+		if i > 0 {
+			e.buf.WriteByte('.')
+		}
+		e.buf.WriteString(quoteKey(key))
+	}
+	e.buf.WriteString(close)
+	e.buf.WriteByte('\n')
+}
+
+func appendPath(path []string, key string) []string {
+	return append(path[:len(path):len(path)], key)
+}
+
+// inline returns v as a scalar, an inline array or an inline table.
+func inline(v reflect.Value) (string, error) {
+	if s, ok, err := scalar(v); ok || err != nil {
+		return s, err
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		items := make([]string, v.Len())
+		for i := range items {
+			item := indirect(v.Index(i))
+			if !item.IsValid() {
+				return "", errors.New("toml: nil value in array")
+			}
+			s, err := inline(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = s
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case reflect.Map, reflect.Struct:
+		entries, err := mapEntries(v)
+		if err != nil {
+			return "", err
+		}
+		items := make([]string, 0, len(entries))
+		for _, en := range entries {
+			if !en.val.IsValid() {
+				continue
+			}
+			s, err := inline(en.val)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, quoteKey(en.key)+" = "+s)
+		}
+		if len(items) == 0 {
+			return "{}", nil
+		}
+		return "{ " + strings.Join(items, ", ") + " }", nil
+	}
+	return "", fmt.Errorf("toml: unsupported type %s", v.Type())
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		if v.Type().Implements(textMarshalerType) && !(v.Kind() == reflect.Ptr && v.Type().Elem() == timeType) {
+			return v
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func isTable(v reflect.Value) bool {
+	if !v.IsValid() || v.Type() == timeType || v.Type().Implements(textMarshalerType) {
+		return false
+	}
+	return v.Kind() == reflect.Map || v.Kind() == reflect.Struct
+}
+
+func isArrayOfTables(v reflect.Value) bool {
+	if (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) || v.Len() == 0 {
+		return false
+	}
+	for i := 0; i < v.Len(); i++ {
+		if !isTable(indirect(v.Index(i))) {
+			return false
+		}
+	}
+	return true
+}
+
+func scalar(v reflect.Value) (string, bool, error) {
+	if v.Type() == timeType {
+		return v.Interface().(time.Time).Format(time.RFC3339Nano), true, nil
+	}
+	if v.Type().Implements(textMarshalerType) {
+		b, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return "", false, err
+		}
+		return quote(string(b)), true, nil
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Uint() > math.MaxInt64 {
+			return "", false, fmt.Errorf("toml: %d overflows a TOML integer", v.Uint())
+		}
+		return strconv.FormatUint(v.Uint(), 10), true, nil
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		switch {
+		case math.IsNaN(f):
+			return "nan", true, nil
+		case math.IsInf(f, 1):
+			return "inf", true, nil
+		case math.IsInf(f, -1):
+			return "-inf", true, nil
+		}
+		s := strconv.FormatFloat(f, 'g', -1, v.Type().Bits())
+		if !strings.ContainsAny(s, ".eE") {
+			s += ".0"
+		}
+		return s, true, nil
+	case reflect.String:
+		return quote(v.String()), true, nil
+	}
+	return "", false, nil
+}
+
+func mapEntries(v reflect.Value) ([]entry, error) {
+	if v.Kind() == reflect.Map {
+		entries := make([]entry, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			entries = append(entries, entry{key: fmt.Sprint(iter.Key().Interface()), val: indirect(iter.Value())})
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+		return entries, nil
+	}
+	var entries []entry
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		name, omitempty, skip := fieldName(field)
+		if skip {
+			continue
+		}
+		fv := v.Field(i)
+		if field.Anonymous && name == "" {
+			if iv := indirect(fv); iv.Kind() == reflect.Struct {
+				embedded, err := mapEntries(iv)
+				if err != nil {
+					return nil, err
+				}
+				entries = append(entries, embedded...)
+			}
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if omitempty && fv.IsZero() {
+			continue
+		}
+		entries = append(entries, entry{key: name, val: indirect(fv)})
+	}
+	return entries, nil
+}
+
+func fieldName(field reflect.StructField) (name string, omitempty bool, skip bool) {
+	tag, ok := field.Tag.Lookup("toml")
+	if !ok {
+		tag = field.Tag.Get("json")
+	}
+	if tag == "-" {
+		return "", false, true
+	}
+	parts := strings.Split(tag, ",")
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitempty = true
+		}
+	}
+	return parts[0], omitempty, false
+}
+
+// quoteKey returns key bare when it only holds A-Za-z0-9_- and quoted otherwise.
+func quoteKey(key string) string {
+	if key == "" {
+		return `""`
+	}
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_' || c == '-') {
+			return quote(key)
+		}
+	}
+	return key
+}
+
+// quote returns s as a TOML basic string.
+func quote(s string) string {
+	var b strings.Builder
+	b.Grow(len(s) + 2)
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,425 @@
+package toml
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// parser decodes the subset of TOML written by the encoder: key/value pairs,
+// [table] and [[array]] headers, basic strings, integers, floats, booleans,
+// offset date-times, arrays and inline tables. It rejects duplicate keys.
+type parser struct {
+	s   string
+	pos int
+}
+
+func decode(t *testing.T, data string) map[string]interface{} {
+	t.Helper()
+	p := &parser{s: data}
+	m, err := p.document()
+	if err != nil {
+		t.Fatalf("decode %q: %v", data, err)
+	}
+	return m
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) document() (map[string]interface{}, error) {
+	root := map[string]interface{}{}
+	current := root
+	defined := map[string]bool{}
+	for {
+		p.skipSpace()
+		if p.pos == len(p.s) {
+			return root, nil
+		}
+		switch {
+		case strings.HasPrefix(p.s[p.pos:], "[["):
+			p.pos += 2
+			path, err := p.keys()
+			if err != nil {
+				return nil, err
+			}
+			if !p.consume("]]") {
+				return nil, p.errorf("unterminated array header")
+			}
+			parent, err := walk(root, path[:len(path)-1])
+			if err != nil {
+				return nil, err
+			}
+			last := path[len(path)-1]
+			arr, _ := parent[last].([]interface{})
+			if _, exists := parent[last]; exists && arr == nil {
+				return nil, p.errorf("%v is not an array of tables", path)
+			}
+			current = map[string]interface{}{}
+			parent[last] = append(arr, current)
+		case p.consume("["):
+			path, err := p.keys()
+			if err != nil {
+				return nil, err
+			}
+			if !p.consume("]") {
+				return nil, p.errorf("unterminated table header")
+			}
+			// array of tables are indexed so that [a.b] after each [[a]] is new
+			id := fmt.Sprintf("%p%q", root, path)
+			if parent, err := walk(root, path[:len(path)-1]); err == nil {
+				id = fmt.Sprintf("%p%q", parent, path[len(path)-1])
+			}
+			if defined[id] {
+				return nil, p.errorf("table %v defined twice", path)
+			}
+			defined[id] = true
+			if current, err = walk(root, path); err != nil {
+				return nil, err
+			}
+		default:
+			if err := p.keyValue(current); err != nil {
+				return nil, err
+			}
+		}
+		p.skipBlank()
+		if p.pos < len(p.s) && !p.consume("\n") {
+			return nil, p.errorf("expected a new line")
+		}
+	}
+}
+
+// walk returns the table at path, creating it when needed and descending
+// into the last element of arrays of tables.
+func walk(m map[string]interface{}, path []string) (map[string]interface{}, error) {
+	for _, key := range path {
+		switch v := m[key].(type) {
+		case nil:
+			sub := map[string]interface{}{}
+			m[key] = sub
+			m = sub
+		case map[string]interface{}:
+			m = v
+		case []interface{}:
+			sub, ok := v[len(v)-1].(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%q is not a table", key)
+			}
+			m = sub
+		default:
+			return nil, fmt.Errorf("%q is not a table", key)
+		}
+	}
+	return m, nil
+}
+
+func (p *parser) keyValue(m map[string]interface{}) error {
+	key, err := p.key()
+	if err != nil {
+		return err
+	}
+	p.skipBlank()
+	if !p.consume("=") {
+		return p.errorf("expected = after %q", key)
+	}
+	p.skipBlank()
+	v, err := p.value()
+	if err != nil {
+		return err
+	}
+	if _, ok := m[key]; ok {
+		return p.errorf("duplicate key %q", key)
+	}
+	m[key] = v
+	return nil
+}
+
+func (p *parser) keys() ([]string, error) {
+	var path []string
+	for {
+		p.skipBlank()
+		key, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+		path = append(path, key)
+		p.skipBlank()
+		if !p.consume(".") {
+			return path, nil
+		}
+	}
+}
+
+func (p *parser) key() (string, error) {
+	if strings.HasPrefix(p.s[p.pos:], `"`) {
+		return p.basicString()
+	}
+	start := p.pos
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_' || c == '-') {
+			break
+		}
+		p.pos++
+	}
+	if p.pos == start {
+		return "", p.errorf("expected a key")
+	}
+	return p.s[start:p.pos], nil
+}
+
+func (p *parser) value() (interface{}, error) {
+	switch {
+	case strings.HasPrefix(p.s[p.pos:], `"`):
+		return p.basicString()
+	case p.consume("["):
+		arr := []interface{}{}
+		for {
+			p.skipBlank()
+			if p.consume("]") {
+				return arr, nil
+			}
+			if len(arr) > 0 {
+				if !p.consume(",") {
+					return nil, p.errorf("expected , in array")
+				}
+				p.skipBlank()
+			}
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+	case p.consume("{"):
+		m := map[string]interface{}{}
+		for first := true; ; first = false {
+			p.skipBlank()
+			if p.consume("}") {
+				return m, nil
+			}
+			if !first {
+				if !p.consume(",") {
+					return nil, p.errorf("expected , in inline table")
+				}
+				p.skipBlank()
+			}
+			if err := p.keyValue(m); err != nil {
+				return nil, err
+			}
+		}
+	}
+	start := p.pos
+	for p.pos < len(p.s) && !strings.ContainsRune(" \t\n,]}", rune(p.s[p.pos])) {
+		p.pos++
+	}
+	tok := p.s[start:p.pos]
+	switch tok {
+	case "true", "false":
+		return tok == "true", nil
+	case "inf", "+inf":
+		return math.Inf(1), nil
+	case "-inf":
+		return math.Inf(-1), nil
+	case "nan", "+nan", "-nan":
+		return math.NaN(), nil
+	}
+	if i, err := strconv.ParseInt(tok, 10, 64); err == nil && !strings.HasPrefix(strings.TrimLeft(tok, "+-"), "0") || tok == "0" {
+		return i, nil
+	}
+	if strings.ContainsAny(tok, ".eE") && !strings.ContainsAny(tok, "xXnNiI_:T") {
+		if f, err := strconv.ParseFloat(tok, 64); err == nil {
+			return f, nil
+		}
+	}
+	if d, err := time.Parse(time.RFC3339Nano, tok); err == nil {
+		return d, nil
+	}
+	return nil, p.errorf("invalid value %q", tok)
+}
+
+func (p *parser) basicString() (string, error) {
+	p.pos++
+	var b strings.Builder
+	for p.pos < len(p.s) {
+		r, size := utf8.DecodeRuneInString(p.s[p.pos:])
+		p.pos += size
+		switch {
+		case r == '"':
+			return b.String(), nil
+		case r == '\\':
+			if p.pos == len(p.s) {
+				return "", p.errorf("unterminated escape")
+			}
+			c := p.s[p.pos]
+			p.pos++
+			switch c {
+			case 'b', 't', 'n', 'f', 'r', '"', '\\':
+				b.WriteByte(map[byte]byte{'b': '\b', 't': '\t', 'n': '\n', 'f': '\f', 'r': '\r', '"': '"', '\\': '\\'}[c])
+			case 'u', 'U':
+				n := map[byte]int{'u': 4, 'U': 8}[c]
+				if p.pos+n > len(p.s) {
+					return "", p.errorf("short unicode escape")
+				}
+				code, err := strconv.ParseUint(p.s[p.pos:p.pos+n], 16, 32)
+				if err != nil {
+					return "", p.errorf("invalid unicode escape")
+				}
+				p.pos += n
+				b.WriteRune(rune(code))
+			default:
+				return "", p.errorf("invalid escape \\%c", c)
+			}
+		case r == utf8.RuneError && size == 1:
+			return "", p.errorf("invalid UTF-8")
+		case r < 0x20 && r != '\t' || r == 0x7f:
+			return "", p.errorf("control character %U in string", r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *parser) consume(s string) bool {
+	if strings.HasPrefix(p.s[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+func (p *parser) skipBlank() {
+	for p.pos < len(p.s) && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.s) && strings.ContainsRune(" \t\r\n", rune(p.s[p.pos])) {
+		p.pos++
+	}
+}
+
+func TestMarshal_RoundTrip(t *testing.T) {
+	strs := []string{
+		"", "plain", "quote\"s", `back\slash`, "multi\nline\r\n", "tab\t", "\b\f", "ctrl\x01\x1f\x7f",
+		"ünïcode", "emoji 🙂", "1", "true", "inf", "2001-12-14T21:59:43Z", "[not] {a: table}", "# comment",
+	}
+	for _, s := range strs {
+		t.Run(strconv.Quote(s), func(t *testing.T) {
+			data, err := Marshal(map[string]interface{}{s: s, "list": []string{s}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := map[string]interface{}{s: s, "list": []interface{}{s}}
+			if got := decode(t, string(data)); !reflect.DeepEqual(got, want) {
+				t.Errorf("round trip of\n%s= %#v, want %#v", data, got, want)
+			}
+		})
+	}
+
+	type point struct {
+		X int `toml:"x"`
+		Y int `json:"y"`
+	}
+	type Base struct {
+		ID int `toml:"id"`
+	}
+	type doc struct {
+		Base
+		Title   string                 `toml:"title"`
+		Int     int                    `toml:"int"`
+		Uint    uint32                 `toml:"uint"`
+		Float   float64                `toml:"float"`
+		Whole   float64                `toml:"whole"`
+		Small   float32                `toml:"small"`
+		Big     float64                `toml:"big"`
+		Inf     float64                `toml:"inf"`
+		Bool    bool                   `toml:"bool"`
+		Time    time.Time              `toml:"time"`
+		IP      net.IP                 `toml:"ip"`
+		Nil     *point                 `toml:"nil"`
+		Skipped string                 `toml:"-"`
+		Empty   string                 `toml:"empty,omitempty"`
+		Mixed   []interface{}          `toml:"mixed"`
+		Inline  []map[string]int       `toml:"inline,omitempty"`
+		Point   point                  `toml:"point"`
+		Points  []point                `toml:"points"`
+		Nested  map[string]interface{} `toml:"nested"`
+		NoList  []int                  `toml:"nolist"`
+		Default string
+	}
+	at := time.Date(2024, 1, 2, 3, 4, 5, 600, time.FixedZone("", -7*3600))
+	v := doc{
+		Base: Base{ID: 7}, Title: "t", Int: -42, Uint: 200, Float: 1.5, Whole: 3, Small: 0.1, Big: 1e21,
+		Inf: math.Inf(-1), Bool: true, Time: at, IP: net.IPv4(10, 0, 0, 1), Skipped: "x",
+		Mixed:  []interface{}{1, "a", 2.5, []int{}, map[string]interface{}{"k": "v"}},
+		Point:  point{1, 2},
+		Points: []point{{3, 4}, {5, 6}},
+		Nested: map[string]interface{}{
+			"a.b":    map[string]interface{}{"c": 1, "rows": []map[string]interface{}{{"r": 1}, {"r": 2, "sub": map[string]int{"s": 3}}}},
+			"scalar": "s",
+			"empty":  map[string]int{},
+		},
+		Default: "d",
+	}
+	data, err := Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"id": int64(7), "title": "t", "int": int64(-42), "uint": int64(200), "float": 1.5, "whole": 3.0,
+		"small": 0.1, "big": 1e21, "inf": math.Inf(-1), "bool": true, "time": at, "ip": "10.0.0.1",
+		"mixed":   []interface{}{int64(1), "a", 2.5, []interface{}{}, map[string]interface{}{"k": "v"}},
+		"point":   map[string]interface{}{"x": int64(1), "y": int64(2)},
+		"points":  []interface{}{map[string]interface{}{"x": int64(3), "y": int64(4)}, map[string]interface{}{"x": int64(5), "y": int64(6)}},
+		"nolist":  []interface{}{},
+		"Default": "d",
+		"nested": map[string]interface{}{
+			"a.b": map[string]interface{}{"c": int64(1), "rows": []interface{}{
+				map[string]interface{}{"r": int64(1)},
+				map[string]interface{}{"r": int64(2), "sub": map[string]interface{}{"s": int64(3)}},
+			}},
+			"scalar": "s",
+			"empty":  map[string]interface{}{},
+		},
+	}
+	got := decode(t, string(data))
+	if gt, ok := got["time"].(time.Time); ok && gt.Equal(at) {
+		got["time"] = at
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip of\n%s= %#v\nwant %#v", data, got, want)
+	}
+
+	data, _ = Marshal(map[string]float64{"nan": math.NaN()})
+	if f, ok := decode(t, string(data))["nan"].(float64); !ok || !math.IsNaN(f) {
+		t.Errorf("NaN encoded as %q", data)
+	}
+}
+
+func TestMarshal_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{"top level scalar", 1},
+		{"top level slice", []int{1}},
+		{"nil in array", map[string]interface{}{"a": []interface{}{1, nil}}},
+		{"uint overflow", map[string]uint64{"a": math.MaxUint64}},
+		{"unsupported", map[string]interface{}{"a": make(chan int)}},
+	}
+	for _, tt := range tests {
+		if _, err := Marshal(tt.v); err == nil {
+			t.Errorf("%s: no error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+// Package yaml implements a minimal block style YAML encoder for response rendering.
+package yaml
+
+import (
+	"bytes"
+	"encoding"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Marshal returns the YAML encoding of v. Struct fields are named by their
+// `yaml` tag, then their `json` tag, then the lower cased field name.
+func Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type Encoder struct {
+	w   io.Writer
+	buf bytes.Buffer
+}
+
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Encode writes the YAML encoding of v to the underlying writer.
+func (e *Encoder) Encode(v interface{}) error {
+	e.buf.Reset()
+	if err := e.value(reflect.ValueOf(v), 0); err != nil {
+		return err
+	}
+	_, err := e.w.Write(e.buf.Bytes())
+	return err
+}
+
+type entry struct {
+	key string
+	val reflect.Value
+}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// value writes a top level value or the value following "key:" or "- ".
+func (e *Encoder) value(v reflect.Value, indent int) error {
+	v = indirect(v)
+	if s, ok, err := scalar(v); err != nil {
+		return err
+	} else if ok {
+		e.buf.WriteString(s)
+		e.buf.WriteByte('\n')
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Map, reflect.Struct:
+		entries, err := mapEntries(v)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			e.buf.WriteString("{}\n")
+			return nil
+		}
+		return e.mapping(entries, indent)
+	case reflect.Slice, reflect.Array:
+		if v.Len() == 0 {
+			e.buf.WriteString("[]\n")
+			return nil
+		}
+		return e.sequence(v, indent)
+	}
+	return fmt.Errorf("yaml: unsupported type %s", v.Type())
+}
+
+func (e *Encoder) mapping(entries []entry, indent int) error {
+	for _, en := range entries {
+		e.writeIndent(indent)
+		e.buf.WriteString(quote(en.key))
+		e.buf.WriteByte(':')
+		if err := e.child(en.val, indent+2); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *Encoder) sequence(v reflect.Value, indent int) error {
+	for i := 0; i < v.Len(); i++ {
+		e.writeIndent(indent)
+		e.buf.WriteByte('-')
+		if err := e.child(v.Index(i), indent+2); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// child writes a nested value, inline when it is a scalar or an empty collection.
+func (e *Encoder) child(v reflect.Value, indent int) error {
+	v = indirect(v)
+	if _, ok, _ := scalar(v); ok || isEmptyCollection(v) {
+		e.buf.WriteByte(' ')
+		return e.value(v, indent)
+	}
+	e.buf.WriteByte('\n')
+	return e.value(v, indent)
+}
+
+func (e *Encoder) writeIndent(n int) {
+	for i := 0; i < n; i++ {
+		e.buf.WriteByte(' ')
+	}
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		if v.Type().Implements(textMarshalerType) {
+			return v
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func isEmptyCollection(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array:
+		return v.Len() == 0
+	case reflect.Struct:
+		entries, err := mapEntries(v)
+		return err == nil && len(entries) == 0
+	}
+	return false
+}
+
+func scalar(v reflect.Value) (string, bool, error) {
+	if !v.IsValid() {
+		return "null", true, nil
+	}
+	if v.Type().Implements(textMarshalerType) {
+		b, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return "", false, err
+		}
+		return quote(string(b)), true, nil
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), true, nil
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		switch {
+		case math.IsNaN(f):
+			return ".nan", true, nil
+		case math.IsInf(f, 1):
+			return ".inf", true, nil
+		case math.IsInf(f, -1):
+			return "-.inf", true, nil
+		}
+		return strconv.FormatFloat(f, 'g', -1, v.Type().Bits()), true, nil
+	case reflect.String:
+		return quote(v.String()), true, nil
+	}
+	return "", false, nil
+}
+
+func mapEntries(v reflect.Value) ([]entry, error) {
+	if v.Kind() == reflect.Map {
+		entries := make([]entry, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			entries = append(entries, entry{key: fmt.Sprint(iter.Key().Interface()), val: iter.Value()})
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+		return entries, nil
+	}
+	var entries []entry
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		name, omitempty, skip := fieldName(field)
+		if skip {
+			continue
+		}
+		fv := v.Field(i)
+		if field.Anonymous && name == "" {
+			if iv := indirect(fv); iv.Kind() == reflect.Struct {
+				embedded, err := mapEntries(iv)
+				if err != nil {
+					return nil, err
+				}
+				entries = append(entries, embedded...)
+			}
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		if omitempty && fv.IsZero() {
+			continue
+		}
+		entries = append(entries, entry{key: name, val: fv})
+	}
+	return entries, nil
+}
+
+func fieldName(field reflect.StructField) (name string, omitempty bool, skip bool) {
+	tag, ok := field.Tag.Lookup("yaml")
+	if !ok {
+		tag = field.Tag.Get("json")
+	}
+	if tag == "-" {
+		return "", false, true
+	}
+	parts := strings.Split(tag, ",")
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitempty = true
+		}
+	}
+	return parts[0], omitempty, false
+}
+
+// quote returns s as a plain scalar when that is unambiguous, double quoted otherwise.
+func quote(s string) string {
+	if needsQuote(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// needsQuote reports whether s would not read back as the same string when
+// plain: YAML 1.1 and 1.2 resolve plain scalars that look like null, booleans,
+// numbers (also hex, octal, sexagesimal, .inf and .nan) or timestamps to those
+// types, so every scalar starting with a digit, '.' or '+' is quoted.
+func needsQuote(s string) bool {
+	if s == "" || s != strings.TrimSpace(s) {
+		return true
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "y", "n", "null", "~", "<<", "=":
+		return true
+	}
+	if c := s[0]; '0' <= c && c <= '9' || c == '.' || c == '+' {
+		return true
+	}
+	if strings.ContainsRune("-?:,[]{}#&*!|>'\"%@`", rune(s[0])) {
+		return true
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return true
+	}
+	for _, r := range s {
+		if r == '\n' || r == '\t' || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,249 @@
+package yaml
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// The decoder below reads the block style subset written by the encoder and
+// resolves plain scalars with the implicit types of both YAML 1.1
+// (yaml.org/type) and the YAML 1.2 core schema, as real decoders do.
+
+var (
+	yamlNull  = regexp.MustCompile(`^(~|null|Null|NULL)$`)
+	yamlBool  = regexp.MustCompile(`^(y|Y|yes|Yes|YES|n|N|no|No|NO|true|True|TRUE|false|False|FALSE|on|On|ON|off|Off|OFF)$`)
+	yamlInt   = regexp.MustCompile(`^[-+]?(0|[1-9][0-9]*)$`)
+	yamlFloat = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?$`)
+	yamlInf   = regexp.MustCompile(`^[-+]?\.(inf|Inf|INF)$`)
+	yamlNaN   = regexp.MustCompile(`^\.(nan|NaN|NAN)$`)
+	// other implicit types, resolved to something that is not a string
+	yamlOther = []*regexp.Regexp{
+		regexp.MustCompile(`^[-+]?0b[0-1_]+$`),
+		regexp.MustCompile(`^[-+]?0[0-7_]+$`),
+		regexp.MustCompile(`^0o[0-7]+$`),
+		regexp.MustCompile(`^[-+]?(0|[1-9][0-9_]*)$`),
+		regexp.MustCompile(`^[-+]?0x[0-9a-fA-F_]+$`),
+		regexp.MustCompile(`^[-+]?[1-9][0-9_]*(:[0-5]?[0-9])+$`),
+		regexp.MustCompile(`^[-+]?([0-9][0-9_]*)?\.[0-9.]*([eE][-+][0-9]+)?$`),
+		regexp.MustCompile(`^[-+]?[0-9][0-9_]*(:[0-5]?[0-9])+\.[0-9_]*$`),
+		regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}$`),
+		regexp.MustCompile(`^[0-9]{4}-[0-9]{1,2}-[0-9]{1,2}([Tt]|[ \t]+)[0-9]{1,2}:[0-9]{2}:[0-9]{2}(\.[0-9]*)?(([ \t]*)Z|[-+][0-9]{1,2}(:[0-9]{2})?)?$`),
+		regexp.MustCompile(`^(<<|=)$`),
+	}
+)
+
+type otherType string
+
+func resolve(s string) interface{} {
+	switch {
+	case yamlNull.MatchString(s):
+		return nil
+	case yamlBool.MatchString(s):
+		switch strings.ToLower(s) {
+		case "y", "yes", "true", "on":
+			return true
+		}
+		return false
+	case yamlInt.MatchString(s):
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return otherType(s)
+		}
+		return i
+	case yamlFloat.MatchString(s):
+		f, _ := strconv.ParseFloat(s, 64)
+		return f
+	case yamlInf.MatchString(s):
+		return math.Inf(map[bool]int{true: -1, false: 1}[s[0] == '-'])
+	case yamlNaN.MatchString(s):
+		return math.NaN()
+	}
+	for _, re := range yamlOther {
+		if re.MatchString(s) {
+			return otherType(s)
+		}
+	}
+	return s
+}
+
+type line struct {
+	indent int
+	text   string
+}
+
+func decode(t *testing.T, data string) interface{} {
+	t.Helper()
+	var lines []line
+	for _, l := range strings.Split(strings.TrimSuffix(data, "\n"), "\n") {
+		text := strings.TrimLeft(l, " ")
+		lines = append(lines, line{indent: len(l) - len(text), text: text})
+	}
+	v, next, err := parseNode(lines, 0, 0)
+	if err == nil && next != len(lines) {
+		err = fmt.Errorf("trailing line %d: %q", next, lines[next].text)
+	}
+	if err != nil {
+		t.Fatalf("decode %q: %v", data, err)
+	}
+	return v
+}
+
+func parseNode(lines []line, i, indent int) (interface{}, int, error) {
+	if i >= len(lines) || lines[i].indent != indent {
+		return nil, i, fmt.Errorf("line %d: expected indentation %d", i, indent)
+	}
+	text := lines[i].text
+	if text == "-" || strings.HasPrefix(text, "- ") {
+		seq := []interface{}{}
+		for i < len(lines) && lines[i].indent == indent && (lines[i].text == "-" || strings.HasPrefix(lines[i].text, "- ")) {
+			item, next, err := parseChild(lines, i, indent, strings.TrimPrefix(lines[i].text, "-"))
+			if err != nil {
+				return nil, i, err
+			}
+			seq = append(seq, item)
+			i = next
+		}
+		return seq, i, nil
+	}
+	if _, rest, ok := parseKey(text); ok && (rest == "" || rest[0] == ' ') {
+		m := map[string]interface{}{}
+		for i < len(lines) && lines[i].indent == indent {
+			key, rest, ok := parseKey(lines[i].text)
+			if !ok {
+				return nil, i, fmt.Errorf("line %d: expected a key: %q", i, lines[i].text)
+			}
+			item, next, err := parseChild(lines, i, indent, rest)
+			if err != nil {
+				return nil, i, err
+			}
+			m[key] = item
+			i = next
+		}
+		return m, i, nil
+	}
+	v, err := parseScalar(text)
+	return v, i + 1, err
+}
+
+// parseChild returns the value after "key:" or "-", inline or on the next lines.
+func parseChild(lines []line, i, indent int, rest string) (interface{}, int, error) {
+	if rest == "" {
+		return parseNode(lines, i+1, indent+2)
+	}
+	v, err := parseScalar(strings.TrimPrefix(rest, " "))
+	return v, i + 1, err
+}
+
+func parseKey(text string) (key, rest string, ok bool) {
+	if strings.HasPrefix(text, `"`) {
+		q, err := strconv.QuotedPrefix(text)
+		if err != nil || !strings.HasPrefix(text[len(q):], ":") {
+			return "", "", false
+		}
+		key, _ = strconv.Unquote(q)
+		return key, text[len(q)+1:], true
+	}
+	if i := strings.Index(text, ": "); i >= 0 {
+		return text[:i], text[i+1:], true
+	}
+	if strings.HasSuffix(text, ":") {
+		return text[:len(text)-1], "", true
+	}
+	return "", "", false
+}
+
+func parseScalar(s string) (interface{}, error) {
+	switch {
+	case s == "{}":
+		return map[string]interface{}{}, nil
+	case s == "[]":
+		return []interface{}{}, nil
+	case strings.HasPrefix(s, `"`):
+		return strconv.Unquote(s)
+	}
+	return resolve(s), nil
+}
+
+func TestMarshal_RoundTrip(t *testing.T) {
+	strs := []string{
+		"", " padded", "plain", "with space", "a: b", "a:b", "ends:", "#comment", "x #y", "-", "- item",
+		"true", "False", "yes", "No", "on", "OFF", "y", "N", "null", "Null", "~", "<<", "=",
+		"1", "-1", "+1", "1.5", "1e3", "1_000", "0x1F", "0o17", "017", "0b101", "1:30", "190:20:30.15",
+		".inf", "-.Inf", "+.INF", ".nan", ".NaN", ".5", "1.",
+		"2001-12-14", "2001-12-14t21:59:43.10-05:00", "2001-12-14 21:59:43.10 -5",
+		"multi\nline", "tab\there", "quote\"s", `back\slash`, "ünïcode", "emoji 🙂", "ctrl\x01",
+	}
+	for _, s := range strs {
+		t.Run(strconv.Quote(s), func(t *testing.T) {
+			data, err := Marshal(map[string]interface{}{s: s, "list": []string{s}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := map[string]interface{}{s: s, "list": []interface{}{s}}
+			if got := decode(t, string(data)); !reflect.DeepEqual(got, want) {
+				t.Errorf("round trip of\n%s= %#v, want %#v", data, got, want)
+			}
+		})
+	}
+
+	type inner struct {
+		Name string   `yaml:"name"`
+		Tags []string `json:"tags"`
+	}
+	type doc struct {
+		Int     int                    `yaml:"int"`
+		Uint    uint8                  `yaml:"uint"`
+		Float   float64                `yaml:"float"`
+		Big     float64                `yaml:"big"`
+		Inf     float64                `yaml:"inf"`
+		Bool    bool                   `yaml:"bool"`
+		Nil     *inner                 `yaml:"nil"`
+		Skipped string                 `yaml:"-"`
+		Empty   string                 `yaml:"empty,omitempty"`
+		Inner   inner                  `yaml:"inner"`
+		List    []inner                `yaml:"list"`
+		Nested  [][]int                `yaml:"nested"`
+		Map     map[string]interface{} `yaml:"map"`
+		NoMap   map[string]int         `yaml:"nomap"`
+		NoList  []int                  `yaml:"nolist"`
+		Default string
+	}
+	v := doc{
+		Int: -42, Uint: 200, Float: 1.5, Big: 1e21, Inf: math.Inf(-1), Bool: true,
+		Skipped: "x", Inner: inner{Name: "a", Tags: []string{"x", "2"}},
+		List:   []inner{{Name: "b"}, {Name: "c", Tags: []string{}}},
+		Nested: [][]int{{1, 2}, {}},
+		Map:    map[string]interface{}{"k": "v", "n": nil},
+		NoMap:  map[string]int{}, Default: "d",
+	}
+	data, err := Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"int": int64(-42), "uint": int64(200), "float": 1.5, "big": 1e21, "inf": math.Inf(-1), "bool": true, "nil": nil,
+		"inner": map[string]interface{}{"name": "a", "tags": []interface{}{"x", "2"}},
+		"list": []interface{}{
+			map[string]interface{}{"name": "b", "tags": []interface{}{}},
+			map[string]interface{}{"name": "c", "tags": []interface{}{}},
+		},
+		"nested":  []interface{}{[]interface{}{int64(1), int64(2)}, []interface{}{}},
+		"map":     map[string]interface{}{"k": "v", "n": nil},
+		"nomap":   map[string]interface{}{},
+		"nolist":  []interface{}{},
+		"default": "d",
+	}
+	if got := decode(t, string(data)); !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip of\n%s= %#v\nwant %#v", data, got, want)
+	}
+
+	data, _ = Marshal(math.NaN())
+	if f, ok := decode(t, string(data)).(float64); !ok || !math.IsNaN(f) {
+		t.Errorf("NaN encoded as %q", data)
+	}
+}
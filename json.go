@@ -0,0 +1,159 @@
+package bytego
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/gostack-labs/bytego/internal/bytebufferpool"
+)
+
+const defaultSecureJSONPrefix = "while(1);"
+
+// ErrInvalidCallback is returned by Ctx.JSONP when the callback is not a JavaScript identifier.
+var ErrInvalidCallback = NewHTTPError(http.StatusBadRequest, "invalid JSONP callback")
+
+// JSONCodec encodes and decodes JSON for Ctx.JSON and Ctx.Bind, so that a faster
+// implementation can replace encoding/json.
+type JSONCodec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+	NewEncoder(w io.Writer) JSONEncoder
+	NewDecoder(r io.Reader) JSONDecoder
+}
+
+type JSONEncoder interface {
+	Encode(v interface{}) error
+	SetEscapeHTML(on bool)
+	SetIndent(prefix, indent string)
+}
+
+type JSONDecoder interface {
+	Decode(v interface{}) error
+	DisallowUnknownFields()
+	UseNumber()
+}
+
+// JSONDecodeOptions configures how request bodies are decoded by the JSON binder.
+type JSONDecodeOptions struct {
+	// DisallowUnknownFields rejects objects with keys that do not match a field.
+	DisallowUnknownFields bool
+	// UseNumber decodes numbers into interface{} values as json.Number.
+	UseNumber bool
+}
+
+type stdJSONCodec struct{}
+
+func (stdJSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (stdJSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (stdJSONCodec) NewEncoder(w io.Writer) JSONEncoder {
+	return json.NewEncoder(w)
+}
+
+func (stdJSONCodec) NewDecoder(r io.Reader) JSONDecoder {
+	return json.NewDecoder(r)
+}
+
+type jsonRender struct {
+	escapeHTML bool
+	ascii      bool
+	indent     string
+	prefix     string
+	suffix     string
+}
+
+// writeJSON encodes i into a pooled buffer, so that encoding errors leave the
+// response untouched, and writes it with a single call.
+func (c *Ctx) writeJSON(code int, contentType string, i interface{}, r jsonRender) error {
+	buf := bytebufferpool.Get()
+	defer bytebufferpool.Put(buf)
+	_, _ = buf.WriteString(r.prefix)
+	enc := c.app.jsonCodec.NewEncoder(buf)
+	enc.SetEscapeHTML(r.escapeHTML)
+	if r.indent != "" {
+		enc.SetIndent("", r.indent)
+	}
+	if err := enc.Encode(i); err != nil {
+		return err
+	}
+	// Encode terminates the value with a newline, Marshal does not
+	if n := len(buf.B); n > 0 && buf.B[n-1] == '\n' {
+		buf.B = buf.B[:n-1]
+	}
+	if r.ascii {
+		buf.B = escapeNonASCII(buf.B, len(r.prefix))
+	}
+	_, _ = buf.WriteString(r.suffix)
+	c.writeContentType(contentType)
+	c.Status(code)
+	_, err := c.Response.Write(buf.B)
+	return err
+}
+
+// escapeNonASCII replaces the non-ASCII characters of b after offset with \u
+// escapes. JSON only carries them inside strings, where the escapes are valid.
+func escapeNonASCII(b []byte, offset int) []byte {
+	i := offset
+	for i < len(b) && b[i] < utf8.RuneSelf {
+		i++
+	}
+	if i == len(b) {
+		return b
+	}
+	const hex = "0123456789abcdef"
+	out := make([]byte, i, len(b)+len(b)/2)
+	copy(out, b[:i])
+	for i < len(b) {
+		if b[i] < utf8.RuneSelf {
+			out = append(out, b[i])
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRune(b[i:])
+		i += size
+		units := []rune{r}
+		if r1, r2 := utf16.EncodeRune(r); r1 != utf8.RuneError {
+			units = []rune{r1, r2}
+		}
+		for _, u := range units {
+			out = append(out, '\\', 'u', hex[u>>12&0xf], hex[u>>8&0xf], hex[u>>4&0xf], hex[u&0xf])
+		}
+	}
+	return out
+}
+
+// validCallback reports whether name is a dotted path of JavaScript identifiers.
+func validCallback(name string) bool {
+	if name == "" || len(name) > 128 {
+		return false
+	}
+	start := true
+	for i := 0; i < len(name); i++ {
+		ch := name[i]
+		switch {
+		case ch == '.':
+			if start {
+				return false
+			}
+			start = true
+			continue
+		case ch == '_' || ch == '$' || ('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z'):
+		case '0' <= ch && ch <= '9':
+			if start {
+				return false
+			}
+		default:
+			return false
+		}
+		start = false
+	}
+	return !start
+}
@@ -0,0 +1,91 @@
+package bytego
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestCtx_JSONVariants(t *testing.T) {
+	data := Map{"html": "<b>&</b>", "text": "héllo 😀"}
+	tests := []struct {
+		name   string
+		render func(c *Ctx) error
+		want   string
+	}{
+		{"json", func(c *Ctx) error { return c.JSON(200, data) },
+			`{"html":"\u003cb\u003e\u0026\u003c/b\u003e","text":"héllo 😀"}`},
+		{"pure", func(c *Ctx) error { return c.PureJSON(200, data) },
+			`{"html":"<b>&</b>","text":"héllo 😀"}`},
+		{"indented", func(c *Ctx) error { return c.IndentedJSON(200, Map{"a": 1}) },
+			"{\n    \"a\": 1\n}"},
+		{"secure", func(c *Ctx) error { return c.SecureJSON(200, []int{1, 2}) },
+			"while(1);[1,2]"},
+		{"ascii", func(c *Ctx) error { return c.AsciiJSON(200, data) },
+			`{"html":"\u003cb\u003e\u0026\u003c/b\u003e","text":"h\u00e9llo \ud83d\ude00"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			c := newTestCtx(New(), httptest.NewRequest(http.MethodGet, "/", nil))
+			c.writer = newResponseWriter(rec, c.app)
+			c.Response = c.writer
+			if err := tt.render(c); err != nil {
+				t.Fatal(err)
+			}
+			if rec.Body.String() != tt.want {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.want)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != jsonContentType {
+				t.Errorf("Content-Type = %q", ct)
+			}
+		})
+	}
+}
+
+func TestCtx_JSONError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	c := newTestCtx(New(), httptest.NewRequest(http.MethodGet, "/", nil))
+	c.writer = newResponseWriter(rec, c.app)
+	c.Response = c.writer
+	if err := c.JSON(200, Map{"ch": make(chan int)}); err == nil {
+		t.Fatal("JSON() error = nil")
+	}
+	if c.Response.Committed() || rec.Body.Len() != 0 {
+		t.Error("response written on encoding error")
+	}
+}
+
+func TestCtx_JSONP(t *testing.T) {
+	tests := []struct {
+		callback string
+		want     string
+		err      error
+	}{
+		{"", `{"a":1}`, nil},
+		{"cb", `/**/cb({"a":1});`, nil},
+		{"$.jsonp_1", `/**/$.jsonp_1({"a":1});`, nil},
+		{"alert(1)//", "", ErrInvalidCallback},
+		{"a..b", "", ErrInvalidCallback},
+		{"1a", "", ErrInvalidCallback},
+		{"a.", "", ErrInvalidCallback},
+	}
+	for _, tt := range tests {
+		t.Run(tt.callback, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/?callback="+url.QueryEscape(tt.callback), nil)
+			c := newTestCtx(New(), req)
+			c.writer = newResponseWriter(rec, c.app)
+			c.Response = c.writer
+			err := c.JSONP(200, Map{"a": 1})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("JSONP() error = %v, want %v", err, tt.err)
+			}
+			if rec.Body.String() != tt.want {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.want)
+			}
+		})
+	}
+}
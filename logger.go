@@ -133,3 +133,41 @@ func (l *defaultLogger) write(level LogLevel, format string, v ...interface{}) e
 	l.pool.Put(buf)
 	return nil
 }
+
+// requestLogger prefixes the messages of a Logger, as returned by Ctx.Logger.
+type requestLogger struct {
+	Logger
+	prefix string
+}
+
+func (l *requestLogger) Debug(v ...interface{}) {
+	l.Logger.Debug(append([]interface{}{l.prefix}, v...)...)
+}
+
+func (l *requestLogger) Info(v ...interface{}) {
+	l.Logger.Info(append([]interface{}{l.prefix}, v...)...)
+}
+
+func (l *requestLogger) Warn(v ...interface{}) {
+	l.Logger.Warn(append([]interface{}{l.prefix}, v...)...)
+}
+
+func (l *requestLogger) Error(v ...interface{}) {
+	l.Logger.Error(append([]interface{}{l.prefix}, v...)...)
+}
+
+func (l *requestLogger) Debugf(format string, v ...interface{}) {
+	l.Logger.Debugf("%s"+format, append([]interface{}{l.prefix}, v...)...)
+}
+
+func (l *requestLogger) Infof(format string, v ...interface{}) {
+	l.Logger.Infof("%s"+format, append([]interface{}{l.prefix}, v...)...)
+}
+
+func (l *requestLogger) Warnf(format string, v ...interface{}) {
+	l.Logger.Warnf("%s"+format, append([]interface{}{l.prefix}, v...)...)
+}
+
+func (l *requestLogger) Errorf(format string, v ...interface{}) {
+	l.Logger.Errorf("%s"+format, append([]interface{}{l.prefix}, v...)...)
+}
@@ -0,0 +1,39 @@
+// Package basicauth authenticates requests with HTTP Basic credentials (RFC 7617).
+package basicauth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"strconv"
+
+	"github.com/gostack-labs/bytego"
+)
+
+const headerWWWAuthenticate = "WWW-Authenticate"
+
+// New returns a middleware rejecting requests without valid credentials with
+// bytego.ErrUnauthorized and a WWW-Authenticate challenge. The username of
+// valid requests is stored on the Ctx under ContextKey.
+func New(config ...Config) bytego.HandlerFunc {
+	cfg := configDefault(config...)
+	challenge := "Basic realm=" + strconv.Quote(cfg.Realm) + `, charset="UTF-8"`
+	return func(c *bytego.Ctx) error {
+		if cfg.Skipper != nil && cfg.Skipper(c) {
+			return c.Next()
+		}
+		username, password, ok := c.Request.BasicAuth()
+		if !ok || !cfg.Authorizer(c, username, password) {
+			c.SetHeader(headerWWWAuthenticate, challenge)
+			return bytego.ErrUnauthorized
+		}
+		c.Set(cfg.ContextKey, username)
+		return c.Next()
+	}
+}
+
+// secureCompare compares digests of a and b, so that neither the content nor
+// the length of the password leaks through timing.
+func secureCompare(a, b string) bool {
+	ha, hb := sha256.Sum256([]byte(a)), sha256.Sum256([]byte(b))
+	return subtle.ConstantTimeCompare(ha[:], hb[:]) == 1
+}
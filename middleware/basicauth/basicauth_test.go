@@ -0,0 +1,61 @@
+package basicauth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gostack-labs/bytego"
+)
+
+func TestBasicAuth(t *testing.T) {
+	app := bytego.New()
+	app.Use(New(Config{
+		Users:   map[string]string{"admin": "secret"},
+		Skipper: func(c *bytego.Ctx) bool { return c.Request.URL.Path == "/health" },
+	}))
+	handler := func(c *bytego.Ctx) error {
+		user, _ := c.Get("username")
+		return c.String(200, "hello "+toString(user))
+	}
+	app.GET("/", handler)
+	app.GET("/health", handler)
+	tests := []struct {
+		name     string
+		path     string
+		user     string
+		password string
+		status   int
+		body     string
+	}{
+		{"valid", "/", "admin", "secret", 200, "hello admin"},
+		{"wrong password", "/", "admin", "nope", 401, ""},
+		{"unknown user", "/", "guest", "secret", 401, ""},
+		{"missing", "/", "", "", 401, ""},
+		{"skipped", "/health", "", "", 200, "hello "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.user != "" {
+				req.SetBasicAuth(tt.user, tt.password)
+			}
+			rec := httptest.NewRecorder()
+			app.Handler().ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if tt.status == 401 && rec.Header().Get(headerWWWAuthenticate) != `Basic realm="Restricted", charset="UTF-8"` {
+				t.Errorf("WWW-Authenticate = %q", rec.Header().Get(headerWWWAuthenticate))
+			}
+			if tt.body != "" && rec.Body.String() != tt.body {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.body)
+			}
+		})
+	}
+}
+
+func toString(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
@@ -0,0 +1,55 @@
+package basicauth
+
+import (
+	"github.com/gostack-labs/bytego"
+)
+
+type Config struct {
+	// Skipper skips the middleware for a request when it returns true.
+	//
+	// Optional. Default value nil.
+	Skipper func(c *bytego.Ctx) bool
+
+	// Users maps usernames to passwords, checked when Authorizer is nil.
+	//
+	// Optional. Default value nil.
+	Users map[string]string
+
+	// Authorizer reports whether the credentials are valid.
+	// Default: a constant time comparison against Users
+	Authorizer func(c *bytego.Ctx, username, password string) bool
+
+	// Realm is the protection space announced in WWW-Authenticate.
+	// Default: "Restricted"
+	Realm string
+
+	// ContextKey is the Ctx key the authenticated username is stored under.
+	// Default: "username"
+	ContextKey string
+}
+
+var DefaultConfig = Config{
+	Realm:      "Restricted",
+	ContextKey: "username",
+}
+
+func configDefault(config ...Config) Config {
+	cfg := DefaultConfig
+	if len(config) > 0 {
+		cfg = config[0]
+		if cfg.Realm == "" {
+			cfg.Realm = DefaultConfig.Realm
+		}
+		if cfg.ContextKey == "" {
+			cfg.ContextKey = DefaultConfig.ContextKey
+		}
+	}
+	if cfg.Authorizer == nil {
+		users := cfg.Users
+		cfg.Authorizer = func(c *bytego.Ctx, username, password string) bool {
+			want, ok := users[username]
+			return ok && secureCompare(password, want)
+		}
+	}
+	return cfg
+}
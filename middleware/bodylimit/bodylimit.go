@@ -0,0 +1,17 @@
+package bodylimit
+
+import (
+	"github.com/gostack-labs/bytego"
+)
+
+// New limits the request body of the routes it is applied to, overriding the
+// limit set by App.SetBodyLimit. A limit <= 0 removes the limit.
+func New(limit int64) bytego.HandlerFunc {
+	return func(c *bytego.Ctx) error {
+		if limit > 0 && c.Request.ContentLength > limit {
+			return bytego.ErrBodyTooLarge
+		}
+		c.SetBodyLimit(limit)
+		return c.Next()
+	}
+}
@@ -0,0 +1,68 @@
+package bodylimit
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gostack-labs/bytego"
+)
+
+func TestBodyLimit(t *testing.T) {
+	var read bool
+	echo := func(c *bytego.Ctx) error {
+		read = true
+		b, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			return err
+		}
+		return c.String(200, string(b))
+	}
+	app := bytego.New()
+	app.SetBodyLimit(8)
+	app.POST("/default", echo)
+	app.POST("/large", New(64), echo)
+	app.POST("/small", New(4), echo)
+	app.POST("/unlimited", New(0), echo)
+
+	tests := []struct {
+		name    string
+		path    string
+		body    string
+		chunked bool
+		status  int
+		read    bool
+	}{
+		{"app limit", "/default", strings.Repeat("a", 16), false, http.StatusRequestEntityTooLarge, true},
+		{"app limit ok", "/default", "abc", false, http.StatusOK, true},
+		{"raised", "/large", strings.Repeat("a", 16), false, http.StatusOK, true},
+		{"raised exceeded", "/large", strings.Repeat("a", 100), false, http.StatusRequestEntityTooLarge, false},
+		{"raised exceeded chunked", "/large", strings.Repeat("a", 100), true, http.StatusRequestEntityTooLarge, true},
+		{"lowered", "/small", "abcdef", false, http.StatusRequestEntityTooLarge, false},
+		{"lowered chunked", "/small", "abcdef", true, http.StatusRequestEntityTooLarge, true},
+		{"removed", "/unlimited", strings.Repeat("a", 100), true, http.StatusOK, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			read = false
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			if tt.chunked {
+				// unknown length, so the limit can only be enforced while reading
+				req.ContentLength = -1
+			}
+			rec := httptest.NewRecorder()
+			app.Handler().ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if tt.status == http.StatusOK && rec.Body.String() != tt.body {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.body)
+			}
+			if read != tt.read {
+				t.Errorf("handler ran = %v, want %v", read, tt.read)
+			}
+		})
+	}
+}
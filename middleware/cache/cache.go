@@ -0,0 +1,200 @@
+// Package cache stores GET responses on the server and replays them for
+// subsequent requests until they expire.
+package cache
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gostack-labs/bytego"
+	"github.com/gostack-labs/bytego/internal/bufwriter"
+)
+
+const (
+	headerAge           = "Age"
+	headerAuthorization = "Authorization"
+	headerCookie        = "Cookie"
+	headerCacheControl  = "Cache-Control"
+	headerSetCookie     = "Set-Cookie"
+	headerXCache        = "X-Cache"
+
+	cacheHit  = "HIT"
+	cacheMiss = "MISS"
+)
+
+// New returns a middleware caching 200 responses to GET requests, which are
+// replayed to GET and HEAD requests with the same key. It follows the
+// Cache-Control directives of both sides: requests with no-cache skip the
+// lookup, no-store also skips storing, and responses that are private,
+// no-cache, no-store, set cookies or vary on * are not stored. Responses to
+// requests with Authorization or Cookie headers are only stored when they are
+// public or have s-maxage (RFC 9111 section 3.5). s-maxage and max-age
+// override Expiration. X-Cache tells whether a response was a HIT or a MISS.
+func New(config ...Config) bytego.HandlerFunc {
+	cfg := configDefault(config...)
+	return func(c *bytego.Ctx) error {
+		method := c.Request.Method
+		if (cfg.Skipper != nil && cfg.Skipper(c)) || (method != http.MethodGet && method != http.MethodHead) {
+			return c.Next()
+		}
+		key := cfg.KeyGenerator(c)
+		reqDirectives := parseCacheControl(c.Header(headerCacheControl))
+		_, noCache := reqDirectives["no-cache"]
+		_, noStore := reqDirectives["no-store"]
+		if !noCache && !noStore {
+			if e, ok := cfg.Store.Get(key); ok && e.matches(c.Request.Header) {
+				return replay(c, e)
+			}
+		}
+
+		// headers set before the handler ran, e.g. by outer middlewares, belong to this request only
+		before := c.Response.Header().Clone()
+		w := bufwriter.New(c.Response)
+		c.Response = w
+		err := c.Next()
+		c.Response = w.ResponseWriter
+		if !w.Buffered() {
+			// nothing held back, e.g. an empty handler or an unmatched route
+			return err
+		}
+		if err == nil && method == http.MethodGet && !noStore {
+			if ttl, ok := expiration(c.Request.Header, w, cfg.Expiration); ok {
+				cfg.Store.Set(key, newEntry(c.Request.Header, before, w), ttl)
+			}
+		}
+		w.Header().Set(headerXCache, cacheMiss)
+		return w.Release(err)
+	}
+}
+
+func newEntry(reqHeader, before http.Header, w *bufwriter.Writer) *Entry {
+	e := &Entry{
+		Status:  w.Status(),
+		Header:  make(http.Header),
+		Body:    append([]byte(nil), w.Bytes()...),
+		Created: time.Now(),
+	}
+	for k, v := range w.Header() {
+		if !equalValues(v, before[k]) {
+			e.Header[k] = append([]string(nil), v...)
+		}
+	}
+	for _, name := range headerTokens(e.Header.Values(bytego.HeaderVary)) {
+		if e.Vary == nil {
+			e.Vary = make(map[string]string)
+		}
+		e.Vary[name] = reqHeader.Get(name)
+	}
+	return e
+}
+
+func equalValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// matches reports whether the request headers named by Vary are the same as
+// those of the request the entry was stored for.
+func (e *Entry) matches(header http.Header) bool {
+	for name, value := range e.Vary {
+		if header.Get(name) != value {
+			return false
+		}
+	}
+	return true
+}
+
+func replay(c *bytego.Ctx, e *Entry) error {
+	c.Abort()
+	header := c.Response.Header()
+	for k, v := range e.Header {
+		header[k] = append([]string(nil), v...)
+	}
+	age := int(time.Since(e.Created) / time.Second)
+	header.Set(headerAge, strconv.Itoa(age))
+	header.Set(headerXCache, cacheHit)
+	c.Status(e.Status)
+	if c.Request.Method == http.MethodHead {
+		return nil
+	}
+	_, err := c.Response.Write(e.Body)
+	return err
+}
+
+// expiration returns how long the response may be stored, if at all.
+func expiration(reqHeader http.Header, w *bufwriter.Writer, fallback time.Duration) (time.Duration, bool) {
+	if w.Status() != http.StatusOK {
+		return 0, false
+	}
+	header := w.Header()
+	if header.Get(headerSetCookie) != "" {
+		return 0, false
+	}
+	for _, name := range headerTokens(header.Values(bytego.HeaderVary)) {
+		if name == "*" {
+			return 0, false
+		}
+	}
+	directives := parseCacheControl(header.Get(headerCacheControl))
+	for _, d := range []string{"no-store", "no-cache", "private"} {
+		if _, ok := directives[d]; ok {
+			return 0, false
+		}
+	}
+	if reqHeader.Get(headerAuthorization) != "" || reqHeader.Get(headerCookie) != "" {
+		_, public := directives["public"]
+		_, sMaxAge := directives["s-maxage"]
+		if !public && !sMaxAge {
+			return 0, false
+		}
+	}
+	for _, d := range []string{"s-maxage", "max-age"} {
+		if v, ok := directives[d]; ok {
+			seconds, err := strconv.Atoi(v)
+			if err != nil || seconds <= 0 {
+				return 0, false
+			}
+			return time.Duration(seconds) * time.Second, true
+		}
+	}
+	return fallback, true
+}
+
+// parseCacheControl returns the lower cased directives of a Cache-Control header.
+func parseCacheControl(v string) map[string]string {
+	if v == "" {
+		return nil
+	}
+	directives := make(map[string]string)
+	for _, part := range strings.Split(v, ",") {
+		name, value := strings.TrimSpace(part), ""
+		if i := strings.IndexByte(name, '='); i >= 0 {
+			name, value = strings.TrimSpace(name[:i]), strings.Trim(strings.TrimSpace(name[i+1:]), `"`)
+		}
+		if name != "" {
+			directives[strings.ToLower(name)] = value
+		}
+	}
+	return directives
+}
+
+func headerTokens(values []string) []string {
+	var tokens []string
+	for _, v := range values {
+		for _, t := range strings.Split(v, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				tokens = append(tokens, http.CanonicalHeaderKey(t))
+			}
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gostack-labs/bytego"
+)
+
+func TestCache(t *testing.T) {
+	calls := map[string]int{}
+	app := bytego.New()
+	app.Use(func(c *bytego.Ctx) error {
+		c.SetHeader(bytego.HeaderXRequestID, c.Request.URL.Path+strconv.Itoa(calls["request"]))
+		calls["request"]++
+		return c.Next()
+	})
+	app.Use(New(Config{Expiration: 50 * time.Millisecond}))
+	handle := func(path string, header map[string]string) {
+		handler := func(c *bytego.Ctx) error {
+			calls[path]++
+			for k, v := range header {
+				c.SetHeader(k, v)
+			}
+			return c.String(200, path+strconv.Itoa(calls[path]))
+		}
+		app.GET(path, handler)
+		app.HEAD(path, handler)
+	}
+	handle("/", nil)
+	handle("/no-store", map[string]string{headerCacheControl: "no-store"})
+	handle("/private", map[string]string{headerCacheControl: "private, max-age=60"})
+	handle("/cookie", map[string]string{headerSetCookie: "id=1"})
+	handle("/vary", map[string]string{bytego.HeaderVary: bytego.HeaderAcceptLanguage})
+	handle("/vary-all", map[string]string{bytego.HeaderVary: "*"})
+	handle("/s-maxage", map[string]string{headerCacheControl: "max-age=0, s-maxage=60"})
+	handle("/account", nil)
+	handle("/public", map[string]string{headerCacheControl: "public"})
+
+	get := func(method, path string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		app.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		header map[string]string
+		xcache string
+		body   string
+	}{
+		{"miss", http.MethodGet, "/", nil, cacheMiss, "/1"},
+		{"hit", http.MethodGet, "/", nil, cacheHit, "/1"},
+		{"head hit", http.MethodHead, "/", nil, cacheHit, ""},
+		{"request no-cache", http.MethodGet, "/", map[string]string{headerCacheControl: "no-cache"}, cacheMiss, "/2"},
+		{"refreshed", http.MethodGet, "/", nil, cacheHit, "/2"},
+		{"no-store", http.MethodGet, "/no-store", nil, cacheMiss, "/no-store1"},
+		{"no-store again", http.MethodGet, "/no-store", nil, cacheMiss, "/no-store2"},
+		{"private", http.MethodGet, "/private", nil, cacheMiss, "/private1"},
+		{"private again", http.MethodGet, "/private", nil, cacheMiss, "/private2"},
+		{"cookie", http.MethodGet, "/cookie", nil, cacheMiss, "/cookie1"},
+		{"cookie again", http.MethodGet, "/cookie", nil, cacheMiss, "/cookie2"},
+		{"vary miss", http.MethodGet, "/vary", map[string]string{bytego.HeaderAcceptLanguage: "en"}, cacheMiss, "/vary1"},
+		{"vary hit", http.MethodGet, "/vary", map[string]string{bytego.HeaderAcceptLanguage: "en"}, cacheHit, "/vary1"},
+		{"vary other", http.MethodGet, "/vary", map[string]string{bytego.HeaderAcceptLanguage: "fr"}, cacheMiss, "/vary2"},
+		{"vary all", http.MethodGet, "/vary-all", nil, cacheMiss, "/vary-all1"},
+		{"vary all again", http.MethodGet, "/vary-all", nil, cacheMiss, "/vary-all2"},
+		{"s-maxage", http.MethodGet, "/s-maxage", nil, cacheMiss, "/s-maxage1"},
+		{"s-maxage hit", http.MethodGet, "/s-maxage", nil, cacheHit, "/s-maxage1"},
+		{"authorization", http.MethodGet, "/account", map[string]string{headerAuthorization: "Bearer alice"}, cacheMiss, "/account1"},
+		{"authorization not shared", http.MethodGet, "/account", map[string]string{headerAuthorization: "Bearer bob"}, cacheMiss, "/account2"},
+		{"cookie request", http.MethodGet, "/account", map[string]string{headerCookie: "session=alice"}, cacheMiss, "/account3"},
+		{"cookie not shared", http.MethodGet, "/account", nil, cacheMiss, "/account4"},
+		{"public with credentials", http.MethodGet, "/public", map[string]string{headerAuthorization: "Bearer alice"}, cacheMiss, "/public1"},
+		{"public hit", http.MethodGet, "/public", nil, cacheHit, "/public1"},
+	}
+	for _, tt := range tests {
+		rec := get(tt.method, tt.path, tt.header)
+		if rec.Header().Get(headerXCache) != tt.xcache || rec.Body.String() != tt.body {
+			t.Errorf("%s: got %s %q, want %s %q", tt.name, rec.Header().Get(headerXCache), rec.Body.String(), tt.xcache, tt.body)
+		}
+		if rec.Header().Get(bytego.HeaderXRequestID) != tt.path+strconv.Itoa(calls["request"]-1) {
+			t.Errorf("%s: request id %q replayed from cache", tt.name, rec.Header().Get(bytego.HeaderXRequestID))
+		}
+		if tt.xcache == cacheHit && rec.Header().Get(headerAge) == "" {
+			t.Errorf("%s: missing Age", tt.name)
+		}
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if rec := get(http.MethodGet, "/", nil); rec.Header().Get(headerXCache) != cacheMiss || rec.Body.String() != "/3" {
+		t.Errorf("expired: got %s %q", rec.Header().Get(headerXCache), rec.Body.String())
+	}
+	if rec := get(http.MethodGet, "/s-maxage", nil); rec.Header().Get(headerXCache) != cacheHit {
+		t.Error("s-maxage did not override Expiration")
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	s := NewMemoryStore(2)
+	s.Set("a", &Entry{Body: []byte("a")}, time.Minute)
+	s.Set("b", &Entry{Body: []byte("b")}, time.Minute)
+	if _, ok := s.Get("a"); !ok {
+		t.Fatal("a missing")
+	}
+	s.Set("c", &Entry{Body: []byte("c")}, time.Minute)
+	if _, ok := s.Get("b"); ok {
+		t.Error("least recently used entry b not evicted")
+	}
+	if _, ok := s.Get("a"); !ok {
+		t.Error("a evicted")
+	}
+	s.Set("d", &Entry{}, -time.Second)
+	if _, ok := s.Get("d"); ok {
+		t.Error("expired entry returned")
+	}
+	if s.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", s.Len())
+	}
+	s.Delete("a")
+	if s.Len() != 0 {
+		t.Errorf("Len() = %d after Delete, want 0", s.Len())
+	}
+}
+
+func TestCache_Empty(t *testing.T) {
+	app := bytego.New()
+	app.Use(New())
+	app.GET("/", func(c *bytego.Ctx) error {
+		return nil
+	})
+	for path, status := range map[string]int{"/": http.StatusOK, "/missing": http.StatusNotFound} {
+		rec := httptest.NewRecorder()
+		app.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != status {
+			t.Errorf("%s: status = %d, want %d", path, rec.Code, status)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/gostack-labs/bytego"
+)
+
+type Config struct {
+	// Skipper skips the middleware for a request when it returns true.
+	//
+	// Optional. Default value nil.
+	Skipper func(c *bytego.Ctx) bool
+
+	// Expiration is how long a response is cached when it has no max-age or
+	// s-maxage Cache-Control directive. Use a separate middleware per route
+	// group for different TTLs.
+	// Default: 1 minute
+	Expiration time.Duration
+
+	// KeyGenerator returns the key a response is cached under.
+	// Default: the request URI
+	KeyGenerator func(c *bytego.Ctx) string
+
+	// Store keeps the cached responses and can be shared between middlewares.
+	// Default: NewMemoryStore(1024)
+	Store Store
+}
+
+var DefaultConfig = Config{
+	Expiration: time.Minute,
+	KeyGenerator: func(c *bytego.Ctx) string {
+		return c.Request.URL.RequestURI()
+	},
+}
+
+func configDefault(config ...Config) Config {
+	cfg := DefaultConfig
+	if len(config) > 0 {
+		cfg = config[0]
+		if cfg.Expiration <= 0 {
+			cfg.Expiration = DefaultConfig.Expiration
+		}
+		if cfg.KeyGenerator == nil {
+			cfg.KeyGenerator = DefaultConfig.KeyGenerator
+		}
+	}
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryStore(1024)
+	}
+	return cfg
+}
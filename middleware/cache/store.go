@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"container/list"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Entry is a cached response.
+type Entry struct {
+	Status  int
+	Header  http.Header
+	Body    []byte
+	Created time.Time
+	// Vary holds the request header values named by the Vary response header
+	// when the entry was stored.
+	Vary map[string]string
+}
+
+// Store keeps cached responses. Implementations must be safe for concurrent use.
+type Store interface {
+	// Get returns the entry stored under key unless it has expired.
+	Get(key string) (*Entry, bool)
+	Set(key string, entry *Entry, ttl time.Duration)
+	Delete(key string)
+}
+
+// MemoryStore is an in-memory Store evicting the least recently used entry
+// once it holds its capacity.
+type MemoryStore struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	lru      *list.List
+}
+
+type memoryItem struct {
+	key     string
+	entry   *Entry
+	expires time.Time
+}
+
+// NewMemoryStore returns a MemoryStore holding up to capacity entries.
+func NewMemoryStore(capacity int) *MemoryStore {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &MemoryStore{
+		capacity: capacity,
+		items:    make(map[string]*list.Element, capacity),
+		lru:      list.New(),
+	}
+}
+
+func (s *MemoryStore) Get(key string) (*Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	el, ok := s.items[key]
+	if !ok {
+		return nil, false
+	}
+	item := el.Value.(*memoryItem)
+	if time.Now().After(item.expires) {
+		s.remove(el)
+		return nil, false
+	}
+	s.lru.MoveToFront(el)
+	return item.entry, true
+}
+
+func (s *MemoryStore) Set(key string, entry *Entry, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item := &memoryItem{key: key, entry: entry, expires: time.Now().Add(ttl)}
+	if el, ok := s.items[key]; ok {
+		el.Value = item
+		s.lru.MoveToFront(el)
+		return
+	}
+	s.items[key] = s.lru.PushFront(item)
+	for s.lru.Len() > s.capacity {
+		s.remove(s.lru.Back())
+	}
+}
+
+func (s *MemoryStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.items[key]; ok {
+		s.remove(el)
+	}
+}
+
+// Len returns the number of stored entries, including expired ones not yet evicted.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lru.Len()
+}
+
+func (s *MemoryStore) remove(el *list.Element) {
+	s.lru.Remove(el)
+	delete(s.items, el.Value.(*memoryItem).key)
+}
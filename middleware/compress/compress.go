@@ -0,0 +1,319 @@
+// Package compress compresses response bodies with the content coding that
+// best matches the Accept-Encoding request header. gzip and deflate are built
+// in; brotli ("br") is not, the standard library having no encoder, but can be
+// added as a Coding backed by an external package.
+package compress
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gostack-labs/bytego"
+	"github.com/gostack-labs/bytego/internal/respctl"
+)
+
+const (
+	headerContentEncoding = "Content-Encoding"
+	headerContentRange    = "Content-Range"
+	headerContentType     = "Content-Type"
+	headerETag            = "ETag"
+	headerRange           = "Range"
+)
+
+// Encoder is a compressing writer that can be reused through Reset, as
+// implemented by gzip.Writer, flate.Writer and most brotli packages.
+type Encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// Coding is a content coding of the Content-Encoding header.
+type Coding struct {
+	// Name is the coding token, e.g. "gzip" or "br".
+	Name string
+	// New returns an encoder writing to w.
+	New func(w io.Writer) Encoder
+}
+
+// NoCompression is the level storing bodies in the coding format without
+// compressing them, the zero level meaning the default one.
+const NoCompression = -100
+
+func flateLevel(level int) int {
+	switch level {
+	case 0:
+		return flate.DefaultCompression
+	case NoCompression:
+		return flate.NoCompression
+	}
+	return level
+}
+
+// Gzip returns the gzip coding with the given compression level, which like
+// Config.Level is the default one when 0.
+func Gzip(level int) Coding {
+	level = flateLevel(level)
+	return Coding{Name: "gzip", New: func(w io.Writer) Encoder {
+		zw, err := gzip.NewWriterLevel(w, level)
+		if err != nil {
+			zw = gzip.NewWriter(w)
+		}
+		return zw
+	}}
+}
+
+// Deflate returns the deflate coding with the given compression level, which
+// like Config.Level is the default one when 0.
+func Deflate(level int) Coding {
+	level = flateLevel(level)
+	return Coding{Name: "deflate", New: func(w io.Writer) Encoder {
+		zw, err := flate.NewWriter(w, level)
+		if err != nil {
+			zw, _ = flate.NewWriter(w, flate.DefaultCompression)
+		}
+		return zw
+	}}
+}
+
+type coding struct {
+	name string
+	pool sync.Pool
+}
+
+// New returns a middleware compressing responses. Requests without
+// Accept-Encoding, Range and HEAD requests, excluded content types and bodies
+// below MinLength are sent as is.
+func New(config ...Config) bytego.HandlerFunc {
+	cfg := configDefault(config...)
+	codings := make(map[string]*coding, len(cfg.Codings))
+	offers := make([]string, 0, len(cfg.Codings)+1)
+	for _, c := range cfg.Codings {
+		newEncoder := c.New
+		codings[c.Name] = &coding{
+			name: c.Name,
+			pool: sync.Pool{New: func() interface{} { return newEncoder(io.Discard) }},
+		}
+		offers = append(offers, c.Name)
+	}
+	offers = append(offers, "identity")
+
+	return func(c *bytego.Ctx) error {
+		if (cfg.Skipper != nil && cfg.Skipper(c)) || c.Request.Method == http.MethodHead ||
+			c.Header(headerRange) != "" {
+			return c.Next()
+		}
+		w := &compressWriter{
+			ResponseWriter: c.Response,
+			c:              c,
+			cfg:            &cfg,
+		}
+		// clients without Accept-Encoding may not understand any coding
+		if c.Header(bytego.HeaderAcceptEncoding) != "" {
+			w.coding = codings[c.AcceptsEncodings(offers...)]
+		}
+		c.Response = w
+		err := c.Next()
+		c.Response = w.ResponseWriter
+		if w.wroteHeader {
+			if cerr := w.close(); err == nil {
+				err = cerr
+			}
+		}
+		return err
+	}
+}
+
+// compressWriter holds back the status and the first MinLength bytes of the
+// body until it knows whether the response is worth compressing.
+type compressWriter struct {
+	bytego.ResponseWriter
+	c           *bytego.Ctx
+	cfg         *Config
+	coding      *coding
+	status      int
+	size        int
+	wroteHeader bool
+	decided     bool
+	hijacked    bool
+	buf         []byte
+	enc         Encoder
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		if w.decided {
+			w.ResponseWriter.WriteHeader(code)
+		}
+		return
+	}
+	if code < http.StatusOK {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.status = code
+	w.wroteHeader = true
+	if code == http.StatusNoContent || code == http.StatusNotModified {
+		w.decide(false)
+	}
+}
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	w.size += len(p)
+	if !w.decided {
+		w.buf = append(w.buf, p...)
+		if len(w.buf) < w.cfg.MinLength {
+			return len(p), nil
+		}
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	if w.enc != nil {
+		return w.enc.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *compressWriter) Status() int {
+	if w.wroteHeader {
+		return w.status
+	}
+	return w.ResponseWriter.Status()
+}
+
+func (w *compressWriter) Size() int {
+	return w.size
+}
+
+func (w *compressWriter) Committed() bool {
+	return w.wroteHeader || w.ResponseWriter.Committed()
+}
+
+// Flush compresses what has been written so far and flushes it to the client,
+// so that streamed responses are compressed regardless of MinLength.
+func (w *compressWriter) Flush() {
+	if w.hijacked {
+		return
+	}
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.decided {
+		if err := w.decide(true); err != nil {
+			return
+		}
+	}
+	if w.enc != nil {
+		_ = w.enc.Flush()
+	}
+	_ = respctl.Flush(w.ResponseWriter)
+}
+
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := respctl.Hijack(w.ResponseWriter)
+	if err == nil {
+		w.hijacked = true
+	}
+	return conn, rw, err
+}
+
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// decide sends the headers, compressing the body when the response qualifies.
+// A body that is complete is compressed only when it reaches MinLength.
+func (w *compressWriter) decide(partial bool) error {
+	w.decided = true
+	header := w.Header()
+	if w.compressible(header) {
+		w.c.AppendHeader(bytego.HeaderVary, bytego.HeaderAcceptEncoding)
+		if w.coding != nil && (partial || len(w.buf) >= w.cfg.MinLength) {
+			if header.Get(headerContentType) == "" && len(w.buf) > 0 {
+				// the server would sniff the compressed bytes otherwise
+				header.Set(headerContentType, http.DetectContentType(w.buf))
+			}
+			header.Del(bytego.HeaderContentLength)
+			header.Set(headerContentEncoding, w.coding.name)
+			if etag := header.Get(headerETag); strings.HasPrefix(etag, `"`) {
+				header.Set(headerETag, "W/"+etag)
+			}
+			w.enc = w.coding.pool.Get().(Encoder)
+			w.enc.Reset(w.ResponseWriter)
+		}
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if w.enc != nil {
+		_, err = w.enc.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+func (w *compressWriter) compressible(header http.Header) bool {
+	switch w.status {
+	case http.StatusNoContent, http.StatusNotModified, http.StatusPartialContent:
+		return false
+	}
+	if header.Get(headerContentEncoding) != "" || header.Get(headerContentRange) != "" {
+		return false
+	}
+	if n, err := strconv.Atoi(header.Get(bytego.HeaderContentLength)); err == nil && n < w.cfg.MinLength {
+		return false
+	}
+	contentType := header.Get(headerContentType)
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	for _, excluded := range w.cfg.ExcludedContentTypes {
+		if contentType == excluded || (strings.HasSuffix(excluded, "/") && strings.HasPrefix(contentType, excluded)) {
+			return false
+		}
+	}
+	return true
+}
+
+// close flushes the held back body or finishes the compressed stream and
+// returns the encoder to its pool.
+func (w *compressWriter) close() error {
+	if w.hijacked {
+		return nil
+	}
+	if !w.decided {
+		if err := w.decide(false); err != nil {
+			return err
+		}
+	}
+	if w.enc == nil {
+		return nil
+	}
+	err := w.enc.Close()
+	w.enc.Reset(io.Discard)
+	w.coding.pool.Put(w.enc)
+	w.enc = nil
+	return err
+}
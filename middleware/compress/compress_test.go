@@ -0,0 +1,166 @@
+package compress
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gostack-labs/bytego"
+)
+
+var large = strings.Repeat("bytego compress ", 200)
+
+func newApp(config ...Config) *bytego.App {
+	app := bytego.New()
+	app.Use(New(config...))
+	app.GET("/large", func(c *bytego.Ctx) error {
+		c.SetHeader(bytego.HeaderContentLength, strconv.Itoa(len(large)))
+		c.SetHeader("ETag", `"v1"`)
+		return c.String(200, large)
+	})
+	app.GET("/small", func(c *bytego.Ctx) error {
+		return c.String(200, "ok")
+	})
+	app.GET("/image", func(c *bytego.Ctx) error {
+		return c.Blob(200, "image/png", []byte(large))
+	})
+	app.GET("/error", func(c *bytego.Ctx) error {
+		return bytego.ErrForbidden
+	})
+	app.GET("/stream", func(c *bytego.Ctx) error {
+		n := 0
+		c.Stream(func(w io.Writer) bool {
+			n++
+			_, _ = io.WriteString(w, "chunk\n")
+			return n < 3
+		})
+		return nil
+	})
+	return app
+}
+
+func decode(t *testing.T, encoding string, r io.Reader) string {
+	t.Helper()
+	var rc io.Reader = r
+	switch encoding {
+	case "gzip":
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rc = zr
+	case "deflate":
+		rc = flate.NewReader(r)
+	}
+	b, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestCompress(t *testing.T) {
+	app := newApp()
+	tests := []struct {
+		name           string
+		path           string
+		acceptEncoding string
+		header         map[string]string
+		encoding       string
+		vary           bool
+		body           string
+	}{
+		{"gzip", "/large", "gzip, deflate", nil, "gzip", true, large},
+		{"deflate preferred", "/large", "gzip;q=0.5, deflate", nil, "deflate", true, large},
+		{"not accepted", "/large", "br", nil, "", true, large},
+		{"no accept-encoding", "/large", "", nil, "", true, large},
+		{"small body", "/small", "gzip", nil, "", true, "ok"},
+		{"excluded type", "/image", "gzip", nil, "", false, large},
+		{"range", "/large", "gzip", map[string]string{"Range": "bytes=0-1"}, "", false, large},
+		{"stream", "/stream", "gzip", nil, "gzip", true, "chunk\nchunk\nchunk\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set(bytego.HeaderAcceptEncoding, tt.acceptEncoding)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			app.Handler().ServeHTTP(rec, req)
+			res := rec.Result()
+			if got := res.Header.Get(headerContentEncoding); got != tt.encoding {
+				t.Errorf("Content-Encoding = %q, want %q", got, tt.encoding)
+			}
+			if got := res.Header.Get(bytego.HeaderVary) == bytego.HeaderAcceptEncoding; got != tt.vary {
+				t.Errorf("Vary = %q", res.Header.Get(bytego.HeaderVary))
+			}
+			if body := decode(t, tt.encoding, res.Body); body != tt.body {
+				t.Errorf("body = %q, want %q", body, tt.body)
+			}
+			if tt.encoding != "" && tt.path == "/large" {
+				if res.Header.Get(bytego.HeaderContentLength) != "" || res.Header.Get("ETag") != `W/"v1"` {
+					t.Errorf("headers = %v", res.Header)
+				}
+			}
+		})
+	}
+}
+
+func TestCompress_Error(t *testing.T) {
+	app := newApp()
+	req := httptest.NewRequest(http.MethodGet, "/error", nil)
+	req.Header.Set(bytego.HeaderAcceptEncoding, "gzip")
+	rec := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden || rec.Header().Get(headerContentEncoding) != "" {
+		t.Errorf("status = %d, headers = %v", rec.Code, rec.Header())
+	}
+}
+
+func TestCompress_PooledEncoders(t *testing.T) {
+	app := newApp(Config{MinLength: 16, Level: gzip.BestSpeed})
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/large", nil)
+		req.Header.Set(bytego.HeaderAcceptEncoding, "gzip")
+		rec := httptest.NewRecorder()
+		app.Handler().ServeHTTP(rec, req)
+		if body := decode(t, "gzip", rec.Body); body != large {
+			t.Fatalf("request %d: body of %d bytes", i, len(body))
+		}
+	}
+}
+
+func TestCompress_NoCompression(t *testing.T) {
+	app := newApp(Config{Level: NoCompression})
+	req := httptest.NewRequest(http.MethodGet, "/large", nil)
+	req.Header.Set(bytego.HeaderAcceptEncoding, "gzip")
+	rec := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, req)
+	if rec.Header().Get(headerContentEncoding) != "gzip" || rec.Body.Len() <= len(large) {
+		t.Fatalf("got %q with %d bytes, want stored gzip larger than %d", rec.Header().Get(headerContentEncoding), rec.Body.Len(), len(large))
+	}
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, _ := io.ReadAll(zr); string(body) != large {
+		t.Errorf("body mismatch")
+	}
+}
+
+func TestCompress_ZeroLevelCoding(t *testing.T) {
+	app := newApp(Config{Codings: []Coding{Gzip(0)}})
+	req := httptest.NewRequest(http.MethodGet, "/large", nil)
+	req.Header.Set(bytego.HeaderAcceptEncoding, "gzip")
+	rec := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, req)
+	if rec.Body.Len() >= len(large)/2 {
+		t.Errorf("Gzip(0) wrote %d bytes for %d, want the default compression", rec.Body.Len(), len(large))
+	}
+}
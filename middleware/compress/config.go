@@ -0,0 +1,67 @@
+package compress
+
+import (
+	"compress/flate"
+
+	"github.com/gostack-labs/bytego"
+)
+
+type Config struct {
+	// Skipper skips the middleware for a request when it returns true.
+	//
+	// Optional. Default value nil.
+	Skipper func(c *bytego.Ctx) bool
+
+	// Level is the compression level of the default gzip and deflate codings,
+	// from flate.BestSpeed to flate.BestCompression, or NoCompression.
+	// Default: flate.DefaultCompression
+	Level int
+
+	// MinLength is the smallest body in bytes worth compressing. Smaller bodies
+	// are sent as is unless the handler flushes first.
+	// Default: 1024
+	MinLength int
+
+	// Codings lists the supported content codings in order of preference.
+	// There is no built-in brotli coding; register "br" as a Coding backed by
+	// an external package.
+	// Default: []Coding{Gzip(Level), Deflate(Level)}
+	Codings []Coding
+
+	// ExcludedContentTypes lists media types that are not compressed, usually
+	// because they already are. A type ending with "/" matches all its subtypes.
+	// Default: image/, video/, audio/ and common archive and font types
+	ExcludedContentTypes []string
+}
+
+var DefaultConfig = Config{
+	Level:     flate.DefaultCompression,
+	MinLength: 1024,
+	ExcludedContentTypes: []string{
+		"image/", "video/", "audio/",
+		"application/zip", "application/gzip", "application/x-gzip", "application/x-bzip2",
+		"application/x-7z-compressed", "application/x-rar-compressed", "application/x-xz",
+		"application/zstd", "application/pdf", "application/wasm",
+		"font/woff", "font/woff2",
+	},
+}
+
+func configDefault(config ...Config) Config {
+	cfg := DefaultConfig
+	if len(config) > 0 {
+		cfg = config[0]
+		if cfg.Level == 0 {
+			cfg.Level = DefaultConfig.Level
+		}
+		if cfg.MinLength <= 0 {
+			cfg.MinLength = DefaultConfig.MinLength
+		}
+		if cfg.ExcludedContentTypes == nil {
+			cfg.ExcludedContentTypes = DefaultConfig.ExcludedContentTypes
+		}
+	}
+	if len(cfg.Codings) == 0 {
+		cfg.Codings = []Coding{Gzip(cfg.Level), Deflate(cfg.Level)}
+	}
+	return cfg
+}
@@ -0,0 +1,73 @@
+package csrf
+
+import (
+	"github.com/gostack-labs/bytego"
+)
+
+type Config struct {
+	// Skipper skips the middleware for a request when it returns true.
+	//
+	// Optional. Default value nil.
+	Skipper func(c *bytego.Ctx) bool
+
+	// TokenLookup is a comma separated list of "<source>:<name>" places the
+	// token of unsafe requests is looked up in, in order. Sources are header,
+	// form and query.
+	// Default: "header:X-CSRF-Token"
+	TokenLookup string
+
+	// Store keeps tokens on the server for the synchronizer token pattern, the
+	// cookie then holding an opaque ID only. Without a store the cookie holds
+	// the token itself (double submit cookie).
+	//
+	// Optional. Default value nil.
+	Store Store
+
+	// ContextKey is the Ctx key the token is stored under, for templates.
+	// Default: "csrf"
+	ContextKey string
+
+	// Cookie attributes. The SameSite attribute is the one set with
+	// Ctx.SetSameSite.
+	// Default: "_csrf"
+	CookieName string
+	// Default: "/"
+	CookiePath     string
+	CookieDomain   string
+	CookieSecure   bool
+	CookieHTTPOnly bool
+	// CookieMaxAge is the lifetime of the cookie and of stored tokens in seconds.
+	// Default: 86400
+	CookieMaxAge int
+}
+
+var DefaultConfig = Config{
+	TokenLookup:  "header:" + headerXCSRFToken,
+	ContextKey:   "csrf",
+	CookieName:   "_csrf",
+	CookiePath:   "/",
+	CookieMaxAge: 86400,
+}
+
+func configDefault(config ...Config) Config {
+	if len(config) == 0 {
+		return DefaultConfig
+	}
+	cfg := config[0]
+	if cfg.TokenLookup == "" {
+		cfg.TokenLookup = DefaultConfig.TokenLookup
+	}
+	if cfg.ContextKey == "" {
+		cfg.ContextKey = DefaultConfig.ContextKey
+	}
+	if cfg.CookieName == "" {
+		cfg.CookieName = DefaultConfig.CookieName
+	}
+	if cfg.CookiePath == "" {
+		cfg.CookiePath = DefaultConfig.CookiePath
+	}
+	if cfg.CookieMaxAge <= 0 {
+		cfg.CookieMaxAge = DefaultConfig.CookieMaxAge
+	}
+	return cfg
+}
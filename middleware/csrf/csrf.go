@@ -0,0 +1,107 @@
+// Package csrf protects against cross-site request forgery by requiring unsafe
+// requests to echo a token handed out in a cookie or rendered into pages.
+package csrf
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"time"
+
+	"github.com/gostack-labs/bytego"
+	"github.com/gostack-labs/bytego/internal/extractor"
+)
+
+const (
+	headerXCSRFToken = "X-CSRF-Token"
+	headerCookie     = "Cookie"
+
+	tokenLength = 32
+)
+
+var (
+	// ErrTokenMissing is returned when an unsafe request carries no token.
+	ErrTokenMissing = bytego.NewHTTPError(http.StatusForbidden, "missing csrf token")
+	// ErrTokenInvalid is returned when the token of an unsafe request does not
+	// match the one handed out.
+	ErrTokenInvalid = bytego.NewHTTPError(http.StatusForbidden, "invalid csrf token")
+)
+
+// New returns a middleware handing out a token on every request, stored on the
+// Ctx under ContextKey, and rejecting POST, PUT, PATCH, DELETE and other unsafe
+// requests that do not send it back with ErrTokenMissing or ErrTokenInvalid.
+// New panics when TokenLookup names an unknown source.
+func New(config ...Config) bytego.HandlerFunc {
+	cfg := configDefault(config...)
+	lookup, err := extractor.Parse(cfg.TokenLookup, "", "header", "form", "query")
+	if err != nil {
+		panic("csrf: TokenLookup: " + err.Error())
+	}
+	ttl := time.Duration(cfg.CookieMaxAge) * time.Second
+	return func(c *bytego.Ctx) error {
+		if cfg.Skipper != nil && cfg.Skipper(c) {
+			return c.Next()
+		}
+		id, token := "", ""
+		if cookie, err := c.Cookie(cfg.CookieName); err == nil && len(cookie.Value) == base64.RawURLEncoding.EncodedLen(tokenLength) {
+			if cfg.Store == nil {
+				token = cookie.Value
+			} else if t, ok := cfg.Store.Get(cookie.Value); ok {
+				id, token = cookie.Value, t
+			}
+		}
+		c.AppendHeader(bytego.HeaderVary, headerCookie)
+
+		if !safeMethod(c.Request.Method) {
+			sent := lookup.Extract(c)
+			if sent == "" {
+				return ErrTokenMissing
+			}
+			if token == "" || subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+				return ErrTokenInvalid
+			}
+		}
+
+		if token == "" {
+			token = randomToken()
+		}
+		value := token
+		if cfg.Store != nil {
+			if id == "" {
+				id = randomToken()
+			}
+			cfg.Store.Set(id, token, ttl)
+			value = id
+		}
+		c.SetCookie(cfg.CookieName, value, cfg.CookieMaxAge, cfg.CookiePath, cfg.CookieDomain, cfg.CookieSecure, cfg.CookieHTTPOnly)
+		c.Set(cfg.ContextKey, token)
+		return c.Next()
+	}
+}
+
+// Token returns the token stored by the middleware under key, "csrf" by
+// default, e.g. to render it into a form.
+func Token(c *bytego.Ctx, key ...string) string {
+	k := DefaultConfig.ContextKey
+	if len(key) > 0 {
+		k = key[0]
+	}
+	v, _ := c.Get(k)
+	token, _ := v.(string)
+	return token
+}
+
+func safeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+func randomToken() string {
+	b := make([]byte, tokenLength)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
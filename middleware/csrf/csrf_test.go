@@ -0,0 +1,102 @@
+package csrf
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gostack-labs/bytego"
+)
+
+func newApp(cfg Config) *bytego.App {
+	app := bytego.New()
+	app.Use(func(c *bytego.Ctx) error {
+		c.SetSameSite(http.SameSiteStrictMode)
+		return c.Next()
+	})
+	app.Use(New(cfg))
+	app.GET("/form", func(c *bytego.Ctx) error {
+		return c.String(200, Token(c))
+	})
+	app.POST("/submit", func(c *bytego.Ctx) error {
+		return c.String(200, "ok")
+	})
+	return app
+}
+
+func serve(app *bytego.App, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCSRF(t *testing.T) {
+	for name, cfg := range map[string]Config{
+		"double submit": {TokenLookup: "header:X-CSRF-Token,form:_csrf"},
+		"synchronizer":  {TokenLookup: "header:X-CSRF-Token,form:_csrf", Store: NewMemoryStore()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			app := newApp(cfg)
+			rec := serve(app, httptest.NewRequest(http.MethodGet, "/form", nil))
+			token := rec.Body.String()
+			cookies := rec.Result().Cookies()
+			if rec.Code != 200 || token == "" || len(cookies) != 1 {
+				t.Fatalf("GET = %d %q %v", rec.Code, token, cookies)
+			}
+			cookie := cookies[0]
+			if cookie.SameSite != http.SameSiteStrictMode {
+				t.Errorf("SameSite = %v", cookie.SameSite)
+			}
+			if (cfg.Store == nil) != (cookie.Value == token) {
+				t.Errorf("cookie value %q, token %q", cookie.Value, token)
+			}
+
+			post := func(header, form string) int {
+				req := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(form))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				req.AddCookie(cookie)
+				if header != "" {
+					req.Header.Set(headerXCSRFToken, header)
+				}
+				return serve(app, req).Code
+			}
+			if code := post(token, ""); code != 200 {
+				t.Errorf("header token: %d", code)
+			}
+			if code := post("", url.Values{"_csrf": {token}}.Encode()); code != 200 {
+				t.Errorf("form token: %d", code)
+			}
+			if code := post("", ""); code != 403 {
+				t.Errorf("missing token: %d", code)
+			}
+			if code := post("forged", ""); code != 403 {
+				t.Errorf("wrong token: %d", code)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/submit", nil)
+			req.Header.Set(headerXCSRFToken, token)
+			if code := serve(app, req).Code; code != 403 {
+				t.Errorf("without cookie: %d", code)
+			}
+
+			req = httptest.NewRequest(http.MethodGet, "/form", nil)
+			req.AddCookie(cookie)
+			if got := serve(app, req).Body.String(); got != token {
+				t.Errorf("token not reused: %q, want %q", got, token)
+			}
+		})
+	}
+}
+
+func TestCSRF_Skipper(t *testing.T) {
+	app := newApp(Config{Skipper: func(c *bytego.Ctx) bool {
+		return c.Header("Authorization") != ""
+	}})
+	req := httptest.NewRequest(http.MethodPost, "/submit", nil)
+	req.Header.Set("Authorization", "Bearer x")
+	if code := serve(app, req).Code; code != 200 {
+		t.Errorf("skipped request: %d", code)
+	}
+}
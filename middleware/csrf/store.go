@@ -0,0 +1,63 @@
+package csrf
+
+import (
+	"sync"
+	"time"
+)
+
+// Store keeps tokens by the ID held in the cookie. Implementations must be
+// safe for concurrent use.
+type Store interface {
+	// Get returns the token stored under id unless it has expired.
+	Get(id string) (string, bool)
+	Set(id, token string, ttl time.Duration)
+	Delete(id string)
+}
+
+const sweepInterval = 1024
+
+// MemoryStore is an in-memory Store. Expired tokens are dropped periodically.
+type MemoryStore struct {
+	mu     sync.Mutex
+	tokens map[string]memoryToken
+	calls  int
+}
+
+type memoryToken struct {
+	token   string
+	expires time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{tokens: make(map[string]memoryToken)}
+}
+
+func (s *MemoryStore) Get(id string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.tokens[id]
+	if !ok || time.Now().After(t.expires) {
+		return "", false
+	}
+	return t.token, true
+}
+
+func (s *MemoryStore) Set(id, token string, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if s.calls++; s.calls%sweepInterval == 0 {
+		for k, t := range s.tokens {
+			if now.After(t.expires) {
+				delete(s.tokens, k)
+			}
+		}
+	}
+	s.tokens[id] = memoryToken{token: token, expires: now.Add(ttl)}
+}
+
+func (s *MemoryStore) Delete(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, id)
+}
@@ -0,0 +1,38 @@
+package decompress
+
+import (
+	"github.com/gostack-labs/bytego"
+)
+
+type Config struct {
+	// Skipper skips the middleware for a request when it returns true.
+	//
+	// Optional. Default value nil.
+	Skipper func(c *bytego.Ctx) bool
+
+	// MaxSize limits the decompressed body in bytes. Reading beyond it fails
+	// with bytego.ErrBodyTooLarge.
+	// Default: 32 MB
+	MaxSize int64
+
+	// Decoders adds content codings to the built-in gzip, x-gzip, deflate and
+	// br, or replaces them.
+	//
+	// Optional. Default value nil.
+	Decoders map[string]Decoder
+}
+
+var DefaultConfig = Config{
+	MaxSize: 32 << 20,
+}
+
+func configDefault(config ...Config) Config {
+	if len(config) == 0 {
+		return DefaultConfig
+	}
+	cfg := config[0]
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = DefaultConfig.MaxSize
+	}
+	return cfg
+}
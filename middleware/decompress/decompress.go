@@ -0,0 +1,138 @@
+// Package decompress decodes request bodies sent with a Content-Encoding, so
+// that handlers and Ctx.Bind read the original content. gzip, x-gzip, deflate
+// and br (brotli) are built in.
+package decompress
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gostack-labs/bytego"
+	"github.com/gostack-labs/bytego/internal/brotli"
+)
+
+const headerContentEncoding = "Content-Encoding"
+
+var (
+	// ErrUnsupportedEncoding is returned for a Content-Encoding without decoder.
+	ErrUnsupportedEncoding = bytego.NewHTTPError(http.StatusUnsupportedMediaType, "unsupported content encoding")
+	// ErrInvalidBody is returned when the body does not start as its encoding requires.
+	ErrInvalidBody = bytego.NewHTTPError(http.StatusBadRequest, "invalid compressed body")
+)
+
+// Decoder returns a reader of the decoded content of r.
+type Decoder func(r io.Reader) (io.ReadCloser, error)
+
+var builtinDecoders = map[string]Decoder{
+	"gzip":    gzipDecoder,
+	"x-gzip":  gzipDecoder,
+	"deflate": deflateDecoder,
+	"br":      brotliDecoder,
+}
+
+// New returns a middleware decoding request bodies. Codings are undone in
+// reverse order of the Content-Encoding header; an unknown coding is answered
+// with 415 and the supported ones in Accept-Encoding (RFC 7694).
+func New(config ...Config) bytego.HandlerFunc {
+	cfg := configDefault(config...)
+	decoders := make(map[string]Decoder, len(builtinDecoders)+len(cfg.Decoders))
+	for name, d := range builtinDecoders {
+		decoders[name] = d
+	}
+	for name, d := range cfg.Decoders {
+		decoders[strings.ToLower(name)] = d
+	}
+	supported := make([]string, 0, len(decoders))
+	for name := range decoders {
+		supported = append(supported, name)
+	}
+	sort.Strings(supported)
+	acceptEncoding := strings.Join(supported, ", ")
+
+	return func(c *bytego.Ctx) error {
+		if cfg.Skipper != nil && cfg.Skipper(c) {
+			return c.Next()
+		}
+		req := c.Request
+		codings := contentCodings(req.Header)
+		if len(codings) == 0 || req.Body == nil || req.Body == http.NoBody {
+			return c.Next()
+		}
+		body := req.Body
+		for i := len(codings) - 1; i >= 0; i-- {
+			decode, ok := decoders[codings[i]]
+			if !ok {
+				c.SetHeader(bytego.HeaderAcceptEncoding, acceptEncoding)
+				return ErrUnsupportedEncoding
+			}
+			rc, err := decode(body)
+			if err != nil {
+				return ErrInvalidBody.WithErr(err)
+			}
+			body = &decodedBody{ReadCloser: rc, src: body}
+		}
+		req.Body = body
+		req.Header.Del(headerContentEncoding)
+		req.Header.Del(bytego.HeaderContentLength)
+		req.ContentLength = -1
+		c.SetBodyLimit(cfg.MaxSize)
+		return c.Next()
+	}
+}
+
+// contentCodings returns the lower cased codings of the Content-Encoding
+// header in the order they were applied, leaving out identity.
+func contentCodings(header http.Header) []string {
+	var codings []string
+	for _, v := range header[headerContentEncoding] {
+		for _, coding := range strings.Split(v, ",") {
+			coding = strings.ToLower(strings.TrimSpace(coding))
+			if coding != "" && coding != "identity" {
+				codings = append(codings, coding)
+			}
+		}
+	}
+	return codings
+}
+
+// decodedBody closes both the decoder and the body it reads from.
+type decodedBody struct {
+	io.ReadCloser
+	src io.Closer
+}
+
+func (b *decodedBody) Close() error {
+	err := b.ReadCloser.Close()
+	if serr := b.src.Close(); err == nil {
+		err = serr
+	}
+	return err
+}
+
+func gzipDecoder(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+// deflateDecoder reads the zlib format the deflate coding is defined as, and
+// the raw deflate stream that some clients send instead.
+func deflateDecoder(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	if header, err := br.Peek(2); err == nil && isZlibHeader(header) {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
+
+func brotliDecoder(r io.Reader) (io.ReadCloser, error) {
+	return brotli.NewReader(r)
+}
+
+func isZlibHeader(b []byte) bool {
+	return b[0]&0x0f == 8 && (uint16(b[0])<<8|uint16(b[1]))%31 == 0
+}
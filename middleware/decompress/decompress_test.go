@@ -0,0 +1,107 @@
+package decompress
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gostack-labs/bytego"
+)
+
+func compress(t *testing.T, encoding string, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "zlib":
+		w = zlib.NewWriter(&buf)
+	case "flate":
+		w, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecompress(t *testing.T) {
+	payload := []byte(`{"name":"bytego"}`)
+	app := bytego.New()
+	app.Use(New(Config{
+		MaxSize: 1024,
+		Decoders: map[string]Decoder{
+			"rev": func(r io.Reader) (io.ReadCloser, error) {
+				b, err := io.ReadAll(r)
+				for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+					b[i], b[j] = b[j], b[i]
+				}
+				return io.NopCloser(bytes.NewReader(b)), err
+			},
+		},
+	}))
+	app.POST("/", func(c *bytego.Ctx) error {
+		var v struct {
+			Name string `json:"name"`
+		}
+		if err := c.BindJSON(&v); err != nil {
+			return err
+		}
+		return c.String(200, v.Name)
+	})
+	reversed := []byte(`}"ogetyb":"eman"{`)
+	// payload as an uncompressed brotli meta-block
+	brotliPayload := []byte{
+		0x00, 0x01, 0x10, 0x7b, 0x22, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0x3a,
+		0x22, 0x62, 0x79, 0x74, 0x65, 0x67, 0x6f, 0x22, 0x7d, 0x03,
+	}
+	tests := []struct {
+		name     string
+		encoding string
+		body     []byte
+		status   int
+	}{
+		{"identity", "", payload, 200},
+		{"gzip", "gzip", compress(t, "gzip", payload), 200},
+		{"deflate zlib", "deflate", compress(t, "zlib", payload), 200},
+		{"deflate raw", "deflate", compress(t, "flate", payload), 200},
+		{"custom", "rev", reversed, 200},
+		{"stacked", "rev, gzip", compress(t, "gzip", reversed), 200},
+		{"brotli", "br", brotliPayload, 200},
+		{"unsupported", "zstd", payload, http.StatusUnsupportedMediaType},
+		{"invalid gzip", "gzip", payload, http.StatusBadRequest},
+		{"too large", "gzip", compress(t, "gzip", bytes.Repeat([]byte(" "), 4096)), http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(tt.body))
+			req.Header.Set("Content-Type", bytego.MIMEJSON)
+			if tt.encoding != "" {
+				req.Header.Set(headerContentEncoding, tt.encoding)
+			}
+			rec := httptest.NewRecorder()
+			app.Handler().ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+			if tt.status == 200 && rec.Body.String() != "bytego" {
+				t.Errorf("body = %q", rec.Body.String())
+			}
+			if tt.status == http.StatusUnsupportedMediaType {
+				if got := rec.Header().Get(bytego.HeaderAcceptEncoding); !strings.Contains(got, "br, ") {
+					t.Errorf("Accept-Encoding = %q", got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package etag
+
+import (
+	"github.com/gostack-labs/bytego"
+)
+
+type Config struct {
+	// Skipper skips the middleware for a request when it returns true.
+	//
+	// Optional. Default value nil.
+	Skipper func(c *bytego.Ctx) bool
+
+	// Weak generates weak ETags, for responses whose bytes may change without
+	// their meaning changing, e.g. when they are compressed later on.
+	//
+	// Optional. Default value false.
+	Weak bool
+}
+
+var DefaultConfig = Config{}
+
+func configDefault(config ...Config) Config {
+	if len(config) == 0 {
+		return DefaultConfig
+	}
+	return config[0]
+}
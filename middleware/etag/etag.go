@@ -0,0 +1,107 @@
+// Package etag generates ETags for GET and HEAD responses and answers
+// conditional requests whose validators still match with 304 Not Modified.
+package etag
+
+import (
+	"hash/crc32"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gostack-labs/bytego"
+	"github.com/gostack-labs/bytego/internal/bufwriter"
+)
+
+const (
+	headerETag            = "ETag"
+	headerIfNoneMatch     = "If-None-Match"
+	headerIfModifiedSince = "If-Modified-Since"
+	headerLastModified    = "Last-Modified"
+	headerContentType     = "Content-Type"
+)
+
+// New returns a middleware buffering 200 responses to GET and HEAD requests
+// to tag them with a checksum of the body. ETag and Last-Modified headers set
+// by the handler are kept and only used to evaluate the request conditions.
+func New(config ...Config) bytego.HandlerFunc {
+	cfg := configDefault(config...)
+	return func(c *bytego.Ctx) error {
+		if (cfg.Skipper != nil && cfg.Skipper(c)) ||
+			(c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead) {
+			return c.Next()
+		}
+		w := bufwriter.New(c.Response)
+		c.Response = w
+		err := c.Next()
+		c.Response = w.ResponseWriter
+		if !w.Buffered() {
+			// nothing held back, e.g. an empty handler or an unmatched route
+			return err
+		}
+		if err != nil || w.Status() != http.StatusOK {
+			return w.Release(err)
+		}
+
+		header := w.Header()
+		etag := header.Get(headerETag)
+		if etag == "" {
+			etag = generate(w.Bytes(), cfg.Weak)
+			header.Set(headerETag, etag)
+		}
+		if notModified(c.Request.Header, etag, header.Get(headerLastModified)) {
+			header.Del(bytego.HeaderContentLength)
+			header.Del(headerContentType)
+			w.ResponseWriter.WriteHeader(http.StatusNotModified)
+			return nil
+		}
+		return w.Release(nil)
+	}
+}
+
+// generate returns an ETag made of the length and CRC-32 checksum of body.
+func generate(body []byte, weak bool) string {
+	b := make([]byte, 0, 24)
+	if weak {
+		b = append(b, "W/"...)
+	}
+	b = append(b, '"')
+	b = strconv.AppendUint(b, uint64(len(body)), 16)
+	b = append(b, '-')
+	b = strconv.AppendUint(b, uint64(crc32.ChecksumIEEE(body)), 16)
+	return string(append(b, '"'))
+}
+
+// notModified evaluates If-None-Match, or If-Modified-Since when it is absent,
+// as described in RFC 7232 section 6.
+func notModified(header http.Header, etag, lastModified string) bool {
+	if inm := header.Get(headerIfNoneMatch); inm != "" {
+		return matchETag(inm, etag)
+	}
+	ims := header.Get(headerIfModifiedSince)
+	if ims == "" || lastModified == "" {
+		return false
+	}
+	since, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+	return !modified.Truncate(time.Second).After(since)
+}
+
+// matchETag reports whether the If-None-Match list matches etag with the weak
+// comparison function.
+func matchETag(list, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
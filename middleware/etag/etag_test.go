@@ -0,0 +1,96 @@
+package etag
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gostack-labs/bytego"
+)
+
+func TestETag(t *testing.T) {
+	lastModified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	app := bytego.New()
+	app.Use(New())
+	hello := func(c *bytego.Ctx) error {
+		return c.String(200, "hello")
+	}
+	app.GET("/", hello)
+	app.HEAD("/", hello)
+	app.GET("/tagged", func(c *bytego.Ctx) error {
+		c.SetHeader(headerETag, `"v2"`)
+		c.SetHeader(headerLastModified, lastModified.Format(http.TimeFormat))
+		return c.String(200, "hello")
+	})
+	app.GET("/missing", func(c *bytego.Ctx) error {
+		return c.String(404, "missing")
+	})
+	app.POST("/", hello)
+	etag := generate([]byte("hello"), false)
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		header map[string]string
+		status int
+		etag   string
+		body   string
+	}{
+		{"generated", http.MethodGet, "/", nil, 200, etag, "hello"},
+		{"if-none-match", http.MethodGet, "/", map[string]string{headerIfNoneMatch: `"x", W/` + etag}, 304, etag, ""},
+		{"if-none-match star", http.MethodHead, "/", map[string]string{headerIfNoneMatch: "*"}, 304, etag, ""},
+		{"if-none-match mismatch", http.MethodGet, "/", map[string]string{headerIfNoneMatch: `"x"`}, 200, etag, "hello"},
+		{"handler etag", http.MethodGet, "/tagged", map[string]string{headerIfNoneMatch: `"v2"`}, 304, `"v2"`, ""},
+		{"if-modified-since", http.MethodGet, "/tagged",
+			map[string]string{headerIfModifiedSince: lastModified.Add(time.Minute).Format(http.TimeFormat)}, 304, `"v2"`, ""},
+		{"modified since", http.MethodGet, "/tagged",
+			map[string]string{headerIfModifiedSince: lastModified.Add(-time.Minute).Format(http.TimeFormat)}, 200, `"v2"`, "hello"},
+		{"not found", http.MethodGet, "/missing", nil, 404, "", "missing"},
+		{"post", http.MethodPost, "/", nil, 200, "", "hello"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			app.Handler().ServeHTTP(rec, req)
+			if rec.Code != tt.status || rec.Header().Get(headerETag) != tt.etag {
+				t.Errorf("got %d %q, want %d %q", rec.Code, rec.Header().Get(headerETag), tt.status, tt.etag)
+			}
+			if tt.method == http.MethodGet && rec.Body.String() != tt.body {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.body)
+			}
+		})
+	}
+}
+
+func TestETag_Weak(t *testing.T) {
+	app := bytego.New()
+	app.Use(New(Config{Weak: true}))
+	app.GET("/", func(c *bytego.Ctx) error {
+		return c.String(200, "hello")
+	})
+	rec := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got := rec.Header().Get(headerETag); got != "W/"+generate([]byte("hello"), false) {
+		t.Errorf("ETag = %q", got)
+	}
+}
+
+func TestETag_Empty(t *testing.T) {
+	app := bytego.New()
+	app.Use(New())
+	app.GET("/", func(c *bytego.Ctx) error {
+		return nil
+	})
+	for path, status := range map[string]int{"/": http.StatusOK, "/missing": http.StatusNotFound} {
+		rec := httptest.NewRecorder()
+		app.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != status {
+			t.Errorf("%s: status = %d, want %d", path, rec.Code, status)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package jwt
+
+import (
+	"time"
+
+	"github.com/gostack-labs/bytego"
+)
+
+type Config struct {
+	// Skipper skips the middleware for a request when it returns true.
+	//
+	// Optional. Default value nil.
+	Skipper func(c *bytego.Ctx) bool
+
+	// SigningKey verifies tokens without a matching "kid": a []byte secret
+	// for HS256/384/512, an *rsa.PublicKey for RS and PS algorithms, an
+	// *ecdsa.PublicKey for ES algorithms or an ed25519.PublicKey for EdDSA.
+	// Private keys are accepted for their public part.
+	SigningKey interface{}
+
+	// SigningKeys holds keys by their "kid" header value.
+	SigningKeys map[string]interface{}
+
+	// KeySet is a JSON Web Key Set, e.g. from LoadKeySet.
+	KeySet *KeySet
+
+	// KeyFunc returns the key for a parsed but unverified token and replaces
+	// the other key options, e.g. to fetch keys from a remote JWKS endpoint.
+	KeyFunc func(t *Token) (interface{}, error)
+
+	// Algorithms restricts the accepted "alg" header values. The key type
+	// always has to match the algorithm.
+	//
+	// Optional. Default value nil.
+	Algorithms []string
+
+	// TokenLookup is a comma separated list of "<source>:<name>" places the
+	// token is looked up in, in order. Sources are header, query and cookie.
+	// Default: "header:Authorization"
+	TokenLookup string
+
+	// AuthScheme prefixes the token in the Authorization header.
+	// Default: "Bearer"
+	AuthScheme string
+
+	// Issuer, when set, must equal the "iss" claim.
+	//
+	// Optional. Default value "".
+	Issuer string
+
+	// Audience, when set, must be in the "aud" claim.
+	//
+	// Optional. Default value "".
+	Audience string
+
+	// Leeway allows for clock skew when checking "exp", "nbf" and "iat".
+	//
+	// Optional. Default value 0.
+	Leeway time.Duration
+
+	// ContextKey is the Ctx key the verified *Token is stored under.
+	// Default: "user"
+	ContextKey string
+}
+
+var DefaultConfig = Config{
+	TokenLookup: "header:" + headerAuthorization,
+	AuthScheme:  "Bearer",
+	ContextKey:  "user",
+}
+
+func configDefault(config ...Config) Config {
+	if len(config) == 0 {
+		panic("jwt: a signing key is required")
+	}
+	cfg := config[0]
+	if cfg.SigningKey == nil && len(cfg.SigningKeys) == 0 && cfg.KeySet == nil && cfg.KeyFunc == nil {
+		panic("jwt: a signing key is required")
+	}
+	if cfg.TokenLookup == "" {
+		cfg.TokenLookup = DefaultConfig.TokenLookup
+	}
+	if cfg.AuthScheme == "" {
+		cfg.AuthScheme = DefaultConfig.AuthScheme
+	}
+	if cfg.ContextKey == "" {
+		cfg.ContextKey = DefaultConfig.ContextKey
+	}
+	return cfg
+}
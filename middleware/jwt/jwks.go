@@ -0,0 +1,158 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+
+	"github.com/gostack-labs/bytego"
+)
+
+// KeySet is a JSON Web Key Set (RFC 7517) of verification keys.
+type KeySet struct {
+	keys map[string]interface{}
+	raw  []byte
+}
+
+type rawKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	K   string `json:"k,omitempty"`
+}
+
+// LoadKeySet reads a key set from a JSON file.
+func LoadKeySet(path string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseKeySet(data)
+}
+
+// ParseKeySet parses a JSON key set of RSA, EC, OKP (Ed25519) and oct keys.
+// Keys used for encryption are skipped.
+func ParseKeySet(data []byte) (*KeySet, error) {
+	var set struct {
+		Keys []rawKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("jwt: invalid key set: %w", err)
+	}
+	ks := &KeySet{keys: make(map[string]interface{}, len(set.Keys))}
+	public := []rawKey{}
+	for _, k := range set.Keys {
+		if k.Use == "enc" {
+			continue
+		}
+		key, err := k.parse()
+		if err != nil {
+			return nil, fmt.Errorf("jwt: key %q: %w", k.Kid, err)
+		}
+		ks.keys[k.Kid] = key
+		if k.Kty != "oct" {
+			k.K = ""
+			public = append(public, k)
+		}
+	}
+	raw, err := json.Marshal(map[string]interface{}{"keys": public})
+	if err != nil {
+		return nil, err
+	}
+	ks.raw = raw
+	return ks, nil
+}
+
+// Key returns the key with the given id.
+func (ks *KeySet) Key(kid string) (interface{}, bool) {
+	key, ok := ks.keys[kid]
+	return key, ok
+}
+
+// Handler serves the public keys of the set as JSON, leaving out the
+// symmetric ones, e.g. at "/.well-known/jwks.json".
+func (ks *KeySet) Handler() bytego.HandlerFunc {
+	return func(c *bytego.Ctx) error {
+		return c.Blob(http.StatusOK, "application/jwk-set+json", ks.raw)
+	}
+}
+
+func (k rawKey) parse() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 || e.Int64() < 3 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	case "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil || len(secret) == 0 {
+			return nil, errors.New("invalid symmetric key")
+		}
+		return secret, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+func decodeInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("invalid key parameter")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,66 @@
+// Package jwt authenticates requests with a JSON Web Token signed with HMAC,
+// RSA, ECDSA or Ed25519 keys, verified with the standard library only.
+package jwt
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gostack-labs/bytego"
+	"github.com/gostack-labs/bytego/internal/extractor"
+)
+
+const (
+	headerAuthorization   = "Authorization"
+	headerWWWAuthenticate = "WWW-Authenticate"
+)
+
+var (
+	// ErrMissingToken is returned when no lookup finds a token.
+	ErrMissingToken = bytego.NewHTTPError(http.StatusUnauthorized, "missing or malformed jwt")
+	// ErrInvalidToken wraps the reason a token was rejected.
+	ErrInvalidToken = bytego.NewHTTPError(http.StatusUnauthorized, "invalid or expired jwt")
+)
+
+// New returns a middleware rejecting requests without a valid token with
+// ErrMissingToken or ErrInvalidToken. The *Token of valid requests is stored
+// on the Ctx under ContextKey. New panics when no key is configured or
+// TokenLookup names an unknown source.
+func New(config ...Config) bytego.HandlerFunc {
+	cfg := configDefault(config...)
+	lookup, err := extractor.Parse(cfg.TokenLookup, cfg.AuthScheme, "header", "query", "cookie")
+	if err != nil {
+		panic("jwt: TokenLookup: " + err.Error())
+	}
+	return func(c *bytego.Ctx) error {
+		if cfg.Skipper != nil && cfg.Skipper(c) {
+			return c.Next()
+		}
+		raw := lookup.Extract(c)
+		if raw == "" {
+			c.SetHeader(headerWWWAuthenticate, cfg.AuthScheme)
+			return ErrMissingToken
+		}
+		token, err := parse(raw, &cfg, time.Now())
+		if err != nil {
+			c.SetHeader(headerWWWAuthenticate, cfg.AuthScheme+` error="invalid_token"`)
+			return ErrInvalidToken.WithErr(err)
+		}
+		c.Set(cfg.ContextKey, token)
+		return c.Next()
+	}
+}
+
+// FromCtx returns the token stored by the middleware under key, "user" by default.
+func FromCtx(c *bytego.Ctx, key ...string) (*Token, bool) {
+	k := DefaultConfig.ContextKey
+	if len(key) > 0 {
+		k = key[0]
+	}
+	v, ok := c.Get(k)
+	if !ok {
+		return nil, false
+	}
+	t, ok := v.(*Token)
+	return t, ok
+}
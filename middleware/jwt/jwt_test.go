@@ -0,0 +1,222 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gostack-labs/bytego"
+)
+
+func sign(t *testing.T, header, claims map[string]interface{}, key interface{}) string {
+	t.Helper()
+	h, _ := json.Marshal(header)
+	c, _ := json.Marshal(claims)
+	input := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+	digest := sha256.Sum256([]byte(input))
+	var sig []byte
+	var err error
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(input))
+		sig = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, k, digest[:])
+		if err == nil {
+			sig = make([]byte, 64)
+			r.FillBytes(sig[:32])
+			s.FillBytes(sig[32:])
+		}
+	case ed25519.PrivateKey:
+		sig = ed25519.Sign(k, []byte(input))
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestParse(t *testing.T) {
+	secret := []byte("secret")
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	now := time.Now().Unix()
+	valid := map[string]interface{}{"sub": "alice", "iss": "bytego", "aud": []string{"api"}, "exp": now + 60}
+	tests := []struct {
+		name   string
+		header map[string]interface{}
+		claims map[string]interface{}
+		key    interface{}
+		cfg    Config
+		err    error
+	}{
+		{"HS256", map[string]interface{}{"alg": "HS256"}, valid, secret, Config{SigningKey: secret}, nil},
+		{"RS256", map[string]interface{}{"alg": "RS256"}, valid, rsaKey, Config{SigningKey: &rsaKey.PublicKey}, nil},
+		{"ES256", map[string]interface{}{"alg": "ES256"}, valid, ecKey, Config{SigningKey: ecKey}, nil},
+		{"EdDSA", map[string]interface{}{"alg": "EdDSA"}, valid, edKey, Config{SigningKey: edKey.Public()}, nil},
+		{"kid", map[string]interface{}{"alg": "HS256", "kid": "a"}, valid, secret, Config{SigningKeys: map[string]interface{}{"a": secret}}, nil},
+		{"unknown kid", map[string]interface{}{"alg": "HS256", "kid": "b"}, valid, secret, Config{SigningKeys: map[string]interface{}{"a": secret}}, ErrKeyNotFound},
+		{"bad signature", map[string]interface{}{"alg": "HS256"}, valid, []byte("other"), Config{SigningKey: secret}, ErrSignatureInvalid},
+		{"none", map[string]interface{}{"alg": "none"}, valid, secret, Config{SigningKey: secret}, ErrAlgorithm},
+		{"key confusion", map[string]interface{}{"alg": "HS256"}, valid, secret, Config{SigningKey: &rsaKey.PublicKey}, ErrAlgorithm},
+		{"disallowed alg", map[string]interface{}{"alg": "HS256"}, valid, secret, Config{SigningKey: secret, Algorithms: []string{"RS256"}}, ErrAlgorithm},
+		{"expired", map[string]interface{}{"alg": "HS256"}, map[string]interface{}{"exp": now - 10}, secret, Config{SigningKey: secret}, ErrTokenExpired},
+		{"leeway", map[string]interface{}{"alg": "HS256"}, map[string]interface{}{"exp": now - 10}, secret, Config{SigningKey: secret, Leeway: time.Minute}, nil},
+		{"not before", map[string]interface{}{"alg": "HS256"}, map[string]interface{}{"nbf": now + 60}, secret, Config{SigningKey: secret}, ErrTokenNotValidYet},
+		{"string exp", map[string]interface{}{"alg": "HS256"}, map[string]interface{}{"exp": "tomorrow"}, secret, Config{SigningKey: secret}, ErrClaimMalformed},
+		{"null nbf", map[string]interface{}{"alg": "HS256"}, map[string]interface{}{"nbf": nil}, secret, Config{SigningKey: secret}, ErrClaimMalformed},
+		{"string iat", map[string]interface{}{"alg": "HS256"}, map[string]interface{}{"iat": "1700000000"}, secret, Config{SigningKey: secret}, ErrClaimMalformed},
+		{"issuer", map[string]interface{}{"alg": "HS256"}, valid, secret, Config{SigningKey: secret, Issuer: "other"}, ErrIssuer},
+		{"audience", map[string]interface{}{"alg": "HS256"}, valid, secret, Config{SigningKey: secret, Audience: "api"}, nil},
+		{"wrong audience", map[string]interface{}{"alg": "HS256"}, valid, secret, Config{SigningKey: secret, Audience: "web"}, ErrAudience},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := Parse(sign(t, tt.header, tt.claims, tt.key), tt.cfg)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if sub, _ := tt.claims["sub"].(string); err == nil && token.Claims.Subject() != sub {
+				t.Errorf("sub = %q", token.Claims.Subject())
+			}
+		})
+	}
+	if _, err := Parse("a.b", Config{SigningKey: secret}); err != ErrTokenMalformed {
+		t.Errorf("err = %v, want ErrTokenMalformed", err)
+	}
+}
+
+func TestJWT(t *testing.T) {
+	secret := []byte("secret")
+	app := bytego.New()
+	app.Use(New(Config{
+		SigningKey:  secret,
+		TokenLookup: "header:Authorization,cookie:jwt",
+		Skipper: func(c *bytego.Ctx) bool {
+			return c.Request.URL.Path == "/public"
+		},
+	}))
+	app.GET("/", func(c *bytego.Ctx) error {
+		token, _ := FromCtx(c)
+		var claims struct {
+			Sub string `json:"sub"`
+		}
+		if err := token.Decode(&claims); err != nil {
+			return err
+		}
+		return c.String(200, claims.Sub)
+	})
+	app.GET("/public", func(c *bytego.Ctx) error {
+		return c.String(200, "public")
+	})
+	token := sign(t, map[string]interface{}{"alg": "HS256"}, map[string]interface{}{"sub": "alice"}, secret)
+	expired := sign(t, map[string]interface{}{"alg": "HS256"}, map[string]interface{}{"exp": 1}, secret)
+	tests := []struct {
+		name      string
+		target    string
+		header    map[string]string
+		status    int
+		challenge string
+	}{
+		{"bearer", "/", map[string]string{headerAuthorization: "Bearer " + token}, 200, ""},
+		{"cookie", "/", map[string]string{"Cookie": "jwt=" + token}, 200, ""},
+		{"skipped", "/public", nil, 200, ""},
+		{"missing", "/", nil, 401, "Bearer"},
+		{"expired", "/", map[string]string{headerAuthorization: "Bearer " + expired}, 401, `Bearer error="invalid_token"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			app.Handler().ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if got := rec.Header().Get(headerWWWAuthenticate); got != tt.challenge {
+				t.Errorf("WWW-Authenticate = %q, want %q", got, tt.challenge)
+			}
+			if tt.target == "/" && tt.status == 200 && rec.Body.String() != "alice" {
+				t.Errorf("body = %q", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestKeySet(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	enc := base64.RawURLEncoding.EncodeToString
+	set := map[string]interface{}{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa", "n": enc(rsaKey.N.Bytes()), "e": enc(big.NewInt(int64(rsaKey.E)).Bytes())},
+		{"kty": "EC", "kid": "ec", "crv": "P-256", "x": enc(ecKey.X.FillBytes(make([]byte, 32))), "y": enc(ecKey.Y.FillBytes(make([]byte, 32)))},
+		{"kty": "oct", "kid": "hmac", "k": enc([]byte("secret"))},
+	}}
+	data, _ := json.Marshal(set)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ks, err := LoadKeySet(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := Config{KeySet: ks}
+	for kid, key := range map[string]interface{}{"rsa": rsaKey, "ec": ecKey, "hmac": []byte("secret")} {
+		alg := map[string]string{"rsa": "RS256", "ec": "ES256", "hmac": "HS256"}[kid]
+		raw := sign(t, map[string]interface{}{"alg": alg, "kid": kid}, map[string]interface{}{"sub": kid}, key)
+		if _, err := Parse(raw, cfg); err != nil {
+			t.Errorf("%s: %v", kid, err)
+		}
+	}
+
+	app := bytego.New()
+	app.GET("/jwks.json", ks.Handler())
+	rec := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/jwks.json", nil))
+	if rec.Code != 200 || strings.Contains(rec.Body.String(), "oct") || !strings.Contains(rec.Body.String(), `"kid":"ec"`) {
+		t.Errorf("jwks = %d %s", rec.Code, rec.Body.String())
+	}
+	served, err := ParseKeySet(rec.Body.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := served.Key("rsa"); !ok {
+		t.Error("served key set misses rsa key")
+	}
+	if _, err := ParseKeySet([]byte(`{"keys":[{"kty":"EC","kid":"x","crv":"P-256","x":"AQ","y":"AQ"}]}`)); err == nil {
+		t.Error("expected error for point not on curve")
+	}
+}
+
+func TestNew_NoKey(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic without a key")
+		}
+	}()
+	New(Config{})
+}
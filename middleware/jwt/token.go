@@ -0,0 +1,316 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	_ "crypto/sha256" // register hashes
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+)
+
+var (
+	ErrTokenMalformed   = errors.New("jwt: malformed token")
+	ErrClaimMalformed   = errors.New("jwt: exp, nbf or iat claim is not a number")
+	ErrAlgorithm        = errors.New("jwt: unexpected signing algorithm")
+	ErrKeyNotFound      = errors.New("jwt: no key for token")
+	ErrSignatureInvalid = errors.New("jwt: invalid signature")
+	ErrTokenExpired     = errors.New("jwt: token is expired")
+	ErrTokenNotValidYet = errors.New("jwt: token is not valid yet")
+	ErrTokenUsedEarly   = errors.New("jwt: token issued in the future")
+	ErrIssuer           = errors.New("jwt: unexpected issuer")
+	ErrAudience         = errors.New("jwt: unexpected audience")
+)
+
+// Token is a parsed JSON Web Token (RFC 7519).
+type Token struct {
+	Raw    string
+	Header map[string]interface{}
+	Claims Claims
+	// Method is the "alg" header value.
+	Method string
+
+	payload []byte
+}
+
+// Decode unmarshals the claims into v, e.g. a struct of custom claims.
+func (t *Token) Decode(v interface{}) error {
+	return json.Unmarshal(t.payload, v)
+}
+
+// Kid returns the "kid" header value.
+func (t *Token) Kid() string {
+	kid, _ := t.Header["kid"].(string)
+	return kid
+}
+
+// Claims are the decoded claims of a token, numbers being float64.
+type Claims map[string]interface{}
+
+func (c Claims) Subject() string {
+	s, _ := c["sub"].(string)
+	return s
+}
+
+func (c Claims) Issuer() string {
+	s, _ := c["iss"].(string)
+	return s
+}
+
+// Audience returns the "aud" claim, which may be a string or a list.
+func (c Claims) Audience() []string {
+	switch aud := c["aud"].(type) {
+	case string:
+		return []string{aud}
+	case []interface{}:
+		list := make([]string, 0, len(aud))
+		for _, a := range aud {
+			if s, ok := a.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+func (c Claims) ExpiresAt() (time.Time, bool) {
+	return c.numericDate("exp")
+}
+
+func (c Claims) NotBefore() (time.Time, bool) {
+	return c.numericDate("nbf")
+}
+
+func (c Claims) IssuedAt() (time.Time, bool) {
+	return c.numericDate("iat")
+}
+
+func (c Claims) numericDate(name string) (time.Time, bool) {
+	f, ok := c[name].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	sec, frac := math.Modf(f)
+	return time.Unix(int64(sec), int64(frac*1e9)), true
+}
+
+// Parse parses and verifies a compact serialized token with the keys and
+// claim checks of config.
+func Parse(raw string, config ...Config) (*Token, error) {
+	cfg := configDefault(config...)
+	return parse(raw, &cfg, time.Now())
+}
+
+func parse(raw string, cfg *Config, now time.Time) (*Token, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, ErrTokenMalformed
+	}
+	t := &Token{Raw: raw}
+	header, err := decodeSegment(parts[0])
+	if err != nil || json.Unmarshal(header, &t.Header) != nil {
+		return nil, ErrTokenMalformed
+	}
+	if t.payload, err = decodeSegment(parts[1]); err != nil || json.Unmarshal(t.payload, &t.Claims) != nil {
+		return nil, ErrTokenMalformed
+	}
+	signature, err := decodeSegment(parts[2])
+	if err != nil {
+		return nil, ErrTokenMalformed
+	}
+	t.Method, _ = t.Header["alg"].(string)
+	if !allowed(t.Method, cfg.Algorithms) {
+		return nil, ErrAlgorithm
+	}
+	key, err := lookupKey(t, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := verify(t.Method, key, raw[:len(parts[0])+1+len(parts[1])], signature); err != nil {
+		return nil, err
+	}
+	if err := validateClaims(t.Claims, cfg, now); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func decodeSegment(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+func allowed(alg string, algorithms []string) bool {
+	if alg == "" || alg == "none" {
+		return false
+	}
+	if len(algorithms) == 0 {
+		return true
+	}
+	for _, a := range algorithms {
+		if a == alg {
+			return true
+		}
+	}
+	return false
+}
+
+func lookupKey(t *Token, cfg *Config) (interface{}, error) {
+	if cfg.KeyFunc != nil {
+		key, err := cfg.KeyFunc(t)
+		if err == nil && key == nil {
+			err = ErrKeyNotFound
+		}
+		return key, err
+	}
+	if kid := t.Kid(); kid != "" {
+		if key, ok := cfg.SigningKeys[kid]; ok {
+			return key, nil
+		}
+		if cfg.KeySet != nil {
+			if key, ok := cfg.KeySet.Key(kid); ok {
+				return key, nil
+			}
+		}
+	}
+	if cfg.SigningKey != nil {
+		return cfg.SigningKey, nil
+	}
+	if cfg.KeySet != nil && t.Kid() == "" && len(cfg.KeySet.keys) == 1 {
+		for _, key := range cfg.KeySet.keys {
+			return key, nil
+		}
+	}
+	return nil, ErrKeyNotFound
+}
+
+var hashes = map[string]crypto.Hash{"256": crypto.SHA256, "384": crypto.SHA384, "512": crypto.SHA512}
+
+// verify checks signature over input with key, which must be of the type the
+// algorithm requires.
+func verify(alg string, key interface{}, input string, signature []byte) error {
+	if alg == "EdDSA" {
+		pub, ok := publicKey(key).(ed25519.PublicKey)
+		if !ok {
+			return ErrAlgorithm
+		}
+		if !ed25519.Verify(pub, []byte(input), signature) {
+			return ErrSignatureInvalid
+		}
+		return nil
+	}
+	if len(alg) != 5 {
+		return ErrAlgorithm
+	}
+	hash, ok := hashes[alg[2:]]
+	if !ok {
+		return ErrAlgorithm
+	}
+	switch alg[:2] {
+	case "HS":
+		secret, ok := key.([]byte)
+		if !ok {
+			return ErrAlgorithm
+		}
+		mac := hmac.New(hash.New, secret)
+		mac.Write([]byte(input))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return ErrSignatureInvalid
+		}
+		return nil
+	}
+	h := hash.New()
+	h.Write([]byte(input))
+	digest := h.Sum(nil)
+	switch alg[:2] {
+	case "RS", "PS":
+		pub, ok := publicKey(key).(*rsa.PublicKey)
+		if !ok {
+			return ErrAlgorithm
+		}
+		var err error
+		if alg[0] == 'R' {
+			err = rsa.VerifyPKCS1v15(pub, hash, digest, signature)
+		} else {
+			err = rsa.VerifyPSS(pub, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+		if err != nil {
+			return ErrSignatureInvalid
+		}
+		return nil
+	case "ES":
+		pub, ok := publicKey(key).(*ecdsa.PublicKey)
+		if !ok || pub.Curve != curves[alg] {
+			return ErrAlgorithm
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return ErrSignatureInvalid
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return ErrSignatureInvalid
+		}
+		return nil
+	}
+	return ErrAlgorithm
+}
+
+var curves = map[string]elliptic.Curve{
+	"ES256": elliptic.P256(),
+	"ES384": elliptic.P384(),
+	"ES512": elliptic.P521(),
+}
+
+func publicKey(key interface{}) interface{} {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return &k.PublicKey
+	case *ecdsa.PrivateKey:
+		return &k.PublicKey
+	case ed25519.PrivateKey:
+		return k.Public()
+	}
+	return key
+}
+
+func validateClaims(claims Claims, cfg *Config, now time.Time) error {
+	for _, name := range []string{"exp", "nbf", "iat"} {
+		if v, ok := claims[name]; ok {
+			if _, ok := v.(float64); !ok {
+				return ErrClaimMalformed
+			}
+		}
+	}
+	if exp, ok := claims.ExpiresAt(); ok && !now.Before(exp.Add(cfg.Leeway)) {
+		return ErrTokenExpired
+	}
+	if nbf, ok := claims.NotBefore(); ok && now.Add(cfg.Leeway).Before(nbf) {
+		return ErrTokenNotValidYet
+	}
+	if iat, ok := claims.IssuedAt(); ok && now.Add(cfg.Leeway).Before(iat) {
+		return ErrTokenUsedEarly
+	}
+	if cfg.Issuer != "" && claims.Issuer() != cfg.Issuer {
+		return ErrIssuer
+	}
+	if cfg.Audience != "" {
+		for _, aud := range claims.Audience() {
+			if aud == cfg.Audience {
+				return nil
+			}
+		}
+		return ErrAudience
+	}
+	return nil
+}
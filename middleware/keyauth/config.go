@@ -0,0 +1,57 @@
+package keyauth
+
+import (
+	"github.com/gostack-labs/bytego"
+)
+
+type Config struct {
+	// Skipper skips the middleware for a request when it returns true.
+	//
+	// Optional. Default value nil.
+	Skipper func(c *bytego.Ctx) bool
+
+	// KeyLookup is a comma separated list of "<source>:<name>" places the key
+	// is looked up in, in order. Sources are header, query and cookie.
+	// Default: "header:Authorization"
+	KeyLookup string
+
+	// AuthScheme prefixes the key in the Authorization header.
+	// Default: "Bearer"
+	AuthScheme string
+
+	// Validator reports whether key is valid. An error is passed on to the
+	// error handler as the cause of bytego.ErrUnauthorized.
+	//
+	// Required.
+	Validator func(c *bytego.Ctx, key string) (bool, error)
+
+	// ContextKey is the Ctx key the valid key is stored under.
+	// Default: "key"
+	ContextKey string
+}
+
+var DefaultConfig = Config{
+	KeyLookup:  "header:" + headerAuthorization,
+	AuthScheme: "Bearer",
+	ContextKey: "key",
+}
+
+func configDefault(config ...Config) Config {
+	if len(config) == 0 {
+		panic("keyauth: Validator is required")
+	}
+	cfg := config[0]
+	if cfg.Validator == nil {
+		panic("keyauth: Validator is required")
+	}
+	if cfg.KeyLookup == "" {
+		cfg.KeyLookup = DefaultConfig.KeyLookup
+	}
+	if cfg.AuthScheme == "" {
+		cfg.AuthScheme = DefaultConfig.AuthScheme
+	}
+	if cfg.ContextKey == "" {
+		cfg.ContextKey = DefaultConfig.ContextKey
+	}
+	return cfg
+}
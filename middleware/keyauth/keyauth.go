@@ -0,0 +1,50 @@
+// Package keyauth authenticates requests with an API key or bearer token
+// taken from a header, the query string or a cookie.
+package keyauth
+
+import (
+	"net/http"
+
+	"github.com/gostack-labs/bytego"
+	"github.com/gostack-labs/bytego/internal/extractor"
+)
+
+const (
+	headerAuthorization   = "Authorization"
+	headerWWWAuthenticate = "WWW-Authenticate"
+)
+
+// ErrMissingKey is returned when no lookup finds a key.
+var ErrMissingKey = bytego.NewHTTPError(http.StatusUnauthorized, "missing or malformed API key")
+
+// New returns a middleware rejecting requests without a valid key with
+// ErrMissingKey or bytego.ErrUnauthorized. The key of valid requests is stored
+// on the Ctx under ContextKey. New panics when Validator is nil or KeyLookup
+// names an unknown source.
+func New(config ...Config) bytego.HandlerFunc {
+	cfg := configDefault(config...)
+	lookup, err := extractor.Parse(cfg.KeyLookup, cfg.AuthScheme, "header", "query", "cookie")
+	if err != nil {
+		panic("keyauth: KeyLookup: " + err.Error())
+	}
+	return func(c *bytego.Ctx) error {
+		if cfg.Skipper != nil && cfg.Skipper(c) {
+			return c.Next()
+		}
+		key := lookup.Extract(c)
+		if key == "" {
+			c.SetHeader(headerWWWAuthenticate, cfg.AuthScheme)
+			return ErrMissingKey
+		}
+		ok, err := cfg.Validator(c, key)
+		if err != nil {
+			return bytego.ErrUnauthorized.WithErr(err)
+		}
+		if !ok {
+			c.SetHeader(headerWWWAuthenticate, cfg.AuthScheme)
+			return bytego.ErrUnauthorized
+		}
+		c.Set(cfg.ContextKey, key)
+		return c.Next()
+	}
+}
@@ -0,0 +1,74 @@
+package keyauth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gostack-labs/bytego"
+)
+
+func TestKeyAuth(t *testing.T) {
+	app := bytego.New()
+	app.Use(New(Config{
+		KeyLookup: "header:Authorization,header:X-API-Key,query:api_key,cookie:api_key",
+		Validator: func(c *bytego.Ctx, key string) (bool, error) {
+			if key == "broken" {
+				return false, errors.New("store unavailable")
+			}
+			return key == "valid-key", nil
+		},
+	}))
+	app.GET("/", func(c *bytego.Ctx) error {
+		key, _ := c.Get("key")
+		return c.String(200, key.(string))
+	})
+	tests := []struct {
+		name   string
+		target string
+		header map[string]string
+		status int
+	}{
+		{"bearer", "/", map[string]string{headerAuthorization: "bearer valid-key"}, 200},
+		{"header", "/", map[string]string{"X-API-Key": "valid-key"}, 200},
+		{"query", "/?api_key=valid-key", nil, 200},
+		{"cookie", "/", map[string]string{"Cookie": "api_key=valid-key"}, 200},
+		{"wrong scheme", "/", map[string]string{headerAuthorization: "Basic valid-key"}, 401},
+		{"invalid", "/?api_key=other", nil, 401},
+		{"validator error", "/?api_key=broken", nil, 401},
+		{"missing", "/", nil, 401},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			app.Handler().ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if tt.status == 200 && rec.Body.String() != "valid-key" {
+				t.Errorf("body = %q", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestKeyAuth_InvalidConfig(t *testing.T) {
+	for _, cfg := range []Config{
+		{},
+		{KeyLookup: "form:key", Validator: func(*bytego.Ctx, string) (bool, error) { return true, nil }},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("New(%+v) did not panic", cfg)
+				}
+			}()
+			New(cfg)
+		}()
+	}
+}
@@ -56,11 +56,7 @@ func New(config ...Config) bytego.HandlerFunc {
 			case "pid":
 				return buf.WriteString(strconv.Itoa(pid))
 			case "request_id":
-				id := c.Header(bytego.HeaderXRequestID)
-				if id == "" {
-					id = c.Response.Header().Get(bytego.HeaderXRequestID)
-				}
-				return buf.WriteString(id)
+				return buf.WriteString(c.RequestID())
 			case "remote_ip":
 				return buf.WriteString(c.ClientIP())
 			case "host":
@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"time"
+
+	"github.com/gostack-labs/bytego"
+)
+
+type Config struct {
+	// Skipper skips the middleware for a request when it returns true.
+	//
+	// Optional. Default value nil.
+	Skipper func(c *bytego.Ctx) bool
+
+	// Algorithm is the limiting algorithm.
+	// Default: SlidingWindow
+	Algorithm Algorithm
+
+	// Max is the number of requests allowed per Period.
+	// Default: 60
+	Max int
+
+	// Period is the duration Max applies to.
+	// Default: 1 minute
+	Period time.Duration
+
+	// Burst is the bucket capacity of the token bucket algorithm.
+	// Default: Max
+	Burst int
+
+	// KeyGenerator returns the key requests are counted under. Keys must be
+	// distinct between middlewares sharing a Store.
+	// Default: Ctx.ClientIP
+	KeyGenerator func(c *bytego.Ctx) string
+
+	// Store counts the requests.
+	// Default: NewMemoryStore()
+	Store Store
+}
+
+var DefaultConfig = Config{
+	Algorithm: SlidingWindow,
+	Max:       60,
+	Period:    time.Minute,
+	KeyGenerator: func(c *bytego.Ctx) string {
+		return c.ClientIP()
+	},
+}
+
+func configDefault(config ...Config) Config {
+	cfg := DefaultConfig
+	if len(config) > 0 {
+		cfg = config[0]
+		if cfg.Max <= 0 {
+			cfg.Max = DefaultConfig.Max
+		}
+		if cfg.Period <= 0 {
+			cfg.Period = DefaultConfig.Period
+		}
+		if cfg.KeyGenerator == nil {
+			cfg.KeyGenerator = DefaultConfig.KeyGenerator
+		}
+	}
+	if cfg.Burst <= 0 {
+		cfg.Burst = cfg.Max
+	}
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryStore()
+	}
+	return cfg
+}
+
+// KeyByHeader returns a KeyGenerator using the value of the request header
+// name, such as an API key, and the client IP when the header is missing.
+func KeyByHeader(name string) func(c *bytego.Ctx) string {
+	return func(c *bytego.Ctx) string {
+		if v := c.Header(name); v != "" {
+			return name + ":" + v
+		}
+		return c.ClientIP()
+	}
+}
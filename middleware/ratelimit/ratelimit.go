@@ -0,0 +1,101 @@
+// Package ratelimit limits the request rate of clients with the token bucket
+// or the sliding window algorithm.
+package ratelimit
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gostack-labs/bytego"
+)
+
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+	HeaderRetryAfter         = "Retry-After"
+)
+
+// ErrTooManyRequests is returned to the error handler when a client exceeds its limit.
+var ErrTooManyRequests = bytego.NewHTTPError(http.StatusTooManyRequests)
+
+type Algorithm uint8
+
+const (
+	// SlidingWindow allows Max requests in any Period, weighting the count of
+	// the previous fixed window by how much of it the sliding window overlaps.
+	SlidingWindow Algorithm = iota
+	// TokenBucket allows bursts of up to Burst requests and refills Max tokens per Period.
+	TokenBucket
+)
+
+func (a Algorithm) String() string {
+	switch a {
+	case SlidingWindow:
+		return "sliding-window"
+	case TokenBucket:
+		return "token-bucket"
+	}
+	return "unknown"
+}
+
+// Limit is the rate a Store enforces for a key.
+type Limit struct {
+	Algorithm Algorithm
+	Max       int
+	Period    time.Duration
+	Burst     int
+}
+
+// Result is the outcome of a request counted by a Store.
+type Result struct {
+	Allowed bool
+	// Limit is the request quota, Max or Burst.
+	Limit int
+	// Remaining is the number of requests left in the quota.
+	Remaining int
+	// Reset is the time until the quota is fully available again.
+	Reset time.Duration
+	// RetryAfter is the time until a denied request would be allowed.
+	RetryAfter time.Duration
+}
+
+// Store counts requests. Implementations backed by external systems must
+// update a key atomically, e.g. with a server-side script.
+type Store interface {
+	Take(key string, limit Limit, now time.Time) (Result, error)
+}
+
+// New returns a middleware answering requests beyond the limit with
+// ErrTooManyRequests. Every response carries the RateLimit-Limit,
+// RateLimit-Remaining and RateLimit-Reset headers, denied ones Retry-After.
+func New(config ...Config) bytego.HandlerFunc {
+	cfg := configDefault(config...)
+	limit := Limit{Algorithm: cfg.Algorithm, Max: cfg.Max, Period: cfg.Period, Burst: cfg.Burst}
+	return func(c *bytego.Ctx) error {
+		if cfg.Skipper != nil && cfg.Skipper(c) {
+			return c.Next()
+		}
+		res, err := cfg.Store.Take(cfg.KeyGenerator(c), limit, time.Now())
+		if err != nil {
+			return err
+		}
+		c.SetHeader(HeaderRateLimitLimit, strconv.Itoa(res.Limit))
+		c.SetHeader(HeaderRateLimitRemaining, strconv.Itoa(res.Remaining))
+		c.SetHeader(HeaderRateLimitReset, strconv.Itoa(seconds(res.Reset)))
+		if !res.Allowed {
+			c.SetHeader(HeaderRetryAfter, strconv.Itoa(seconds(res.RetryAfter)))
+			return ErrTooManyRequests
+		}
+		return c.Next()
+	}
+}
+
+// seconds rounds d up to whole seconds.
+func seconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int((d + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gostack-labs/bytego"
+)
+
+func TestTokenBucket(t *testing.T) {
+	s := NewMemoryStore()
+	limit := Limit{Algorithm: TokenBucket, Max: 1, Period: time.Second, Burst: 3}
+	now := time.Unix(1000, 0)
+	for i := 0; i < 3; i++ {
+		if res, _ := s.Take("k", limit, now); !res.Allowed || res.Remaining != 2-i {
+			t.Fatalf("request %d: %+v", i, res)
+		}
+	}
+	res, _ := s.Take("k", limit, now)
+	if res.Allowed || res.RetryAfter != time.Second || res.Reset != 3*time.Second {
+		t.Fatalf("over burst: %+v", res)
+	}
+	if res, _ := s.Take("k", limit, now.Add(1500*time.Millisecond)); !res.Allowed || res.Remaining != 0 {
+		t.Fatalf("after refill: %+v", res)
+	}
+	if res, _ := s.Take("other", limit, now); !res.Allowed {
+		t.Fatalf("other key: %+v", res)
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	s := NewMemoryStore()
+	limit := Limit{Algorithm: SlidingWindow, Max: 4, Period: 10 * time.Second}
+	start := time.Unix(1000, 0)
+	for i := 0; i < 4; i++ {
+		if res, _ := s.Take("k", limit, start.Add(time.Second)); !res.Allowed || res.Remaining != 3-i {
+			t.Fatalf("request %d: %+v", i, res)
+		}
+	}
+	res, _ := s.Take("k", limit, start.Add(time.Second))
+	if res.Allowed || res.RetryAfter != 11500*time.Millisecond {
+		t.Fatalf("over limit: %+v", res)
+	}
+	// 25% into the next window the previous 4 requests weigh 3
+	if res, _ := s.Take("k", limit, start.Add(12500*time.Millisecond)); !res.Allowed || res.Remaining != 0 {
+		t.Fatalf("next window: %+v", res)
+	}
+	if res, _ := s.Take("k", limit, start.Add(12500*time.Millisecond)); res.Allowed {
+		t.Fatalf("next window over limit: %+v", res)
+	}
+	if res, _ := s.Take("k", limit, start.Add(40*time.Second)); !res.Allowed || res.Remaining != 3 {
+		t.Fatalf("idle: %+v", res)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	app := bytego.New()
+	app.Use(New(Config{Max: 2, Period: time.Minute, KeyGenerator: KeyByHeader("X-API-Key")}))
+	app.GET("/", func(c *bytego.Ctx) error {
+		return c.String(200, "ok")
+	})
+	get := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-API-Key", key)
+		rec := httptest.NewRecorder()
+		app.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+	for i, want := range []int{200, 200, 429} {
+		rec := get("a")
+		if rec.Code != want {
+			t.Fatalf("request %d: status = %d, want %d", i, rec.Code, want)
+		}
+		if rec.Header().Get(HeaderRateLimitLimit) != "2" || rec.Header().Get(HeaderRateLimitReset) == "" {
+			t.Errorf("request %d: headers = %v", i, rec.Header())
+		}
+		if want == 429 && rec.Header().Get(HeaderRetryAfter) == "" {
+			t.Error("missing Retry-After")
+		}
+	}
+	if rec := get("b"); rec.Code != 200 || rec.Header().Get(HeaderRateLimitRemaining) != "1" {
+		t.Errorf("other key: %d %v", rec.Code, rec.Header())
+	}
+}
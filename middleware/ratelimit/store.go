@@ -0,0 +1,144 @@
+package ratelimit
+
+import (
+	"hash/fnv"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	shardCount    = 32
+	sweepInterval = 1024
+)
+
+// MemoryStore is an in-memory Store split into shards with their own lock.
+// Idle keys are dropped once their quota is fully available again.
+type MemoryStore struct {
+	shards [shardCount]shard
+}
+
+type shard struct {
+	mu      sync.Mutex
+	entries map[string]*state
+	calls   int
+}
+
+type state struct {
+	// token bucket
+	tokens float64
+	last   time.Time
+	// sliding window
+	windowStart time.Time
+	prev, curr  int
+
+	expires time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	s := &MemoryStore{}
+	for i := range s.shards {
+		s.shards[i].entries = make(map[string]*state)
+	}
+	return s
+}
+
+func (s *MemoryStore) Take(key string, limit Limit, now time.Time) (Result, error) {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	sh := &s.shards[h.Sum32()%shardCount]
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if sh.calls++; sh.calls%sweepInterval == 0 {
+		for k, st := range sh.entries {
+			if now.After(st.expires) {
+				delete(sh.entries, k)
+			}
+		}
+	}
+	st, ok := sh.entries[key]
+	if !ok || now.After(st.expires) {
+		st = &state{}
+		sh.entries[key] = st
+	}
+	var res Result
+	if limit.Algorithm == TokenBucket {
+		res = takeToken(st, limit, now)
+	} else {
+		res = takeWindow(st, limit, now)
+	}
+	st.expires = now.Add(res.Reset)
+	return res, nil
+}
+
+func takeToken(st *state, limit Limit, now time.Time) Result {
+	burst := float64(limit.Burst)
+	perToken := limit.Period / time.Duration(limit.Max)
+	if st.last.IsZero() {
+		st.tokens = burst
+	} else if elapsed := now.Sub(st.last); elapsed > 0 {
+		st.tokens = math.Min(burst, st.tokens+float64(elapsed)/float64(perToken))
+	}
+	st.last = now
+	res := Result{Limit: limit.Burst}
+	if st.tokens >= 1 {
+		st.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = time.Duration((1 - st.tokens) * float64(perToken))
+	}
+	res.Remaining = int(st.tokens)
+	res.Reset = time.Duration((burst - st.tokens) * float64(perToken))
+	return res
+}
+
+func takeWindow(st *state, limit Limit, now time.Time) Result {
+	period := limit.Period
+	start := now.Truncate(period)
+	switch {
+	case st.windowStart.Equal(start):
+	case st.windowStart.Add(period).Equal(start):
+		st.prev, st.curr = st.curr, 0
+	default:
+		st.prev, st.curr = 0, 0
+	}
+	st.windowStart = start
+
+	elapsed := now.Sub(start)
+	weight := 1 - float64(elapsed)/float64(period)
+	max := float64(limit.Max)
+	count := float64(st.prev)*weight + float64(st.curr)
+	res := Result{Limit: limit.Max}
+	if count+1 <= max {
+		st.curr++
+		count++
+		res.Allowed = true
+	} else {
+		res.RetryAfter = windowRetryAfter(st, max, elapsed, period)
+	}
+	res.Remaining = int(max - count)
+	if res.Remaining < 0 {
+		res.Remaining = 0
+	}
+	// the current window stops weighing on the count one period after it ends
+	res.Reset = 2*period - elapsed
+	if st.curr == 0 {
+		res.Reset = period - elapsed
+	}
+	return res
+}
+
+// windowRetryAfter returns the time until the weighted count leaves room for
+// one more request.
+func windowRetryAfter(st *state, max float64, elapsed, period time.Duration) time.Duration {
+	p := float64(period)
+	if float64(st.curr)+1 <= max {
+		// room appears while the previous window slides out
+		t := p*(1-(max-1-float64(st.curr))/float64(st.prev)) - float64(elapsed)
+		return time.Duration(math.Max(t, 0))
+	}
+	// the current window becomes the previous one and has to slide out partly
+	t := p*(1-(max-1)/float64(st.curr)) + float64(period-elapsed)
+	return time.Duration(t)
+}
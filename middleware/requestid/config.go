@@ -0,0 +1,45 @@
+package requestid
+
+import (
+	"github.com/gostack-labs/bytego"
+)
+
+type Config struct {
+	// Skipper skips the middleware for a request when it returns true.
+	//
+	// Optional. Default value nil.
+	Skipper func(c *bytego.Ctx) bool
+
+	// Header is the request and response header carrying the ID.
+	// Default: bytego.HeaderXRequestID
+	Header string
+
+	// Generator returns a new ID, e.g. UUID, ULID or a custom function.
+	// Default: UUID
+	Generator func() string
+
+	// DisableReuse always generates a new ID instead of reusing a valid one
+	// sent by the client.
+	//
+	// Optional. Default value false.
+	DisableReuse bool
+}
+
+var DefaultConfig = Config{
+	Header:    bytego.HeaderXRequestID,
+	Generator: UUID,
+}
+
+func configDefault(config ...Config) Config {
+	if len(config) == 0 {
+		return DefaultConfig
+	}
+	cfg := config[0]
+	if cfg.Header == "" {
+		cfg.Header = DefaultConfig.Header
+	}
+	if cfg.Generator == nil {
+		cfg.Generator = DefaultConfig.Generator
+	}
+	return cfg
+}
@@ -0,0 +1,91 @@
+// Package requestid assigns every request an ID that is echoed in the
+// response, returned by Ctx.RequestID and prefixed to Ctx.Logger messages.
+package requestid
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"time"
+
+	"github.com/gostack-labs/bytego"
+)
+
+const maxIDLength = 128
+
+// New returns a middleware reusing the ID sent in the request header when it
+// is valid, or generating one. The ID is set on the request header, so that it
+// propagates to upstream calls built from it, and on the response header.
+func New(config ...Config) bytego.HandlerFunc {
+	cfg := configDefault(config...)
+	return func(c *bytego.Ctx) error {
+		if cfg.Skipper != nil && cfg.Skipper(c) {
+			return c.Next()
+		}
+		id := c.Header(cfg.Header)
+		if cfg.DisableReuse || !valid(id) {
+			id = cfg.Generator()
+			c.Request.Header.Set(cfg.Header, id)
+		}
+		c.SetHeader(cfg.Header, id)
+		c.SetRequestID(id)
+		return c.Next()
+	}
+}
+
+// valid reports whether a client sent ID is safe to log and echo: printable
+// ASCII without spaces and at most 128 characters.
+func valid(id string) bool {
+	if id == "" || len(id) > maxIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// UUID returns a random version 4 UUID (RFC 4122).
+func UUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	var s [36]byte
+	hex.Encode(s[0:8], b[0:4])
+	s[8] = '-'
+	hex.Encode(s[9:13], b[4:6])
+	s[13] = '-'
+	hex.Encode(s[14:18], b[6:8])
+	s[18] = '-'
+	hex.Encode(s[19:23], b[8:10])
+	s[23] = '-'
+	hex.Encode(s[24:], b[10:])
+	return string(s[:])
+}
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULID returns a ULID, a lexicographically sortable ID made of a millisecond
+// timestamp and 80 random bits, in Crockford's base32.
+func ULID() string {
+	var b [16]byte
+	ms := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], ms)
+	copy(b[:6], ts[2:])
+	_, _ = rand.Read(b[6:])
+
+	// 128 bits as 26 characters of 5 bits, the first one holding 3 bits
+	hi := binary.BigEndian.Uint64(b[:8])
+	lo := binary.BigEndian.Uint64(b[8:])
+	var s [26]byte
+	for i := 25; i >= 0; i-- {
+		s[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(s[:])
+}
@@ -0,0 +1,94 @@
+package requestid
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gostack-labs/bytego"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestRequestID(t *testing.T) {
+	var logs bytes.Buffer
+	app := bytego.New()
+	app.SetLogger(bytego.NewLogger(&logs))
+	app.Use(New())
+	app.GET("/", func(c *bytego.Ctx) error {
+		c.Logger().Infof("handled %s", c.Request.URL.Path)
+		return c.String(200, c.RequestID())
+	})
+	tests := []struct {
+		name     string
+		incoming string
+		reused   bool
+	}{
+		{"generated", "", false},
+		{"reused", "abc-123", true},
+		{"invalid", "abc 123\n", false},
+		{"too long", strings.Repeat("a", maxIDLength+1), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs.Reset()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.incoming != "" {
+				req.Header.Set(bytego.HeaderXRequestID, tt.incoming)
+			}
+			rec := httptest.NewRecorder()
+			app.Handler().ServeHTTP(rec, req)
+			id := rec.Header().Get(bytego.HeaderXRequestID)
+			if tt.reused && id != tt.incoming {
+				t.Errorf("id = %q, want %q", id, tt.incoming)
+			}
+			if !tt.reused && !uuidPattern.MatchString(id) {
+				t.Errorf("id = %q is not a UUIDv4", id)
+			}
+			if rec.Body.String() != id {
+				t.Errorf("RequestID() = %q, want %q", rec.Body.String(), id)
+			}
+			if !strings.Contains(logs.String(), "request_id="+id+" handled /") {
+				t.Errorf("log = %q", logs.String())
+			}
+		})
+	}
+}
+
+func TestRequestID_Config(t *testing.T) {
+	app := bytego.New()
+	app.Use(New(Config{Header: "X-Trace-ID", Generator: func() string { return "fixed" }, DisableReuse: true}))
+	app.GET("/", func(c *bytego.Ctx) error {
+		return c.String(200, c.Header("X-Trace-ID"))
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Trace-ID", "client")
+	rec := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, req)
+	if rec.Header().Get("X-Trace-ID") != "fixed" || rec.Body.String() != "fixed" {
+		t.Errorf("got %q, request header %q", rec.Header().Get("X-Trace-ID"), rec.Body.String())
+	}
+}
+
+func TestULID(t *testing.T) {
+	before := ULID()
+	time.Sleep(2 * time.Millisecond)
+	id := ULID()
+	if len(id) != 26 || strings.Trim(id, crockford) != "" {
+		t.Fatalf("ULID() = %q", id)
+	}
+	if id[:10] <= before[:10] {
+		t.Errorf("timestamps not sortable: %q <= %q", id, before)
+	}
+	var ms int64
+	for _, ch := range id[:10] {
+		ms = ms<<5 | int64(strings.IndexRune(crockford, ch))
+	}
+	if d := time.Since(time.Unix(0, ms*int64(time.Millisecond))); d < 0 || d > time.Second {
+		t.Errorf("timestamp off by %v", d)
+	}
+}
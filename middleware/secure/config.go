@@ -0,0 +1,120 @@
+package secure
+
+import (
+	"github.com/gostack-labs/bytego"
+)
+
+// Header values left empty get their default value. The value "-" omits a
+// header, which lets a group middleware remove a header set by the app one.
+type Config struct {
+	// Skipper skips the middleware for a request when it returns true.
+	//
+	// Optional. Default value nil.
+	Skipper func(c *bytego.Ctx) bool
+
+	// HTTPSRedirect redirects plain HTTP requests to HTTPS. Requests count as
+	// HTTPS when Ctx.Scheme is "https", i.e. when served over TLS or forwarded
+	// as HTTPS by a trusted proxy.
+	//
+	// Optional. Default value false.
+	HTTPSRedirect bool
+
+	// HTTPSHost is the host redirected to instead of the request host.
+	//
+	// Optional. Default value "".
+	HTTPSHost string
+
+	// HSTSMaxAge is the max-age of Strict-Transport-Security in seconds, sent
+	// on HTTPS requests only. A negative value omits the header.
+	// Default: 31536000
+	HSTSMaxAge int
+
+	// Optional. Default value false.
+	HSTSExcludeSubdomains bool
+
+	// Optional. Default value false.
+	HSTSPreload bool
+
+	// ContentSecurityPolicy may contain the placeholder "{nonce}", which is
+	// replaced with a random nonce per request, available to templates with
+	// Nonce, e.g. "script-src 'self' 'nonce-{nonce}'".
+	//
+	// Optional. Default value "".
+	ContentSecurityPolicy string
+
+	// CSPReportOnly sends the policy as Content-Security-Policy-Report-Only.
+	//
+	// Optional. Default value false.
+	CSPReportOnly bool
+
+	// Default: "nosniff"
+	XContentTypeOptions string
+
+	// Default: "SAMEORIGIN"
+	XFrameOptions string
+
+	// Default: "0", disabling the XSS filter of legacy browsers.
+	XSSProtection string
+
+	// Default: "strict-origin-when-cross-origin"
+	ReferrerPolicy string
+
+	// Optional. Default value "".
+	PermissionsPolicy string
+
+	// Default: "same-origin"
+	CrossOriginOpenerPolicy string
+
+	// Optional. Default value "".
+	CrossOriginEmbedderPolicy string
+
+	// Default: "same-origin"
+	CrossOriginResourcePolicy string
+
+	// NonceKey is the Ctx key the CSP nonce is stored under.
+	// Default: "cspNonce"
+	NonceKey string
+}
+
+var DefaultConfig = Config{
+	HSTSMaxAge:                31536000,
+	XContentTypeOptions:       "nosniff",
+	XFrameOptions:             "SAMEORIGIN",
+	XSSProtection:             "0",
+	ReferrerPolicy:            "strict-origin-when-cross-origin",
+	CrossOriginOpenerPolicy:   "same-origin",
+	CrossOriginResourcePolicy: "same-origin",
+	NonceKey:                  "cspNonce",
+}
+
+func configDefault(config ...Config) Config {
+	if len(config) == 0 {
+		return DefaultConfig
+	}
+	cfg := config[0]
+	if cfg.HSTSMaxAge == 0 {
+		cfg.HSTSMaxAge = DefaultConfig.HSTSMaxAge
+	}
+	if cfg.XContentTypeOptions == "" {
+		cfg.XContentTypeOptions = DefaultConfig.XContentTypeOptions
+	}
+	if cfg.XFrameOptions == "" {
+		cfg.XFrameOptions = DefaultConfig.XFrameOptions
+	}
+	if cfg.XSSProtection == "" {
+		cfg.XSSProtection = DefaultConfig.XSSProtection
+	}
+	if cfg.ReferrerPolicy == "" {
+		cfg.ReferrerPolicy = DefaultConfig.ReferrerPolicy
+	}
+	if cfg.CrossOriginOpenerPolicy == "" {
+		cfg.CrossOriginOpenerPolicy = DefaultConfig.CrossOriginOpenerPolicy
+	}
+	if cfg.CrossOriginResourcePolicy == "" {
+		cfg.CrossOriginResourcePolicy = DefaultConfig.CrossOriginResourcePolicy
+	}
+	if cfg.NonceKey == "" {
+		cfg.NonceKey = DefaultConfig.NonceKey
+	}
+	return cfg
+}
@@ -0,0 +1,132 @@
+// Package secure sets security related response headers and optionally
+// redirects plain HTTP requests to HTTPS.
+package secure
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gostack-labs/bytego"
+)
+
+const (
+	headerStrictTransportSecurity         = "Strict-Transport-Security"
+	headerContentSecurityPolicy           = "Content-Security-Policy"
+	headerContentSecurityPolicyReportOnly = "Content-Security-Policy-Report-Only"
+	headerXContentTypeOptions             = "X-Content-Type-Options"
+	headerXFrameOptions                   = "X-Frame-Options"
+	headerXXSSProtection                  = "X-XSS-Protection"
+	headerReferrerPolicy                  = "Referrer-Policy"
+	headerPermissionsPolicy               = "Permissions-Policy"
+	headerCrossOriginOpenerPolicy         = "Cross-Origin-Opener-Policy"
+	headerCrossOriginEmbedderPolicy       = "Cross-Origin-Embedder-Policy"
+	headerCrossOriginResourcePolicy       = "Cross-Origin-Resource-Policy"
+
+	noncePlaceholder = "{nonce}"
+)
+
+// New returns a middleware setting the configured headers before calling the
+// next handler. Mounted on a group as well as on the app, its headers replace
+// the ones of the app middleware and the CSP nonce is kept.
+func New(config ...Config) bytego.HandlerFunc {
+	cfg := configDefault(config...)
+	headers := []struct{ name, value string }{
+		{headerXContentTypeOptions, cfg.XContentTypeOptions},
+		{headerXFrameOptions, cfg.XFrameOptions},
+		{headerXXSSProtection, cfg.XSSProtection},
+		{headerReferrerPolicy, cfg.ReferrerPolicy},
+		{headerPermissionsPolicy, cfg.PermissionsPolicy},
+		{headerCrossOriginOpenerPolicy, cfg.CrossOriginOpenerPolicy},
+		{headerCrossOriginEmbedderPolicy, cfg.CrossOriginEmbedderPolicy},
+		{headerCrossOriginResourcePolicy, cfg.CrossOriginResourcePolicy},
+	}
+	hsts := ""
+	if cfg.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(cfg.HSTSMaxAge)
+		if !cfg.HSTSExcludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		if cfg.HSTSPreload {
+			hsts += "; preload"
+		}
+	}
+	cspHeader, otherCSPHeader := headerContentSecurityPolicy, headerContentSecurityPolicyReportOnly
+	if cfg.CSPReportOnly {
+		cspHeader, otherCSPHeader = otherCSPHeader, cspHeader
+	}
+	useNonce := strings.Contains(cfg.ContentSecurityPolicy, noncePlaceholder)
+
+	return func(c *bytego.Ctx) error {
+		if cfg.Skipper != nil && cfg.Skipper(c) {
+			return c.Next()
+		}
+		https := c.Scheme() == "https"
+		if cfg.HTTPSRedirect && !https {
+			host := cfg.HTTPSHost
+			if host == "" {
+				host = c.Host()
+			}
+			code := http.StatusMovedPermanently
+			if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+				code = http.StatusPermanentRedirect
+			}
+			c.Abort()
+			return c.Redirect("https://"+host+c.Request.URL.RequestURI(), code)
+		}
+		for _, h := range headers {
+			setHeader(c, h.name, h.value)
+		}
+		if https && hsts != "" {
+			c.SetHeader(headerStrictTransportSecurity, hsts)
+		} else if cfg.HSTSMaxAge < 0 {
+			c.SetHeader(headerStrictTransportSecurity, "")
+		}
+		if csp := cfg.ContentSecurityPolicy; csp != "" {
+			if useNonce {
+				csp = strings.ReplaceAll(csp, noncePlaceholder, nonce(c, cfg.NonceKey))
+			}
+			setHeader(c, cspHeader, csp)
+			c.SetHeader(otherCSPHeader, "")
+		}
+		return c.Next()
+	}
+}
+
+// Nonce returns the CSP nonce of the request stored under key, "cspNonce" by
+// default, for use in script and style tags.
+func Nonce(c *bytego.Ctx, key ...string) string {
+	k := DefaultConfig.NonceKey
+	if len(key) > 0 {
+		k = key[0]
+	}
+	v, _ := c.Get(k)
+	n, _ := v.(string)
+	return n
+}
+
+// nonce returns the nonce of the request, generating it on first use.
+func nonce(c *bytego.Ctx, key string) string {
+	if n := Nonce(c, key); n != "" {
+		return n
+	}
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	n := base64.StdEncoding.EncodeToString(b)
+	c.Set(key, n)
+	return n
+}
+
+// setHeader sets a configured header, leaving it alone when value is empty
+// and deleting it when value is "-".
+func setHeader(c *bytego.Ctx, name, value string) {
+	switch value {
+	case "":
+	case "-":
+		c.SetHeader(name, "")
+	default:
+		c.SetHeader(name, value)
+	}
+}
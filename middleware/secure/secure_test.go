@@ -0,0 +1,132 @@
+package secure
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gostack-labs/bytego"
+)
+
+func serve(app *bytego.App, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestSecure_Defaults(t *testing.T) {
+	app := bytego.New()
+	_ = app.SetTrustedProxies([]string{"192.0.2.1"})
+	app.Use(New())
+	app.GET("/", func(c *bytego.Ctx) error {
+		return c.String(200, "ok")
+	})
+	rec := serve(app, httptest.NewRequest(http.MethodGet, "/", nil))
+	want := map[string]string{
+		headerXContentTypeOptions:       "nosniff",
+		headerXFrameOptions:             "SAMEORIGIN",
+		headerXXSSProtection:            "0",
+		headerReferrerPolicy:            "strict-origin-when-cross-origin",
+		headerCrossOriginOpenerPolicy:   "same-origin",
+		headerCrossOriginResourcePolicy: "same-origin",
+		headerStrictTransportSecurity:   "",
+		headerContentSecurityPolicy:     "",
+	}
+	for name, value := range want {
+		if got := rec.Header().Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(bytego.HeaderXForwardedProto, "https")
+	rec = serve(app, req)
+	if got := rec.Header().Get(headerStrictTransportSecurity); got != "max-age=31536000; includeSubDomains" {
+		t.Errorf("HSTS = %q", got)
+	}
+}
+
+func TestSecure_Nonce(t *testing.T) {
+	app := bytego.New()
+	app.Use(New(Config{ContentSecurityPolicy: "script-src 'self' 'nonce-{nonce}'"}))
+	api := app.Group("/api", New(Config{
+		XFrameOptions:           "DENY",
+		CrossOriginOpenerPolicy: "-",
+		ContentSecurityPolicy:   "default-src 'none'; style-src 'nonce-{nonce}'",
+		CSPReportOnly:           true,
+	}))
+	app.GET("/", func(c *bytego.Ctx) error {
+		return c.String(200, Nonce(c))
+	})
+	api.GET("/", func(c *bytego.Ctx) error {
+		return c.String(200, Nonce(c))
+	})
+
+	rec := serve(app, httptest.NewRequest(http.MethodGet, "/", nil))
+	nonce := rec.Body.String()
+	if nonce == "" || rec.Header().Get(headerContentSecurityPolicy) != "script-src 'self' 'nonce-"+nonce+"'" {
+		t.Errorf("CSP = %q, nonce %q", rec.Header().Get(headerContentSecurityPolicy), nonce)
+	}
+	if other := serve(app, httptest.NewRequest(http.MethodGet, "/", nil)).Body.String(); other == nonce {
+		t.Error("nonce reused across requests")
+	}
+
+	rec = serve(app, httptest.NewRequest(http.MethodGet, "/api/", nil))
+	nonce = rec.Body.String()
+	if got := rec.Header().Get(headerContentSecurityPolicyReportOnly); !strings.HasSuffix(got, "'nonce-"+nonce+"'") {
+		t.Errorf("group CSP = %q, nonce %q", got, nonce)
+	}
+	if got := rec.Header().Get(headerContentSecurityPolicy); got != "" {
+		t.Errorf("app CSP kept: %q", got)
+	}
+	if got := rec.Header().Get(headerXFrameOptions); got != "DENY" {
+		t.Errorf("X-Frame-Options = %q", got)
+	}
+	if got := rec.Header().Get(headerCrossOriginOpenerPolicy); got != "" {
+		t.Errorf("Cross-Origin-Opener-Policy = %q", got)
+	}
+	if got := rec.Header().Get(headerXContentTypeOptions); got != "nosniff" {
+		t.Errorf("X-Content-Type-Options = %q", got)
+	}
+}
+
+func TestSecure_HTTPSRedirect(t *testing.T) {
+	app := bytego.New()
+	_ = app.SetTrustedProxies([]string{"192.0.2.0/24"})
+	app.Use(New(Config{HTTPSRedirect: true}))
+	app.Any("/path", func(c *bytego.Ctx) error {
+		return c.String(200, "ok")
+	})
+	tests := []struct {
+		method string
+		proto  string
+		status int
+	}{
+		{http.MethodGet, "", http.StatusMovedPermanently},
+		{http.MethodPost, "http", http.StatusPermanentRedirect},
+		{http.MethodGet, "https", http.StatusOK},
+		{http.MethodGet, "untrusted", http.StatusMovedPermanently},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "http://example.com/path?a=1", nil)
+		if tt.proto == "untrusted" {
+			req.RemoteAddr = "203.0.113.9:1234"
+			req.Header.Set(bytego.HeaderXForwardedProto, "https")
+		} else if tt.proto != "" {
+			req.Header.Set(bytego.HeaderXForwardedProto, tt.proto)
+		}
+		rec := serve(app, req)
+		if rec.Code != tt.status {
+			t.Errorf("%s %q: status = %d, want %d", tt.method, tt.proto, rec.Code, tt.status)
+		}
+		if tt.status != http.StatusOK {
+			if loc := rec.Header().Get("Location"); loc != "https://example.com/path?a=1" {
+				t.Errorf("Location = %q", loc)
+			}
+			if rec.Body.String() == "ok" {
+				t.Error("handler ran after redirect")
+			}
+		}
+	}
+}
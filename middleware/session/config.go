@@ -0,0 +1,57 @@
+package session
+
+import (
+	"github.com/gostack-labs/bytego"
+)
+
+type Config struct {
+	// Skipper skips the middleware for a request when it returns true.
+	//
+	// Optional. Default value nil.
+	Skipper func(c *bytego.Ctx) bool
+
+	// Store keeps the session values.
+	//
+	// Required.
+	Store Store
+
+	// Cookie attributes. The SameSite attribute is the one set with
+	// Ctx.SetSameSite.
+	// Default: "session"
+	CookieName string
+	// Default: "/"
+	CookiePath   string
+	CookieDomain string
+	CookieSecure bool
+	// CookieAllowScript leaves out the HttpOnly attribute, which keeps page
+	// scripts, and thus XSS attacks, from reading the session cookie.
+	//
+	// Optional. Default value false.
+	CookieAllowScript bool
+	// MaxAge is the lifetime of the cookie and of stored sessions in seconds.
+	// Default: 86400
+	MaxAge int
+}
+
+var DefaultConfig = Config{
+	CookieName: "session",
+	CookiePath: "/",
+	MaxAge:     86400,
+}
+
+func configDefault(config ...Config) Config {
+	if len(config) == 0 || config[0].Store == nil {
+		panic("session: a store is required")
+	}
+	cfg := config[0]
+	if cfg.CookieName == "" {
+		cfg.CookieName = DefaultConfig.CookieName
+	}
+	if cfg.CookiePath == "" {
+		cfg.CookiePath = DefaultConfig.CookiePath
+	}
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = DefaultConfig.MaxAge
+	}
+	return cfg
+}
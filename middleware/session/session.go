@@ -0,0 +1,246 @@
+// Package session keeps per-client state across requests in a pluggable Store,
+// identified by a cookie.
+package session
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gostack-labs/bytego"
+	"github.com/gostack-labs/bytego/internal/respctl"
+)
+
+const (
+	ctxKey      = "bytego/session"
+	flashPrefix = "_flash."
+)
+
+// Session holds the values of a client session. It is loaded on the first
+// call to Get and saved before the response is committed if it changed.
+type Session struct {
+	c      *bytego.Ctx
+	cfg    *Config
+	loaded bool
+	isNew  bool
+	value  string
+	values map[string]interface{}
+	// old is the cookie value of a regenerated or destroyed session to delete.
+	old     string
+	changed bool
+	expire  bool
+	saved   bool
+}
+
+// New returns a middleware making the session of a request available with
+// Get. New panics when Store is nil.
+func New(config ...Config) bytego.HandlerFunc {
+	cfg := configDefault(config...)
+	return func(c *bytego.Ctx) error {
+		if cfg.Skipper != nil && cfg.Skipper(c) {
+			return c.Next()
+		}
+		s := &Session{c: c, cfg: &cfg}
+		c.Set(ctxKey, s)
+		w := &sessionWriter{ResponseWriter: c.Response, s: s}
+		c.Response = w
+		err := c.Next()
+		c.Response = w.ResponseWriter
+		if !w.Committed() {
+			// the error handler writes the response after the middleware returns
+			if serr := s.save(); err == nil {
+				err = serr
+			}
+		}
+		return err
+	}
+}
+
+// Get returns the session of the request. It panics when the middleware is
+// not installed.
+func Get(c *bytego.Ctx) *Session {
+	v, _ := c.Get(ctxKey)
+	s, ok := v.(*Session)
+	if !ok {
+		panic("session: middleware not installed")
+	}
+	s.load()
+	return s
+}
+
+func (s *Session) load() {
+	if s.loaded {
+		return
+	}
+	s.loaded = true
+	if cookie, err := s.c.Cookie(s.cfg.CookieName); err == nil && cookie.Value != "" {
+		values, ok, err := s.cfg.Store.Load(s.c, cookie.Value)
+		if err != nil {
+			s.c.Logger().Errorf("session: load: %v", err)
+		} else if ok {
+			s.value, s.values = cookie.Value, values
+		}
+	}
+	if s.values == nil {
+		s.values = make(map[string]interface{})
+		s.isNew = true
+	}
+}
+
+// IsNew reports whether the request carried no valid session.
+func (s *Session) IsNew() bool {
+	return s.isNew
+}
+
+func (s *Session) Get(key string) interface{} {
+	return s.values[key]
+}
+
+func (s *Session) Set(key string, value interface{}) {
+	s.values[key] = value
+	s.touch()
+}
+
+func (s *Session) Delete(key string) {
+	if _, ok := s.values[key]; ok {
+		delete(s.values, key)
+		s.touch()
+	}
+}
+
+// Keys returns the sorted keys of the session, flashes left out.
+func (s *Session) Keys() []string {
+	keys := make([]string, 0, len(s.values))
+	for k := range s.values {
+		if !strings.HasPrefix(k, flashPrefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Flash adds a value under key that is kept until it is read with Flashes,
+// typically on the next request.
+func (s *Session) Flash(key string, value interface{}) {
+	old, _ := s.values[flashPrefix+key].([]interface{})
+	flashes := make([]interface{}, len(old), len(old)+1)
+	copy(flashes, old)
+	s.values[flashPrefix+key] = append(flashes, value)
+	s.touch()
+}
+
+// Flashes returns and removes the values flashed under key.
+func (s *Session) Flashes(key string) []interface{} {
+	flashes, _ := s.values[flashPrefix+key].([]interface{})
+	s.Delete(flashPrefix + key)
+	return flashes
+}
+
+// Regenerate keeps the values under a new session ID and deletes the old one,
+// which should be done on login to prevent session fixation.
+func (s *Session) Regenerate() {
+	if s.value != "" {
+		s.old = s.value
+		s.value = ""
+	}
+	s.touch()
+}
+
+// Destroy deletes the session and expires its cookie.
+func (s *Session) Destroy() {
+	if s.value != "" {
+		s.old = s.value
+		s.value = ""
+	}
+	s.values = make(map[string]interface{})
+	s.changed = false
+	s.expire = true
+	s.saved = false
+}
+
+func (s *Session) touch() {
+	s.changed = true
+	s.expire = false
+	s.saved = false
+}
+
+// save deletes a replaced session, then stores the session and sets its cookie
+// if it changed.
+func (s *Session) save() error {
+	if !s.loaded || s.saved {
+		return nil
+	}
+	s.saved = true
+	cfg := s.cfg
+	if s.old != "" {
+		if err := cfg.Store.Delete(s.c, s.old); err != nil {
+			return err
+		}
+		s.old = ""
+	}
+	if s.expire {
+		s.c.SetCookie(cfg.CookieName, "", -1, cfg.CookiePath, cfg.CookieDomain, cfg.CookieSecure, !cfg.CookieAllowScript)
+		return nil
+	}
+	if !s.changed {
+		return nil
+	}
+	value, err := cfg.Store.Save(s.c, s.value, s.values, time.Duration(cfg.MaxAge)*time.Second)
+	if err != nil {
+		return err
+	}
+	s.value = value
+	s.changed = false
+	s.c.SetCookie(cfg.CookieName, value, cfg.MaxAge, cfg.CookiePath, cfg.CookieDomain, cfg.CookieSecure, !cfg.CookieAllowScript)
+	return nil
+}
+
+// sessionWriter saves the session when the response is committed, while the
+// cookie can still be set.
+type sessionWriter struct {
+	bytego.ResponseWriter
+	s *Session
+}
+
+func (w *sessionWriter) commit() {
+	if !w.ResponseWriter.Committed() {
+		if err := w.s.save(); err != nil {
+			w.s.c.Logger().Errorf("session: save: %v", err)
+		}
+	}
+}
+
+func (w *sessionWriter) WriteHeader(code int) {
+	if code >= http.StatusOK {
+		w.commit()
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *sessionWriter) Write(p []byte) (int, error) {
+	w.commit()
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *sessionWriter) WriteString(s string) (int, error) {
+	w.commit()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *sessionWriter) Flush() {
+	w.commit()
+	_ = respctl.Flush(w.ResponseWriter)
+}
+
+func (w *sessionWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.commit()
+	return respctl.Hijack(w.ResponseWriter)
+}
+
+func (w *sessionWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
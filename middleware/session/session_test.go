@@ -0,0 +1,145 @@
+package session
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gostack-labs/bytego"
+)
+
+func newApp(store Store) *bytego.App {
+	app := bytego.New()
+	app.Use(New(Config{Store: store}))
+	app.GET("/set", func(c *bytego.Ctx) error {
+		s := Get(c)
+		s.Set("user", "alice")
+		s.Flash("notice", "welcome")
+		return c.String(200, "ok")
+	})
+	app.GET("/get", func(c *bytego.Ctx) error {
+		s := Get(c)
+		user, _ := s.Get("user").(string)
+		for _, f := range s.Flashes("notice") {
+			user += " " + f.(string)
+		}
+		return c.String(200, user)
+	})
+	app.GET("/regenerate", func(c *bytego.Ctx) error {
+		Get(c).Regenerate()
+		return c.String(200, "ok")
+	})
+	app.GET("/destroy", func(c *bytego.Ctx) error {
+		Get(c).Destroy()
+		return c.String(200, "ok")
+	})
+	app.GET("/error", func(c *bytego.Ctx) error {
+		Get(c).Set("failed", true)
+		return errors.New("boom")
+	})
+	return app
+}
+
+func serve(app *bytego.App, target string, cookie *http.Cookie) (*httptest.ResponseRecorder, *http.Cookie) {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, req)
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == "session" {
+			return rec, c
+		}
+	}
+	return rec, nil
+}
+
+func TestSession(t *testing.T) {
+	cookieStore, err := NewCookieStore([]byte("0123456789abcdef0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, store := range map[string]Store{"cookie": cookieStore, "memory": NewMemoryStore()} {
+		t.Run(name, func(t *testing.T) {
+			app := newApp(store)
+			if _, cookie := serve(app, "/get", nil); cookie != nil {
+				t.Errorf("unchanged session set a cookie: %v", cookie)
+			}
+			_, cookie := serve(app, "/set", nil)
+			if cookie == nil || !cookie.HttpOnly || cookie.MaxAge != 86400 {
+				t.Fatalf("cookie = %v", cookie)
+			}
+			rec, next := serve(app, "/get", cookie)
+			if rec.Body.String() != "alice welcome" {
+				t.Errorf("body = %q", rec.Body.String())
+			}
+			if next == nil {
+				t.Fatal("consumed flash not saved")
+			}
+			cookie = next
+			if rec, _ := serve(app, "/get", cookie); rec.Body.String() != "alice" {
+				t.Errorf("after flash: body = %q", rec.Body.String())
+			}
+
+			_, regenerated := serve(app, "/regenerate", cookie)
+			if regenerated == nil || regenerated.Value == cookie.Value {
+				t.Fatalf("regenerated cookie = %v", regenerated)
+			}
+			if rec, _ := serve(app, "/get", regenerated); rec.Body.String() != "alice" {
+				t.Errorf("regenerated session lost values: %q", rec.Body.String())
+			}
+			if name == "memory" {
+				if rec, _ := serve(app, "/get", cookie); rec.Body.String() != "" {
+					t.Errorf("old session still valid: %q", rec.Body.String())
+				}
+			}
+
+			_, destroyed := serve(app, "/destroy", regenerated)
+			if destroyed == nil || destroyed.MaxAge >= 0 {
+				t.Errorf("destroy cookie = %v", destroyed)
+			}
+			if name == "memory" {
+				if rec, _ := serve(app, "/get", regenerated); rec.Body.String() != "" {
+					t.Errorf("destroyed session still valid: %q", rec.Body.String())
+				}
+			}
+
+			rec, cookie = serve(app, "/error", nil)
+			if rec.Code != http.StatusInternalServerError || cookie == nil {
+				t.Errorf("error response: %d, cookie %v", rec.Code, cookie)
+			}
+		})
+	}
+}
+
+func TestCookieStore_Rotation(t *testing.T) {
+	oldKey, newKey := []byte("0123456789abcdef"), []byte("fedcba9876543210")
+	oldStore, _ := NewCookieStore(oldKey)
+	_, cookie := serve(newApp(oldStore), "/set", nil)
+
+	rotated, _ := NewCookieStore(newKey, oldKey)
+	if rec, _ := serve(newApp(rotated), "/get", cookie); rec.Body.String() != "alice welcome" {
+		t.Errorf("rotated store: body = %q", rec.Body.String())
+	}
+	other, _ := NewCookieStore(newKey)
+	if rec, _ := serve(newApp(other), "/get", cookie); rec.Body.String() != "" {
+		t.Errorf("unknown key: body = %q", rec.Body.String())
+	}
+	if _, err := NewCookieStore([]byte("short")); err == nil {
+		t.Error("expected error for invalid key size")
+	}
+}
+
+func TestSession_AllowScript(t *testing.T) {
+	app := bytego.New()
+	app.Use(New(Config{Store: NewMemoryStore(), CookieAllowScript: true}))
+	app.GET("/set", func(c *bytego.Ctx) error {
+		Get(c).Set("theme", "dark")
+		return nil
+	})
+	if _, cookie := serve(app, "/set", nil); cookie == nil || cookie.HttpOnly {
+		t.Errorf("cookie = %v, want one without HttpOnly", cookie)
+	}
+}
@@ -0,0 +1,186 @@
+package session
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/gob"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gostack-labs/bytego"
+)
+
+// Store keeps session values, identified by the value of the session cookie.
+// Implementations must be safe for concurrent use.
+type Store interface {
+	// Load returns the values of the session identified by value, or false
+	// when it does not exist or has expired.
+	Load(c *bytego.Ctx, value string) (map[string]interface{}, bool, error)
+	// Save stores the values and returns the new cookie value. value is empty
+	// for a new session.
+	Save(c *bytego.Ctx, value string, values map[string]interface{}, maxAge time.Duration) (string, error)
+	Delete(c *bytego.Ctx, value string) error
+}
+
+// maxCookieSize is the size browsers are required to accept for a cookie.
+const maxCookieSize = 4096
+
+var ErrCookieTooLarge = errors.New("session: encoded session exceeds 4096 bytes")
+
+func init() {
+	gob.Register([]interface{}{})
+	gob.Register(map[string]interface{}{})
+}
+
+// CookieStore keeps the values in the cookie itself, gob encoded, then
+// encrypted and authenticated with AES-GCM. Custom value types have to be
+// registered with gob.Register.
+type CookieStore struct {
+	aeads []cipher.AEAD
+}
+
+type cookiePayload struct {
+	Values  map[string]interface{}
+	Expires int64
+}
+
+// NewCookieStore returns a CookieStore encrypting with the first key and
+// decrypting with any of them, so that keys can be rotated. Keys must be 16,
+// 24 or 32 bytes long.
+func NewCookieStore(keys ...[]byte) (*CookieStore, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("session: at least one key is required")
+	}
+	s := &CookieStore{}
+	for _, key := range keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		s.aeads = append(s.aeads, aead)
+	}
+	return s, nil
+}
+
+func (s *CookieStore) Load(c *bytego.Ctx, value string) (map[string]interface{}, bool, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, false, nil
+	}
+	for _, aead := range s.aeads {
+		if len(data) < aead.NonceSize() {
+			continue
+		}
+		plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+		if err != nil {
+			continue
+		}
+		var p cookiePayload
+		if err := gob.NewDecoder(bytes.NewReader(plain)).Decode(&p); err != nil {
+			return nil, false, err
+		}
+		if time.Now().Unix() > p.Expires {
+			return nil, false, nil
+		}
+		return p.Values, true, nil
+	}
+	return nil, false, nil
+}
+
+func (s *CookieStore) Save(c *bytego.Ctx, value string, values map[string]interface{}, maxAge time.Duration) (string, error) {
+	var buf bytes.Buffer
+	p := cookiePayload{Values: values, Expires: time.Now().Add(maxAge).Unix()}
+	if err := gob.NewEncoder(&buf).Encode(&p); err != nil {
+		return "", err
+	}
+	aead := s.aeads[0]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+buf.Len()+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, buf.Bytes(), nil))
+	if len(encoded) > maxCookieSize {
+		return "", ErrCookieTooLarge
+	}
+	return encoded, nil
+}
+
+// Delete does nothing, the cookie being expired by the middleware.
+func (s *CookieStore) Delete(c *bytego.Ctx, value string) error {
+	return nil
+}
+
+const sweepInterval = 1024
+
+// MemoryStore keeps the values in memory under a random ID held in the cookie.
+// Expired sessions are dropped periodically.
+type MemoryStore struct {
+	mu       sync.Mutex
+	sessions map[string]memorySession
+	calls    int
+}
+
+type memorySession struct {
+	values  map[string]interface{}
+	expires time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{sessions: make(map[string]memorySession)}
+}
+
+func (s *MemoryStore) Load(c *bytego.Ctx, value string) (map[string]interface{}, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[value]
+	if !ok || time.Now().After(sess.expires) {
+		return nil, false, nil
+	}
+	return copyValues(sess.values), true, nil
+}
+
+func (s *MemoryStore) Save(c *bytego.Ctx, value string, values map[string]interface{}, maxAge time.Duration) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if s.calls++; s.calls%sweepInterval == 0 {
+		for id, sess := range s.sessions {
+			if now.After(sess.expires) {
+				delete(s.sessions, id)
+			}
+		}
+	}
+	if _, ok := s.sessions[value]; !ok {
+		// never adopt an ID the store did not hand out
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+		value = base64.RawURLEncoding.EncodeToString(b)
+	}
+	s.sessions[value] = memorySession{values: copyValues(values), expires: now.Add(maxAge)}
+	return value, nil
+}
+
+func (s *MemoryStore) Delete(c *bytego.Ctx, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, value)
+	return nil
+}
+
+func copyValues(values map[string]interface{}) map[string]interface{} {
+	cp := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		cp[k] = v
+	}
+	return cp
+}
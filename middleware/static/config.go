@@ -0,0 +1,59 @@
+package static
+
+type Config struct {
+	// Param is the catch-all route parameter holding the file path. The request
+	// path is used when the route has no such parameter.
+	// Default: "filepath"
+	Param string
+
+	// Index is the file served for directories.
+	// Default: "index.html"
+	Index string
+
+	// Browse enables directory listings when a directory has no index file.
+	//
+	// Optional. Default value false.
+	Browse bool
+
+	// SPA serves the root index file for paths that do not exist, so a single
+	// page application can route them.
+	//
+	// Optional. Default value false.
+	SPA bool
+
+	// Precompressed serves a ".br" or ".gz" sibling of a file, when present and
+	// accepted by the client, with the matching Content-Encoding.
+	//
+	// Optional. Default value false.
+	Precompressed bool
+
+	// CacheControl is the Cache-Control header of served files,
+	// e.g. "public, max-age=31536000, immutable".
+	//
+	// Optional. Default value "".
+	CacheControl string
+
+	// DisableETag stops generating ETag headers from file size and modification time.
+	//
+	// Optional. Default value false.
+	DisableETag bool
+}
+
+var DefaultConfig = Config{
+	Param: "filepath",
+	Index: "index.html",
+}
+
+func configDefault(config ...Config) Config {
+	if len(config) == 0 {
+		return DefaultConfig
+	}
+	cfg := config[0]
+	if cfg.Param == "" {
+		cfg.Param = DefaultConfig.Param
+	}
+	if cfg.Index == "" {
+		cfg.Index = DefaultConfig.Index
+	}
+	return cfg
+}
@@ -0,0 +1,149 @@
+package static
+
+import (
+	"bytes"
+	"html"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gostack-labs/bytego"
+)
+
+const headerLocation = "Location"
+
+var encodings = []struct {
+	name string
+	ext  string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// New returns a handler serving files of fsys, such as an embed.FS or os.DirFS.
+//
+//	app.GET("/assets/*filepath", static.New(assets))
+func New(fsys fs.FS, config ...Config) bytego.HandlerFunc {
+	cfg := configDefault(config...)
+	return func(c *bytego.Ctx) error {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			return bytego.NewHTTPError(http.StatusMethodNotAllowed)
+		}
+		p, ok := c.Params.Get(cfg.Param)
+		if !ok {
+			p = c.Request.URL.Path
+		}
+		name := strings.TrimPrefix(path.Clean("/"+p), "/")
+		if name == "" {
+			name = "."
+		}
+
+		fi, err := fs.Stat(fsys, name)
+		if err == nil && fi.IsDir() {
+			if !strings.HasSuffix(c.Request.URL.Path, "/") {
+				// relative to the directory, so that a path like //evil.com/dir cannot
+				// send the client to another host
+				location := &url.URL{Path: path.Base(c.Request.URL.Path) + "/", RawQuery: c.Request.URL.RawQuery}
+				c.SetHeader(headerLocation, location.String())
+				c.Status(http.StatusMovedPermanently)
+				return nil
+			}
+			index := path.Join(name, cfg.Index)
+			if ifi, err := fs.Stat(fsys, index); err == nil && !ifi.IsDir() {
+				return serveFile(c, fsys, index, ifi, cfg)
+			}
+			if cfg.Browse {
+				return listDir(c, fsys, name)
+			}
+			err = fs.ErrNotExist
+		}
+		if err != nil {
+			if cfg.SPA && !isAssetPath(name) {
+				if ifi, err := fs.Stat(fsys, cfg.Index); err == nil && !ifi.IsDir() {
+					return serveFile(c, fsys, cfg.Index, ifi, cfg)
+				}
+			}
+			return bytego.ErrNotFound.WithErr(err)
+		}
+		return serveFile(c, fsys, name, fi, cfg)
+	}
+}
+
+// isAssetPath reports whether name has a non HTML file extension; SPA mode
+// answers such missing paths with 404 instead of the index.
+func isAssetPath(name string) bool {
+	return path.Ext(name) != "" && path.Ext(name) != ".html"
+}
+
+func serveFile(c *bytego.Ctx, fsys fs.FS, name string, fi fs.FileInfo, cfg Config) error {
+	header := c.Response.Header()
+	servedName, servedInfo, encoding := name, fi, ""
+	if cfg.Precompressed && c.Header(bytego.HeaderAcceptEncoding) != "" {
+		c.AppendHeader(bytego.HeaderVary, bytego.HeaderAcceptEncoding)
+		for _, enc := range encodings {
+			if c.AcceptsEncodings(enc.name, "identity") != enc.name {
+				continue
+			}
+			if cfi, err := fs.Stat(fsys, name+enc.ext); err == nil && !cfi.IsDir() {
+				servedName, servedInfo, encoding = name+enc.ext, cfi, enc.name
+				break
+			}
+		}
+	}
+
+	f, err := fsys.Open(servedName)
+	if err != nil {
+		return bytego.ErrNotFound.WithErr(err)
+	}
+	defer f.Close()
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		b, err := io.ReadAll(f)
+		if err != nil {
+			return err
+		}
+		content = bytes.NewReader(b)
+	}
+
+	if encoding != "" {
+		header.Set("Content-Encoding", encoding)
+	}
+	if cfg.CacheControl != "" {
+		header.Set("Cache-Control", cfg.CacheControl)
+	}
+	if !cfg.DisableETag && header.Get("Etag") == "" {
+		etag := strconv.FormatInt(servedInfo.Size(), 16) + "-" + strconv.FormatInt(servedInfo.ModTime().UnixNano(), 16)
+		if encoding != "" {
+			etag += "-" + encoding
+		}
+		header.Set("Etag", `W/"`+etag+`"`)
+	}
+	// the original name keeps the Content-Type of the uncompressed file
+	http.ServeContent(c.Response, c.Request, path.Base(name), servedInfo.ModTime(), content)
+	return nil
+}
+
+func listDir(c *bytego.Ctx, fsys fs.FS, name string) error {
+	entries, err := fs.ReadDir(fsys, name)
+	if err != nil {
+		return err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	var b strings.Builder
+	b.WriteString("<!doctype html>\n<meta name=\"viewport\" content=\"width=device-width\">\n<pre>\n")
+	for _, e := range entries {
+		n := e.Name()
+		if e.IsDir() {
+			n += "/"
+		}
+		u := url.URL{Path: n}
+		b.WriteString(`<a href="` + html.EscapeString(u.String()) + `">` + html.EscapeString(n) + "</a>\n")
+	}
+	b.WriteString("</pre>\n")
+	return c.HTML(http.StatusOK, b.String())
+}
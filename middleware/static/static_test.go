@@ -0,0 +1,101 @@
+package static
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/gostack-labs/bytego"
+)
+
+func TestNew(t *testing.T) {
+	now := time.Now()
+	fsys := fstest.MapFS{
+		"index.html":       {Data: []byte("<h1>app</h1>"), ModTime: now},
+		"js/app.js":        {Data: []byte("console.log(1)"), ModTime: now},
+		"js/app.js.br":     {Data: []byte("brotli"), ModTime: now},
+		"js/app.js.gz":     {Data: []byte("gzip"), ModTime: now},
+		"img/logo.svg":     {Data: []byte("<svg/>"), ModTime: now},
+		"docs/guide.txt":   {Data: []byte("guide"), ModTime: now},
+		"docs/sub/end.txt": {Data: []byte("end"), ModTime: now},
+	}
+	app := bytego.New()
+	app.GET("/assets/*filepath", New(fsys, Config{Precompressed: true, CacheControl: "public, max-age=60"}))
+	app.GET("/browse/*filepath", New(fsys, Config{Browse: true}))
+	app.NoRoute(New(fsys, Config{SPA: true}))
+
+	tests := []struct {
+		name           string
+		path           string
+		acceptEncoding string
+		code           int
+		body           string
+		encoding       string
+	}{
+		{"file", "/assets/js/app.js", "", http.StatusOK, "console.log(1)", ""},
+		{"brotli", "/assets/js/app.js", "gzip, br", http.StatusOK, "brotli", "br"},
+		{"gzip", "/assets/js/app.js", "gzip", http.StatusOK, "gzip", "gzip"},
+		{"no precompressed sibling", "/assets/img/logo.svg", "br", http.StatusOK, "<svg/>", ""},
+		{"index", "/assets/", "", http.StatusOK, "<h1>app</h1>", ""},
+		{"listing disabled", "/assets/docs/", "", http.StatusNotFound, "", ""},
+		{"listing", "/browse/docs/", "", http.StatusOK, `<a href="sub/">sub/</a>`, ""},
+		{"missing", "/assets/missing.js", "", http.StatusNotFound, "", ""},
+		{"spa fallback", "/users/42", "", http.StatusOK, "<h1>app</h1>", ""},
+		{"spa missing asset", "/missing.js", "", http.StatusNotFound, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set(bytego.HeaderAcceptEncoding, tt.acceptEncoding)
+			}
+			rec := httptest.NewRecorder()
+			app.Handler().ServeHTTP(rec, req)
+			if rec.Code != tt.code {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.code)
+			}
+			if tt.body != "" && !strings.Contains(rec.Body.String(), tt.body) {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.body)
+			}
+			if got := rec.Header().Get("Content-Encoding"); got != tt.encoding {
+				t.Errorf("Content-Encoding = %q, want %q", got, tt.encoding)
+			}
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/assets/js/app.js", nil)
+	rec := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, req)
+	if rec.Header().Get("Cache-Control") != "public, max-age=60" || rec.Header().Get("Etag") == "" ||
+		rec.Header().Get("Content-Type") != "text/javascript; charset=utf-8" {
+		t.Errorf("headers = %v", rec.Header())
+	}
+}
+
+func TestNew_DirectoryRedirect(t *testing.T) {
+	fsys := fstest.MapFS{
+		"docs/guide.txt":          {Data: []byte("guide")},
+		"evil.com/docs/guide.txt": {Data: []byte("guide")},
+	}
+	app := bytego.New()
+	app.NoRoute(New(fsys))
+	for path, want := range map[string]string{
+		"/docs":           "docs/",
+		"//evil.com/docs": "docs/",
+		"/docs?lang=en":   "docs/?lang=en",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.URL.Path, req.URL.RawQuery = path, ""
+		if i := strings.IndexByte(path, '?'); i >= 0 {
+			req.URL.Path, req.URL.RawQuery = path[:i], path[i+1:]
+		}
+		rec := httptest.NewRecorder()
+		app.Handler().ServeHTTP(rec, req)
+		if rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != want {
+			t.Errorf("%s: %d Location %q, want %q", path, rec.Code, rec.Header().Get("Location"), want)
+		}
+	}
+}
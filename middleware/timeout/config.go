@@ -0,0 +1,47 @@
+package timeout
+
+import (
+	"time"
+
+	"github.com/gostack-labs/bytego"
+)
+
+type Config struct {
+	// Skipper skips the middleware for a request when it returns true.
+	//
+	// Optional. Default value nil.
+	Skipper func(c *bytego.Ctx) bool
+
+	// Timeout is the time a handler has to complete.
+	// Default: 5 seconds
+	Timeout time.Duration
+
+	// Overrides sets the timeout of routes by their path as registered, e.g.
+	// "/upload/:id". A value <= 0 disables the timeout for the route.
+	//
+	// Optional. Default value nil.
+	Overrides map[string]time.Duration
+
+	// Error is returned to the error handler when the deadline passes.
+	// Default: ErrServiceUnavailable
+	Error error
+}
+
+var DefaultConfig = Config{
+	Timeout: 5 * time.Second,
+	Error:   ErrServiceUnavailable,
+}
+
+func configDefault(config ...Config) Config {
+	if len(config) == 0 {
+		return DefaultConfig
+	}
+	cfg := config[0]
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultConfig.Timeout
+	}
+	if cfg.Error == nil {
+		cfg.Error = DefaultConfig.Error
+	}
+	return cfg
+}
@@ -0,0 +1,170 @@
+// Package timeout bounds the time handlers have to produce a response.
+package timeout
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/gostack-labs/bytego"
+)
+
+var (
+	// ErrServiceUnavailable is the default error of a request whose handler timed out.
+	ErrServiceUnavailable = bytego.NewHTTPError(http.StatusServiceUnavailable, "request timeout")
+	// ErrGatewayTimeout suits handlers that time out waiting on upstream services.
+	ErrGatewayTimeout = bytego.NewHTTPError(http.StatusGatewayTimeout)
+)
+
+// New returns a middleware running the rest of the handler chain with a
+// deadline on the request context. The handlers run in their own goroutine
+// on a copy of the Ctx and their response is buffered, so that once the
+// deadline passes Config.Error goes to the error handler and later writes of
+// the abandoned handlers fail with http.ErrHandlerTimeout. Values stored with
+// Ctx.Set and the ID of Ctx.SetRequestID are carried back to the Ctx of the
+// outer middlewares when the handlers complete in time, and are lost when
+// they do not. Handlers should watch Ctx.Context to stop early. Flushing and
+// hijacking are not supported.
+func New(config ...Config) bytego.HandlerFunc {
+	cfg := configDefault(config...)
+	return func(c *bytego.Ctx) error {
+		d := cfg.Timeout
+		if o, ok := cfg.Overrides[c.RoutePath()]; ok {
+			d = o
+		}
+		if d <= 0 || (cfg.Skipper != nil && cfg.Skipper(c)) {
+			return c.Next()
+		}
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		tw := &timeoutWriter{w: c.Response, h: make(http.Header)}
+		cp := c.Copy()
+		cp.Response = tw
+		done := make(chan error, 1)
+		panicked := make(chan interface{}, 1)
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					panicked <- p
+				}
+			}()
+			done <- cp.Next()
+		}()
+		// the chain has run, or is abandoned, on the copy
+		c.Abort()
+
+		select {
+		case p := <-panicked:
+			panic(p)
+		case err := <-done:
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			if err != nil && errors.Is(err, context.DeadlineExceeded) && ctx.Err() == context.DeadlineExceeded {
+				tw.timedOut = true
+				return cfg.Error
+			}
+			for k, v := range cp.Keys() {
+				c.Set(k, v)
+			}
+			dst := c.Response.Header()
+			for k, v := range tw.h {
+				dst[k] = v
+			}
+			if id := cp.RequestID(); id != c.RequestID() {
+				c.SetRequestID(id)
+			}
+			if tw.wroteHeader {
+				c.Response.WriteHeader(tw.status)
+				if _, werr := c.Response.Write(tw.buf.Bytes()); err == nil {
+					err = werr
+				}
+			}
+			return err
+		case <-ctx.Done():
+			tw.mu.Lock()
+			tw.timedOut = true
+			tw.mu.Unlock()
+			if ctx.Err() == context.DeadlineExceeded {
+				return cfg.Error
+			}
+			return ctx.Err()
+		}
+	}
+}
+
+// timeoutWriter buffers the response of handlers until they complete in time.
+type timeoutWriter struct {
+	w           bytego.ResponseWriter
+	h           http.Header
+	mu          sync.Mutex
+	buf         bytes.Buffer
+	status      int
+	wroteHeader bool
+	timedOut    bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.h
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+	tw.status = code
+	tw.wroteHeader = true
+}
+
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if !tw.wroteHeader {
+		tw.status = http.StatusOK
+		tw.wroteHeader = true
+	}
+	return tw.buf.Write(p)
+}
+
+func (tw *timeoutWriter) WriteString(s string) (int, error) {
+	return tw.Write([]byte(s))
+}
+
+func (tw *timeoutWriter) Status() int {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	return tw.status
+}
+
+func (tw *timeoutWriter) Size() int {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	return tw.buf.Len()
+}
+
+func (tw *timeoutWriter) Committed() bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	return tw.wroteHeader
+}
+
+// Flush does nothing, the response is sent when the handlers complete.
+func (tw *timeoutWriter) Flush() {}
+
+func (tw *timeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, errors.New("timeout: hijacking is not supported")
+}
+
+func (tw *timeoutWriter) Unwrap() http.ResponseWriter {
+	return tw.w
+}
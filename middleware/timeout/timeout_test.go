@@ -0,0 +1,155 @@
+package timeout
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gostack-labs/bytego"
+)
+
+func TestTimeout(t *testing.T) {
+	lateWrite := make(chan error, 1)
+	app := bytego.New()
+	app.Use(New(Config{
+		Timeout:   20 * time.Millisecond,
+		Overrides: map[string]time.Duration{"/slow/:id": time.Second, "/unbounded": 0},
+	}))
+	app.GET("/fast", func(c *bytego.Ctx) error {
+		c.SetHeader("X-Handler", "fast")
+		return c.String(201, "done")
+	})
+	slow := func(c *bytego.Ctx) error {
+		select {
+		case <-c.Context().Done():
+			return c.Context().Err()
+		case <-time.After(50 * time.Millisecond):
+			return c.String(200, "slow "+c.Param("id"))
+		}
+	}
+	app.GET("/slow", slow)
+	app.GET("/slow/:id", slow)
+	app.GET("/unbounded", func(c *bytego.Ctx) error {
+		if _, ok := c.Context().Deadline(); ok {
+			return errors.New("unexpected deadline")
+		}
+		return c.String(200, "unbounded")
+	})
+	app.GET("/stubborn", func(c *bytego.Ctx) error {
+		time.Sleep(50 * time.Millisecond)
+		c.SetHeader("X-Late", "1")
+		_, err := c.Response.Write([]byte("late"))
+		lateWrite <- err
+		return nil
+	})
+	app.GET("/error", func(c *bytego.Ctx) error {
+		return bytego.ErrForbidden
+	})
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/fast", 201, "done"},
+		{"/slow", 503, ""},
+		{"/slow/7", 200, "slow 7"},
+		{"/unbounded", 200, "unbounded"},
+		{"/stubborn", 503, ""},
+		{"/error", 403, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			app.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body.String())
+			}
+			if tt.body != "" && rec.Body.String() != tt.body {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.body)
+			}
+			if tt.path == "/fast" && rec.Header().Get("X-Handler") != "fast" {
+				t.Errorf("headers = %v", rec.Header())
+			}
+			if tt.path == "/stubborn" {
+				if err := <-lateWrite; err != http.ErrHandlerTimeout {
+					t.Errorf("late Write() error = %v", err)
+				}
+				if rec.Header().Get("X-Late") != "" {
+					t.Error("late header reached the response")
+				}
+			}
+		})
+	}
+}
+
+func TestTimeout_GatewayTimeoutAndPanic(t *testing.T) {
+	app := bytego.New()
+	app.Use(func(c *bytego.Ctx) (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = bytego.NewHTTPError(http.StatusInternalServerError, "recovered")
+			}
+		}()
+		return c.Next()
+	})
+	app.Use(New(Config{Timeout: 10 * time.Millisecond, Error: ErrGatewayTimeout}))
+	app.GET("/slow", func(c *bytego.Ctx) error {
+		<-c.Context().Done()
+		return c.Context().Err()
+	})
+	app.GET("/panic", func(c *bytego.Ctx) error {
+		panic("boom")
+	})
+	for path, want := range map[string]int{"/slow": 504, "/panic": 500} {
+		rec := httptest.NewRecorder()
+		app.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != want {
+			t.Errorf("%s: status = %d, want %d", path, rec.Code, want)
+		}
+	}
+}
+
+func TestTimeout_CtxValues(t *testing.T) {
+	var got map[string]interface{}
+	app := bytego.New()
+	app.Use(func(c *bytego.Ctx) error {
+		c.Set("outer", 1)
+		err := c.Next()
+		got = map[string]interface{}{"user": nil, "outer": nil, "id": c.RequestID()}
+		got["user"], _ = c.Get("user")
+		got["outer"], _ = c.Get("outer")
+		return err
+	})
+	app.Use(New(Config{Timeout: 20 * time.Millisecond}))
+	app.GET("/fast", func(c *bytego.Ctx) error {
+		c.Set("user", "alice")
+		c.SetRequestID("req-1")
+		return c.String(200, "ok")
+	})
+	app.GET("/slow", func(c *bytego.Ctx) error {
+		<-c.Context().Done()
+		c.Set("user", "bob")
+		c.SetRequestID("req-2")
+		return c.Context().Err()
+	})
+
+	tests := []struct {
+		path string
+		want map[string]interface{}
+	}{
+		{"/fast", map[string]interface{}{"user": "alice", "outer": 1, "id": "req-1"}},
+		// values set by handlers that time out are lost
+		{"/slow", map[string]interface{}{"user": nil, "outer": 1, "id": ""}},
+	}
+	for _, tt := range tests {
+		app.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+		for k, v := range tt.want {
+			if got[k] != v {
+				t.Errorf("%s: %s = %v, want %v", tt.path, k, got[k], v)
+			}
+		}
+	}
+}
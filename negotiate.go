@@ -0,0 +1,202 @@
+package bytego
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrNotAcceptable is returned by Ctx.Negotiate when no offer matches the Accept header.
+var ErrNotAcceptable = NewHTTPError(http.StatusNotAcceptable)
+
+// ViewData is rendered with the template Name when Ctx.Negotiate picks HTML; the
+// other formats encode Data.
+type ViewData struct {
+	Name string
+	Data interface{}
+}
+
+var defaultOffers = []string{MIMEJSON, MIMEXML, MIMEYAML, MIMEPlain}
+
+// Negotiate writes data in the offered format that best matches the Accept
+// header. Offers default to JSON, XML, YAML and plain text, preceded by HTML
+// when data is a ViewData; other offers are written by the renderer registered
+// for them. Offers that cannot be written, like HTML for data that is neither a
+// ViewData nor a string or a media type without renderer, give way to the next
+// best one. ErrNotAcceptable is returned when nothing matches.
+func (c *Ctx) Negotiate(code int, data interface{}, offers ...string) error {
+	view, isView := data.(ViewData)
+	if v, ok := data.(*ViewData); ok && v != nil {
+		view, isView = *v, true
+	}
+	if isView {
+		data = view.Data
+	}
+	if len(offers) == 0 {
+		offers = defaultOffers
+		if isView {
+			offers = append([]string{MIMEHTML}, defaultOffers...)
+		}
+	}
+	c.AppendHeader(HeaderVary, HeaderAccept)
+	// leave out the offers data cannot be written as so the next best one is picked
+	renderable := make([]string, 0, len(offers))
+	for _, offer := range offers {
+		if c.canRender(offer, data, isView) {
+			renderable = append(renderable, offer)
+		}
+	}
+	switch offer := c.Accepts(renderable...); offer {
+	case "":
+		return ErrNotAcceptable
+	case MIMEHTML:
+		if isView {
+			return c.View(code, view.Name, view.Data)
+		}
+		return c.HTML(code, data.(string))
+	case MIMEPlain:
+		c.writeContentType(plainContentType)
+		return c.String(code, fmt.Sprint(data))
+	default:
+		r, _ := c.app.renderer(offer)
+		return c.render(code, r, data)
+	}
+}
+
+func (c *Ctx) canRender(offer string, data interface{}, isView bool) bool {
+	switch offer {
+	case MIMEHTML:
+		_, ok := data.(string)
+		return isView || ok
+	case MIMEPlain:
+		return true
+	}
+	_, ok := c.app.renderer(offer)
+	return ok
+}
+
+// Accepts returns the offered media type with the highest quality in the Accept
+// header, ties going to the earlier offer. It returns "" if none is acceptable.
+func (c *Ctx) Accepts(offers ...string) string {
+	return negotiate(c.Header(HeaderAccept), offers, matchMediaRange, "")
+}
+
+// AcceptsEncodings returns the best offered content coding of the Accept-Encoding header.
+func (c *Ctx) AcceptsEncodings(offers ...string) string {
+	return negotiate(c.Header(HeaderAcceptEncoding), offers, matchEncoding, "identity")
+}
+
+// AcceptsLanguages returns the best offered language tag of the Accept-Language header.
+func (c *Ctx) AcceptsLanguages(offers ...string) string {
+	return negotiate(c.Header(HeaderAcceptLanguage), offers, matchLanguage, "")
+}
+
+type acceptSpec struct {
+	value string
+	q     float64
+}
+
+// rangeMatcher reports whether the range matches the offer and how specific the match is.
+type rangeMatcher func(spec, offer string) (specificity int, ok bool)
+
+// negotiate picks the best offer for header. implicit names an offer that is
+// acceptable unless a range explicitly excludes it, like the identity coding.
+func negotiate(header string, offers []string, match rangeMatcher, implicit string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+	if header == "" {
+		return offers[0]
+	}
+	specs := parseAccept(header)
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		q, specificity := 0.0, -1
+		for _, spec := range specs {
+			if s, ok := match(spec.value, offer); ok && s > specificity {
+				q, specificity = spec.q, s
+			}
+		}
+		if specificity < 0 && implicit != "" && strings.EqualFold(offer, implicit) {
+			q = 1
+		}
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// parseAccept parses a comma separated list of values with optional q parameters (RFC 7231 5.3.1).
+func parseAccept(header string) []acceptSpec {
+	parts := strings.Split(header, ",")
+	specs := make([]acceptSpec, 0, len(parts))
+	for _, part := range parts {
+		params := strings.Split(part, ";")
+		value := strings.TrimSpace(params[0])
+		if value == "" {
+			continue
+		}
+		spec := acceptSpec{value: value, q: 1}
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if len(param) > 2 && (param[0] == 'q' || param[0] == 'Q') && param[1] == '=' {
+				q, err := strconv.ParseFloat(param[2:], 64)
+				if err != nil || q < 0 || q > 1 {
+					q = 0
+				}
+				spec.q = q
+				break
+			}
+		}
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+func matchMediaRange(spec, offer string) (int, bool) {
+	if i := strings.IndexByte(offer, ';'); i >= 0 {
+		offer = strings.TrimSpace(offer[:i])
+	}
+	if spec == "*/*" {
+		return 0, true
+	}
+	specType, specSub := splitMediaType(spec)
+	offerType, offerSub := splitMediaType(offer)
+	if !strings.EqualFold(specType, offerType) {
+		return 0, false
+	}
+	if specSub == "*" {
+		return 1, true
+	}
+	return 2, strings.EqualFold(specSub, offerSub)
+}
+
+func splitMediaType(mediaType string) (string, string) {
+	if i := strings.IndexByte(mediaType, '/'); i >= 0 {
+		return mediaType[:i], mediaType[i+1:]
+	}
+	return mediaType, ""
+}
+
+func matchEncoding(spec, offer string) (int, bool) {
+	if spec == "*" {
+		return 0, true
+	}
+	return 1, strings.EqualFold(spec, offer)
+}
+
+// matchLanguage implements basic filtering of language ranges (RFC 4647 3.3.1).
+func matchLanguage(spec, offer string) (int, bool) {
+	if spec == "*" {
+		return 0, true
+	}
+	if strings.EqualFold(spec, offer) {
+		return len(spec) + 1, true
+	}
+	if len(offer) > len(spec) && offer[len(spec)] == '-' && strings.EqualFold(offer[:len(spec)], spec) {
+		return len(spec), true
+	}
+	return 0, false
+}
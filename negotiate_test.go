@@ -0,0 +1,92 @@
+package bytego
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCtx_Accepts(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		accept func(c *Ctx, offers ...string) string
+		offers []string
+		want   string
+	}{
+		{"no header", "", (*Ctx).Accepts, []string{MIMEXML, MIMEJSON}, MIMEXML},
+		{"exact", "application/json", (*Ctx).Accepts, []string{MIMEXML, MIMEJSON}, MIMEJSON},
+		{"quality", "application/xml;q=0.5, application/json;q=0.8", (*Ctx).Accepts, []string{MIMEXML, MIMEJSON}, MIMEJSON},
+		{"specificity", "text/*;q=0.2, text/html, */*;q=0.1", (*Ctx).Accepts, []string{MIMEPlain, MIMEHTML}, MIMEHTML},
+		{"wildcard tie keeps offer order", "*/*", (*Ctx).Accepts, []string{MIMEXML, MIMEJSON}, MIMEXML},
+		{"excluded", "application/json;q=0, */*", (*Ctx).Accepts, []string{MIMEJSON}, ""},
+		{"none", "image/png", (*Ctx).Accepts, []string{MIMEJSON}, ""},
+		{"encoding", "gzip;q=0.5, br", (*Ctx).AcceptsEncodings, []string{"gzip", "br"}, "br"},
+		{"encoding identity", "gzip;q=0", (*Ctx).AcceptsEncodings, []string{"gzip", "identity"}, "identity"},
+		{"encoding identity excluded", "*;q=0", (*Ctx).AcceptsEncodings, []string{"identity"}, ""},
+		{"language prefix", "fr-CH, fr;q=0.9, en;q=0.8", (*Ctx).AcceptsLanguages, []string{"en-US", "fr-FR"}, "fr-FR"},
+		{"language exact", "en-US, en;q=0.5", (*Ctx).AcceptsLanguages, []string{"en-GB", "en-US"}, "en-US"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(HeaderAccept, tt.header)
+			req.Header.Set(HeaderAcceptEncoding, tt.header)
+			req.Header.Set(HeaderAcceptLanguage, tt.header)
+			if got := tt.accept(newTestCtx(New(), req), tt.offers...); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCtx_Negotiate(t *testing.T) {
+	data := Map{"name": "bytego"}
+	tests := []struct {
+		accept      string
+		code        int
+		contentType string
+		body        string
+	}{
+		{"application/json", http.StatusOK, jsonContentType, `{"name":"bytego"}`},
+		{"application/x-yaml", http.StatusOK, yamlContentType, "name: bytego\n"},
+		{"text/plain", http.StatusOK, plainContentType, "map[name:bytego]"},
+		{"image/png", http.StatusNotAcceptable, jsonContentType, `"code":406`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			app := New()
+			app.GET("/", func(c *Ctx) error {
+				return c.Negotiate(http.StatusOK, data)
+			})
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(HeaderAccept, tt.accept)
+			rec := httptest.NewRecorder()
+			app.Handler().ServeHTTP(rec, req)
+			if rec.Code != tt.code || rec.Header().Get("Content-Type") != tt.contentType || !strings.Contains(rec.Body.String(), tt.body) {
+				t.Errorf("Negotiate() = %d %s %q", rec.Code, rec.Header().Get("Content-Type"), rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestCtx_Negotiate_Unrenderable(t *testing.T) {
+	app := New()
+	app.GET("/", func(c *Ctx) error {
+		return c.Negotiate(http.StatusOK, Map{"name": "bytego"}, MIMEHTML, "application/vnd.bytego", MIMEJSON)
+	})
+	for _, accept := range []string{
+		"text/html, application/json;q=0.5",
+		"application/vnd.bytego, application/json;q=0.5",
+		"*/*",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(HeaderAccept, accept)
+		rec := httptest.NewRecorder()
+		app.Handler().ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != jsonContentType {
+			t.Errorf("%s: Negotiate() = %d %s %q", accept, rec.Code, rec.Header().Get("Content-Type"), rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package bytego
+
+import (
+	"net"
+	"strings"
+)
+
+// SetTrustedProxies sets the proxies, as IP addresses or CIDR ranges, whose
+// Forwarded, X-Forwarded-For, X-Real-Ip, X-Forwarded-Proto and
+// X-Forwarded-Host headers are honored by Ctx.ClientIP, Ctx.Scheme and
+// Ctx.Host. No proxy is trusted by default.
+func (a *App) SetTrustedProxies(proxies []string) error {
+	nets := make([]*net.IPNet, 0, len(proxies))
+	for _, p := range proxies {
+		p = strings.TrimSpace(p)
+		if !strings.Contains(p, "/") {
+			ip := net.ParseIP(p)
+			if ip == nil {
+				return &net.ParseError{Type: "IP address", Text: p}
+			}
+			if ip4 := ip.To4(); ip4 != nil {
+				ip = ip4
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(p)
+		if err != nil {
+			return err
+		}
+		nets = append(nets, ipNet)
+	}
+	a.trustedProxies = nets
+	return nil
+}
+
+func (a *App) isTrustedProxy(ip net.IP) bool {
+	for _, n := range a.trustedProxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// hop is the client end of the proxy chain with the scheme and host it used,
+// which are empty when not forwarded by a trusted proxy.
+type hop struct {
+	ip    string
+	proto string
+	host  string
+}
+
+// clientHop returns the forwardedHop of the request, parsed once.
+func (c *Ctx) clientHop() hop {
+	if !c.hopParsed {
+		c.hop, c.hopParsed = c.forwardedHop(), true
+	}
+	return c.hop
+}
+
+// forwardedHop walks the forwarding headers from right to left, starting at
+// the direct peer, and stops at the first address that is not a trusted proxy.
+// The Forwarded header takes precedence over the X-Forwarded ones.
+func (c *Ctx) forwardedHop() hop {
+	remote := c.RemoteIP()
+	ip := net.ParseIP(remote)
+	if ip == nil || !c.app.isTrustedProxy(ip) {
+		return hop{ip: remote}
+	}
+	header := c.Request.Header
+	if values := header.Values(HeaderForwarded); len(values) > 0 {
+		elems := parseForwarded(values)
+		prev := remote
+		for i := len(elems) - 1; i >= 0; i-- {
+			ip := parseNode(elems[i].node)
+			if ip == nil || !c.app.isTrustedProxy(ip) {
+				if ip != nil {
+					prev = ip.String()
+				}
+				return hop{ip: prev, proto: elems[i].proto, host: elems[i].host}
+			}
+			prev = ip.String()
+		}
+		if len(elems) > 0 {
+			return hop{ip: prev, proto: elems[0].proto, host: elems[0].host}
+		}
+	}
+	h := hop{ip: remote}
+	// n counts the proxies, from the direct peer, that forwarded h.ip
+	n := 0
+	if values := header.Values(HeaderXForwardedFor); len(values) > 0 {
+		addrs := splitValues(values)
+		for i := len(addrs) - 1; i >= 0; i-- {
+			ip := net.ParseIP(addrs[i])
+			if ip == nil {
+				break
+			}
+			h.ip, n = ip.String(), len(addrs)-i
+			if !c.app.isTrustedProxy(ip) {
+				break
+			}
+		}
+	} else if ip := net.ParseIP(strings.TrimSpace(header.Get(HeaderXRealIP))); ip != nil {
+		h.ip, n = ip.String(), 1
+	}
+	h.proto = hopValue(splitValues(header.Values(HeaderXForwardedProto)), n)
+	h.host = hopValue(splitValues(header.Values(HeaderXForwardedHost)), n)
+	return h
+}
+
+type forwardedElement struct {
+	node  string
+	proto string
+	host  string
+}
+
+// parseForwarded parses the elements of Forwarded headers (RFC 7239).
+func parseForwarded(values []string) []forwardedElement {
+	var elems []forwardedElement
+	for _, v := range values {
+		for _, e := range splitQuoted(v, ',') {
+			var elem forwardedElement
+			for _, pair := range splitQuoted(e, ';') {
+				i := strings.IndexByte(pair, '=')
+				if i < 0 {
+					continue
+				}
+				value := strings.TrimSpace(pair[i+1:])
+				if len(value) > 1 && value[0] == '"' && value[len(value)-1] == '"' {
+					value = strings.ReplaceAll(value[1:len(value)-1], `\`, "")
+				}
+				switch strings.ToLower(strings.TrimSpace(pair[:i])) {
+				case "for":
+					elem.node = value
+				case "proto":
+					elem.proto = value
+				case "host":
+					elem.host = value
+				}
+			}
+			elems = append(elems, elem)
+		}
+	}
+	return elems
+}
+
+// splitQuoted splits s at sep outside of quoted strings.
+func splitQuoted(s string, sep byte) []string {
+	var parts []string
+	quoted, start := false, 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && quoted:
+			i++
+		case s[i] == '"':
+			quoted = !quoted
+		case s[i] == sep && !quoted:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// parseNode returns the IP of a Forwarded node such as 192.0.2.1:80 or
+// "[2001:db8::1]:80", or nil for "unknown" and obfuscated identifiers.
+func parseNode(node string) net.IP {
+	if strings.HasPrefix(node, "[") {
+		if i := strings.IndexByte(node, ']'); i > 0 {
+			return net.ParseIP(node[1:i])
+		}
+		return nil
+	}
+	if host, _, err := net.SplitHostPort(node); err == nil {
+		node = host
+	}
+	return net.ParseIP(node)
+}
+
+func splitValues(values []string) []string {
+	var list []string
+	for _, v := range values {
+		for _, item := range strings.Split(v, ",") {
+			list = append(list, strings.TrimSpace(item))
+		}
+	}
+	return list
+}
+
+// hopValue returns the X-Forwarded-Proto or -Host entry appended by the n-th
+// proxy from the right, the one the client connected to. Entries left of it
+// were sent by the client. When there are fewer entries than proxies, the
+// leftmost one was passed on unchanged by the trusted proxies.
+func hopValue(list []string, n int) string {
+	if len(list) == 0 {
+		return ""
+	}
+	if n < 1 {
+		n = 1
+	}
+	if n > len(list) {
+		n = len(list)
+	}
+	return list[len(list)-n]
+}
+
+// ClientIP returns the address of the client, taken from the forwarding
+// headers when the request comes through trusted proxies, see
+// App.SetTrustedProxies, and the remote address otherwise.
+func (c *Ctx) ClientIP() string {
+	return c.clientHop().ip
+}
+
+// Scheme returns "https" or "http", as forwarded by trusted proxies or else
+// depending on whether the connection uses TLS.
+func (c *Ctx) Scheme() string {
+	if proto := strings.ToLower(c.clientHop().proto); proto == "https" || proto == "http" {
+		return proto
+	}
+	if c.Request.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// Host returns the host requested by the client, as forwarded by trusted
+// proxies or else from the request.
+func (c *Ctx) Host() string {
+	if host := c.clientHop().host; host != "" && validHost(host) {
+		return host
+	}
+	return c.Request.Host
+}
+
+func validHost(host string) bool {
+	for i := 0; i < len(host); i++ {
+		if b := host[i]; b <= ' ' || b > '~' || b == '/' || b == '\\' || b == '@' {
+			return false
+		}
+	}
+	return true
+}
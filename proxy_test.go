@@ -0,0 +1,105 @@
+package bytego
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCtx_ClientIP(t *testing.T) {
+	app := New()
+	if err := app.SetTrustedProxies([]string{"10.0.0.0/8", "2001:db8::1"}); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		remote string
+		header map[string][]string
+		want   string
+	}{
+		{"no proxy", "203.0.113.1:80", nil, "203.0.113.1"},
+		{"untrusted peer", "203.0.113.1:80", map[string][]string{HeaderXForwardedFor: {"1.1.1.1"}, HeaderXRealIP: {"2.2.2.2"}}, "203.0.113.1"},
+		{"xff", "10.0.0.1:80", map[string][]string{HeaderXForwardedFor: {"1.1.1.1"}}, "1.1.1.1"},
+		{"xff spoofed", "10.0.0.1:80", map[string][]string{HeaderXForwardedFor: {"6.6.6.6, 1.1.1.1, 10.0.0.2"}}, "1.1.1.1"},
+		{"xff multiple headers", "10.0.0.1:80", map[string][]string{HeaderXForwardedFor: {"6.6.6.6", "1.1.1.1, 10.0.0.2"}}, "1.1.1.1"},
+		{"xff all trusted", "10.0.0.1:80", map[string][]string{HeaderXForwardedFor: {"10.0.0.3, 10.0.0.2"}}, "10.0.0.3"},
+		{"xff garbage", "10.0.0.1:80", map[string][]string{HeaderXForwardedFor: {"1.1.1.1, garbage"}}, "10.0.0.1"},
+		{"x-real-ip", "10.0.0.1:80", map[string][]string{HeaderXRealIP: {"1.1.1.1"}}, "1.1.1.1"},
+		{"ipv6 peer", "[2001:db8::1]:80", map[string][]string{HeaderXForwardedFor: {"1.1.1.1"}}, "1.1.1.1"},
+		{"forwarded", "10.0.0.1:80", map[string][]string{
+			HeaderForwarded:     {`for=6.6.6.6, for="[2001:db8::2]:4711";proto=https, for=10.0.0.2`},
+			HeaderXForwardedFor: {"7.7.7.7"},
+		}, "2001:db8::2"},
+		{"forwarded unknown", "10.0.0.1:80", map[string][]string{HeaderForwarded: {"for=unknown, for=10.0.0.2"}}, "10.0.0.2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remote
+			for k, v := range tt.header {
+				req.Header[k] = v
+			}
+			if got := newTestCtx(app, req).ClientIP(); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if err := app.SetTrustedProxies([]string{"10.0.0.0/33"}); err == nil {
+		t.Error("expected error for invalid CIDR")
+	}
+	if err := app.SetTrustedProxies([]string{"proxy"}); err == nil {
+		t.Error("expected error for invalid IP")
+	}
+}
+
+func TestCtx_SchemeHost(t *testing.T) {
+	app := New()
+	_ = app.SetTrustedProxies([]string{"10.0.0.1", "10.0.0.2"})
+	tests := []struct {
+		name   string
+		remote string
+		tls    bool
+		header map[string]string
+		scheme string
+		host   string
+	}{
+		{"direct", "203.0.113.1:80", false, nil, "http", "example.com"},
+		{"tls", "203.0.113.1:80", true, nil, "https", "example.com"},
+		{"untrusted", "203.0.113.1:80", false, map[string]string{HeaderXForwardedProto: "https", HeaderXForwardedHost: "evil.com"}, "http", "example.com"},
+		{"x-forwarded", "10.0.0.1:80", false, map[string]string{HeaderXForwardedFor: "1.1.1.1", HeaderXForwardedProto: "https", HeaderXForwardedHost: "app.com"}, "https", "app.com"},
+		{"x-forwarded spoofed", "10.0.0.1:80", false, map[string]string{
+			HeaderXForwardedFor: "6.6.6.6, 1.1.1.1", HeaderXForwardedProto: "http, https", HeaderXForwardedHost: "evil.com, app.com",
+		}, "https", "app.com"},
+		{"x-forwarded hops", "10.0.0.1:80", false, map[string]string{
+			HeaderXForwardedFor: "1.1.1.1, 10.0.0.2", HeaderXForwardedProto: "https, http", HeaderXForwardedHost: "app.com, proxy.local",
+		}, "https", "app.com"},
+		{"x-forwarded passed on", "10.0.0.1:80", false, map[string]string{HeaderXForwardedFor: "1.1.1.1, 10.0.0.2", HeaderXForwardedProto: "https"}, "https", "example.com"},
+		{"x-forwarded without for", "10.0.0.1:80", false, map[string]string{HeaderXForwardedHost: "evil.com, app.com"}, "http", "app.com"},
+		{"forwarded", "10.0.0.1:80", false, map[string]string{HeaderForwarded: `for=1.1.1.1;proto=https;host="app.com:8443"`}, "https", "app.com:8443"},
+		{"invalid", "10.0.0.1:80", false, map[string]string{HeaderXForwardedProto: "gopher", HeaderXForwardedHost: "a/b"}, "http", "example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+			req.RemoteAddr = tt.remote
+			if tt.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			c := newTestCtx(app, req)
+			if got := c.Scheme(); got != tt.scheme {
+				t.Errorf("Scheme() = %q, want %q", got, tt.scheme)
+			}
+			if got := c.Host(); got != tt.host {
+				t.Errorf("Host() = %q, want %q", got, tt.host)
+			}
+			req.Header.Set(HeaderXForwardedHost, "changed.com")
+			if got := c.Host(); got != tt.host {
+				t.Errorf("Host() = %q after a header change, want the parsed %q", got, tt.host)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package bytego
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gostack-labs/bytego/internal/msgpack"
+	"github.com/gostack-labs/bytego/internal/respctl"
+	"github.com/gostack-labs/bytego/internal/toml"
+	"github.com/gostack-labs/bytego/internal/yaml"
+)
+
+const (
+	MIMETOML    = "application/toml"
+	MIMEMsgPack = "application/msgpack"
+	MIMECSV     = "text/csv"
+)
+
+// RenderFunc writes the encoding of v to w. The status and content type are
+// committed with the first write, so a RenderFunc failing before it writes
+// leaves the response untouched.
+type RenderFunc func(w io.Writer, v interface{}) error
+
+// ProtoMarshaler is implemented by generated protobuf messages that encode
+// themselves. Messages of google.golang.org/protobuf can be wrapped in a type
+// whose Marshal calls proto.Marshal.
+type ProtoMarshaler interface {
+	Marshal() ([]byte, error)
+}
+
+var errCSVRows = errors.New("csv rows must be [][]string or a channel of []string")
+
+type renderer struct {
+	contentType string
+	render      RenderFunc
+}
+
+func (a *App) defaultRenderers() map[string]renderer {
+	renderJSON := func(w io.Writer, v interface{}) error {
+		b, err := a.jsonCodec.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	}
+	renderXML := func(w io.Writer, v interface{}) error {
+		b, err := xml.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	}
+	return map[string]renderer{
+		MIMEJSON:     {jsonContentType, renderJSON},
+		MIMEXML:      {xmlContentType, renderXML},
+		MIMEXML2:     {"text/xml; charset=utf-8", renderXML},
+		MIMEYAML:     {yamlContentType, renderYAML},
+		MIMETOML:     {"application/toml; charset=utf-8", renderTOML},
+		MIMEMsgPack:  {MIMEMsgPack, renderMsgPack},
+		MIMEPROTOBUF: {MIMEPROTOBUF, renderProtoBuf},
+		MIMECSV:      {"text/csv; charset=utf-8", renderCSV},
+	}
+}
+
+// RegisterRenderer sets the renderer used by Ctx.Render and Ctx.Negotiate for
+// the media type of contentType, which is also written as the Content-Type.
+// Renderers must be registered before the app serves requests.
+func (a *App) RegisterRenderer(contentType string, fn RenderFunc) {
+	if fn == nil {
+		return
+	}
+	a.renderers[mediaType(contentType)] = renderer{contentType: contentType, render: fn}
+}
+
+func (a *App) renderer(contentType string) (renderer, bool) {
+	r, ok := a.renderers[mediaType(contentType)]
+	return r, ok
+}
+
+func mediaType(contentType string) string {
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+// Render writes v with the renderer registered for the media type of contentType.
+func (c *Ctx) Render(code int, contentType string, v interface{}) error {
+	r, ok := c.app.renderer(contentType)
+	if !ok {
+		return errors.New("no renderer registered for " + contentType)
+	}
+	return c.render(code, r, v)
+}
+
+func (c *Ctx) render(code int, r renderer, v interface{}) error {
+	w := &renderWriter{c: c, code: code, contentType: r.contentType}
+	if err := r.render(w, v); err != nil {
+		return err
+	}
+	w.commit()
+	return nil
+}
+
+func (c *Ctx) YAML(code int, i interface{}) error {
+	return c.Render(code, MIMEYAML, i)
+}
+
+func (c *Ctx) TOML(code int, i interface{}) error {
+	return c.Render(code, MIMETOML, i)
+}
+
+func (c *Ctx) MsgPack(code int, i interface{}) error {
+	return c.Render(code, MIMEMsgPack, i)
+}
+
+// ProtoBuf writes i, which must be a ProtoMarshaler.
+func (c *Ctx) ProtoBuf(code int, i interface{}) error {
+	return c.Render(code, MIMEPROTOBUF, i)
+}
+
+// CSV writes rows given as [][]string, or streams them from a channel of
+// []string until it is closed, flushing after every row.
+func (c *Ctx) CSV(code int, rows interface{}) error {
+	return c.Render(code, MIMECSV, rows)
+}
+
+// renderWriter commits the status and content type with the first write.
+type renderWriter struct {
+	c           *Ctx
+	code        int
+	contentType string
+}
+
+func (w *renderWriter) Write(b []byte) (int, error) {
+	w.commit()
+	return w.c.Response.Write(b)
+}
+
+func (w *renderWriter) Flush() {
+	w.commit()
+	_ = respctl.Flush(w.c.Response)
+}
+
+func (w *renderWriter) commit() {
+	if !w.c.Response.Committed() {
+		w.c.writeContentType(w.contentType)
+		w.c.Status(w.code)
+	}
+}
+
+func renderYAML(w io.Writer, v interface{}) error {
+	return yaml.NewEncoder(w).Encode(v)
+}
+
+func renderTOML(w io.Writer, v interface{}) error {
+	return toml.NewEncoder(w).Encode(v)
+}
+
+func renderMsgPack(w io.Writer, v interface{}) error {
+	return msgpack.NewEncoder(w).Encode(v)
+}
+
+func renderProtoBuf(w io.Writer, v interface{}) error {
+	m, ok := v.(ProtoMarshaler)
+	if !ok {
+		return errors.New("protobuf renderer requires a ProtoMarshaler")
+	}
+	b, err := m.Marshal()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+func renderCSV(w io.Writer, v interface{}) error {
+	cw := csv.NewWriter(w)
+	switch rows := v.(type) {
+	case [][]string:
+		return cw.WriteAll(rows)
+	case <-chan []string:
+		return streamCSV(w, cw, rows)
+	case chan []string:
+		return streamCSV(w, cw, rows)
+	}
+	return errCSVRows
+}
+
+func streamCSV(w io.Writer, cw *csv.Writer, rows <-chan []string) error {
+	flusher, _ := w.(http.Flusher)
+	for row := range rows {
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	return nil
+}
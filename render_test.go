@@ -0,0 +1,156 @@
+package bytego
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type testProto struct{ b []byte }
+
+func (m testProto) Marshal() ([]byte, error) { return m.b, nil }
+
+func TestCtx_Renderers(t *testing.T) {
+	type server struct {
+		Host  string   `toml:"host"`
+		Ports []int    `toml:"ports"`
+		Tags  []string `toml:"tags,omitempty"`
+	}
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name        string
+		render      func(c *Ctx) error
+		contentType string
+		want        string
+	}{
+		{"toml", func(c *Ctx) error {
+			return c.TOML(200, Map{
+				"title":   "a \"b\"",
+				"ratio":   1.0,
+				"created": created,
+				"owner":   Map{"name": "bytego"},
+				"servers": []server{{Host: "a", Ports: []int{80, 443}}, {Host: "b"}},
+			})
+		}, "application/toml; charset=utf-8",
+			"created = 2024-01-02T03:04:05Z\nratio = 1.0\ntitle = \"a \\\"b\\\"\"\n\n[owner]\nname = \"bytego\"\n\n" +
+				"[[servers]]\nhost = \"a\"\nports = [80, 443]\n\n[[servers]]\nhost = \"b\"\nports = []\n"},
+		{"msgpack", func(c *Ctx) error {
+			return c.MsgPack(200, Map{"a": 1, "b": []interface{}{true, nil, -1, "x"}, "c": 300})
+		}, MIMEMsgPack,
+			"\x83\xa1a\x01\xa1b\x94\xc3\xc0\xff\xa1x\xa1c\xcd\x01\x2c"},
+		{"protobuf", func(c *Ctx) error { return c.ProtoBuf(200, testProto{[]byte{0x08, 0x96, 0x01}}) },
+			MIMEPROTOBUF, "\x08\x96\x01"},
+		{"csv", func(c *Ctx) error { return c.CSV(200, [][]string{{"id", "name"}, {"1", "a,b"}}) },
+			"text/csv; charset=utf-8", "id,name\n1,\"a,b\"\n"},
+		{"yaml", func(c *Ctx) error { return c.YAML(200, Map{"a": 1}) },
+			yamlContentType, "a: 1\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			c := newTestCtx(New(), httptest.NewRequest(http.MethodGet, "/", nil))
+			c.writer = newResponseWriter(rec, c.app)
+			c.Response = c.writer
+			if err := tt.render(c); err != nil {
+				t.Fatal(err)
+			}
+			if rec.Body.String() != tt.want {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.want)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != tt.contentType {
+				t.Errorf("Content-Type = %q, want %q", ct, tt.contentType)
+			}
+		})
+	}
+}
+
+func TestCtx_RenderErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		render func(c *Ctx) error
+	}{
+		{"protobuf", func(c *Ctx) error { return c.ProtoBuf(200, Map{}) }},
+		{"toml", func(c *Ctx) error { return c.TOML(200, []int{1}) }},
+		{"csv", func(c *Ctx) error { return c.CSV(200, "a,b") }},
+		{"unknown", func(c *Ctx) error { return c.Render(200, "application/x-unknown", nil) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			c := newTestCtx(New(), httptest.NewRequest(http.MethodGet, "/", nil))
+			c.writer = newResponseWriter(rec, c.app)
+			c.Response = c.writer
+			if err := tt.render(c); err == nil {
+				t.Fatal("error = nil")
+			}
+			if c.Response.Committed() {
+				t.Error("response committed on error")
+			}
+		})
+	}
+}
+
+func TestCtx_CSVStream(t *testing.T) {
+	app := New()
+	app.GET("/export", func(c *Ctx) error {
+		rows := make(chan []string)
+		go func() {
+			defer close(rows)
+			for _, row := range [][]string{{"id"}, {"1"}, {"2"}} {
+				rows <- row
+			}
+		}()
+		return c.CSV(200, rows)
+	})
+	rec := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/export", nil))
+	if rec.Body.String() != "id\n1\n2\n" || !rec.Flushed {
+		t.Errorf("body = %q, flushed = %v", rec.Body.String(), rec.Flushed)
+	}
+}
+
+func TestApp_RegisterRenderer(t *testing.T) {
+	app := New()
+	app.RegisterRenderer("application/vnd.bytego+text; charset=utf-8", func(w io.Writer, v interface{}) error {
+		_, err := io.WriteString(w, "bytego:"+v.(string))
+		return err
+	})
+	app.GET("/", func(c *Ctx) error {
+		return c.Negotiate(200, "hi", MIMEJSON, "application/vnd.bytego+text", MIMEMsgPack)
+	})
+	tests := []struct {
+		accept      string
+		contentType string
+		want        []byte
+		err         error
+	}{
+		{"application/vnd.bytego+text", "application/vnd.bytego+text; charset=utf-8", []byte("bytego:hi"), nil},
+		{"application/msgpack", MIMEMsgPack, []byte("\xa2hi"), nil},
+		{"application/toml", "", nil, ErrNotAcceptable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(HeaderAccept, tt.accept)
+			var gotErr error
+			app.SetErrorHandler(func(err error, c *Ctx) {
+				gotErr = err
+			})
+			rec := httptest.NewRecorder()
+			app.Handler().ServeHTTP(rec, req)
+			if !errors.Is(gotErr, tt.err) {
+				t.Fatalf("error = %v, want %v", gotErr, tt.err)
+			}
+			if tt.err != nil {
+				return
+			}
+			if !bytes.Equal(rec.Body.Bytes(), tt.want) || rec.Header().Get("Content-Type") != tt.contentType {
+				t.Errorf("got %q %q", rec.Header().Get("Content-Type"), rec.Body.Bytes())
+			}
+		})
+	}
+}
@@ -1,10 +1,16 @@
 package bytego
 
 import (
+	"bufio"
+	"errors"
 	"io"
+	"net"
 	"net/http"
 )
 
+// ResponseWriter is the writer of Ctx.Response. Implementations wrapping
+// another writer should also implement http.Flusher, http.Hijacker and
+// Unwrap() http.ResponseWriter, which streaming and websockets rely on.
 type ResponseWriter interface {
 	http.ResponseWriter
 	Status() int
@@ -74,3 +80,50 @@ func (w *responseWriter) writeStatusrCheck() {
 		w.WriteHeader(w.status)
 	}
 }
+
+// Flush commits the status and sends any buffered data to the client.
+func (w *responseWriter) Flush() {
+	w.writeStatusrCheck()
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("http.Hijacker is not supported by the response writer")
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil {
+		w.committed = true
+	}
+	return conn, rw, err
+}
+
+func (w *responseWriter) Push(target string, opts *http.PushOptions) error {
+	if p, ok := w.ResponseWriter.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+func (w *responseWriter) ReadFrom(r io.Reader) (n int64, err error) {
+	w.writeStatusrCheck()
+	if rf, ok := w.ResponseWriter.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(r)
+	} else {
+		n, err = io.Copy(writerOnly{w.ResponseWriter}, r)
+	}
+	w.size += int(n)
+	return
+}
+
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// writerOnly hides the io.ReaderFrom of a writer to avoid io.Copy recursion.
+type writerOnly struct {
+	io.Writer
+}
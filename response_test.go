@@ -0,0 +1,88 @@
+package bytego
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCtx_Stream(t *testing.T) {
+	app := New()
+	app.GET("/", func(c *Ctx) error {
+		i := 0
+		c.Stream(func(w io.Writer) bool {
+			i++
+			_, _ = io.WriteString(w, "chunk;")
+			return i < 3
+		})
+		return nil
+	})
+	rec := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if !rec.Flushed || rec.Body.String() != "chunk;chunk;chunk;" {
+		t.Errorf("Stream() flushed=%v body=%q", rec.Flushed, rec.Body.String())
+	}
+}
+
+// unwrapWriter is a wrapper implementing ResponseWriter and Unwrap only.
+type unwrapWriter struct {
+	ResponseWriter
+}
+
+func (w *unwrapWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func TestCtx_Stream_Wrapped(t *testing.T) {
+	app := New()
+	app.Use(func(c *Ctx) error {
+		c.Response = &unwrapWriter{c.Response}
+		return c.Next()
+	})
+	app.GET("/", func(c *Ctx) error {
+		c.Stream(func(w io.Writer) bool {
+			_, _ = io.WriteString(w, "chunk;")
+			return false
+		})
+		return nil
+	})
+	rec := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if !rec.Flushed || rec.Body.String() != "chunk;" {
+		t.Errorf("Stream() flushed=%v body=%q", rec.Flushed, rec.Body.String())
+	}
+}
+
+func TestCtx_DataFromReader(t *testing.T) {
+	app := New()
+	app.GET("/", func(c *Ctx) error {
+		if _, ok := c.Response.(io.ReaderFrom); !ok {
+			t.Error("response writer does not implement io.ReaderFrom")
+		}
+		if u, ok := c.Response.(interface{ Unwrap() http.ResponseWriter }); !ok || u.Unwrap() == nil {
+			t.Error("response writer does not unwrap")
+		}
+		body := strings.NewReader("hello")
+		return c.DataFromReader(http.StatusOK, int64(body.Len()), "text/plain", body, map[string]string{
+			"Content-Disposition": `attachment; filename="hello.txt"`,
+		})
+	})
+	rec := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Body.String() != "hello" || rec.Header().Get(HeaderContentLength) != "5" ||
+		rec.Header().Get("Content-Disposition") == "" {
+		t.Errorf("DataFromReader() = %v %q", rec.Header(), rec.Body.String())
+	}
+}
+
+func TestResponseWriter_Hijack(t *testing.T) {
+	w := newResponseWriter(httptest.NewRecorder(), New())
+	if _, _, err := w.Hijack(); err == nil {
+		t.Error("Hijack() error = nil for a writer without http.Hijacker")
+	}
+	if err := w.Push("/app.js", nil); err != http.ErrNotSupported {
+		t.Errorf("Push() error = %v", err)
+	}
+}
@@ -61,6 +61,9 @@ func (r *router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	ctx.writer = newResponseWriter(w, r.app)
 	ctx.Response = ctx.writer
 	defer r.pool.Put(ctx)
+	if r.app.bodyLimit > 0 {
+		ctx.SetBodyLimit(r.app.bodyLimit)
+	}
 
 	path := req.URL.Path
 	if root := r.trees[req.Method]; root != nil {
@@ -0,0 +1,115 @@
+package bytego
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gostack-labs/bytego/internal/respctl"
+)
+
+const sseContentType = "text/event-stream"
+
+// SSEvent is a single Server-Sent Event. Data is written as is when it is a
+// string or []byte and JSON encoded otherwise.
+type SSEvent struct {
+	ID    string
+	Event string
+	Retry time.Duration
+	Data  interface{}
+}
+
+// SSEWriter sends events on a stream opened by Ctx.SSEStream.
+type SSEWriter struct {
+	c *Ctx
+	// LastEventID is the Last-Event-ID sent by a reconnecting client.
+	LastEventID string
+}
+
+// Send writes and flushes ev. It fails once the request context is cancelled.
+func (w *SSEWriter) Send(ev SSEvent) error {
+	return w.c.writeSSEvent(ev)
+}
+
+// SSEvent writes and flushes a single event with the given name and data.
+func (c *Ctx) SSEvent(event string, data interface{}) error {
+	return c.writeSSEvent(SSEvent{Event: event, Data: data})
+}
+
+// SSEStream opens an event stream and calls step until it returns false or the
+// client goes away. It reports whether the client disconnected.
+func (c *Ctx) SSEStream(step func(w *SSEWriter) bool) bool {
+	c.setSSEHeaders()
+	w := &SSEWriter{c: c, LastEventID: c.Header(HeaderLastEventID)}
+	return c.Stream(func(io.Writer) bool {
+		return step(w)
+	})
+}
+
+func (c *Ctx) setSSEHeaders() {
+	if c.Response.Committed() {
+		return
+	}
+	header := c.Response.Header()
+	header.Set("Content-Type", sseContentType)
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+}
+
+func (c *Ctx) writeSSEvent(ev SSEvent) error {
+	if err := c.Context().Err(); err != nil {
+		return err
+	}
+	c.setSSEHeaders()
+	var buf bytes.Buffer
+	if ev.ID != "" {
+		writeSSEField(&buf, "id", sseFieldValue(ev.ID))
+	}
+	if ev.Event != "" {
+		writeSSEField(&buf, "event", sseFieldValue(ev.Event))
+	}
+	if ev.Retry > 0 {
+		writeSSEField(&buf, "retry", strconv.FormatInt(ev.Retry.Milliseconds(), 10))
+	}
+	var data string
+	switch d := ev.Data.(type) {
+	case nil:
+	case string:
+		data = d
+	case []byte:
+		data = string(d)
+	default:
+		b, err := c.app.jsonCodec.Marshal(d)
+		if err != nil {
+			return err
+		}
+		data = string(b)
+	}
+	data = strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(data)
+	for _, line := range strings.Split(data, "\n") {
+		writeSSEField(&buf, "data", line)
+	}
+	buf.WriteByte('\n')
+	if _, err := c.Response.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	_ = respctl.Flush(c.Response)
+	return nil
+}
+
+func writeSSEField(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+	buf.WriteString(": ")
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+// sseFieldValue removes line breaks, which would end the field early.
+func sseFieldValue(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
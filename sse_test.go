@@ -0,0 +1,45 @@
+package bytego
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCtx_SSEStream(t *testing.T) {
+	app := New()
+	var lastEventID string
+	app.GET("/events", func(c *Ctx) error {
+		n := 0
+		c.SSEStream(func(w *SSEWriter) bool {
+			lastEventID = w.LastEventID
+			n++
+			switch n {
+			case 1:
+				_ = w.Send(SSEvent{ID: "8", Event: "message", Retry: 3 * time.Second, Data: "line1\nline2"})
+			case 2:
+				_ = w.Send(SSEvent{ID: "9\n", Data: Map{"n": 2}})
+			}
+			return n < 2
+		})
+		return c.SSEvent("done", nil)
+	})
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	req.Header.Set(HeaderLastEventID, "7")
+	rec := httptest.NewRecorder()
+	app.Handler().ServeHTTP(rec, req)
+
+	want := "id: 8\nevent: message\nretry: 3000\ndata: line1\ndata: line2\n\n" +
+		"id: 9\ndata: {\"n\":2}\n\n" +
+		"event: done\ndata: \n\n"
+	if rec.Body.String() != want {
+		t.Errorf("body = %q, want %q", rec.Body.String(), want)
+	}
+	if rec.Header().Get("Content-Type") != sseContentType || !rec.Flushed {
+		t.Errorf("headers = %v, flushed = %v", rec.Header(), rec.Flushed)
+	}
+	if lastEventID != "7" {
+		t.Errorf("LastEventID = %q", lastEventID)
+	}
+}
@@ -0,0 +1,598 @@
+package bytego
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const validateTag = "validate"
+
+// RuleFunc reports whether field satisfies a validation rule. param is the text
+// after '=' in the tag and parent is the struct that contains the field.
+type RuleFunc func(field reflect.Value, param string, parent reflect.Value) bool
+
+// ValidationError describes a field that failed a validation rule.
+type ValidationError struct {
+	Field   string
+	Rule    string
+	Param   string
+	Value   interface{}
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// ValidationErrors aggregates the ValidationError of every failed field.
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Validator is the built-in struct validator driven by `validate` tags, e.g.
+// `validate:"required,min=3,max=20"`.
+type Validator struct {
+	mu    sync.RWMutex
+	rules map[string]RuleFunc
+	plans sync.Map
+	// report is called once per struct type with unknown rules.
+	report func(err error)
+}
+
+type validateRule struct {
+	name  string
+	param string
+	fn    RuleFunc
+}
+
+type validateField struct {
+	index     int
+	name      string
+	required  bool
+	omitempty bool
+	rules     []validateRule
+
+	// rules applied to every element after `dive`
+	diveRequired bool
+	dive         []validateRule
+}
+
+type validatePlan struct {
+	fields []validateField
+	err    error
+}
+
+func NewValidator() *Validator {
+	v := &Validator{rules: make(map[string]RuleFunc, len(builtinRules))}
+	for name, fn := range builtinRules {
+		v.rules[name] = fn
+	}
+	return v
+}
+
+// RegisterRule adds or replaces the rule called name.
+func (v *Validator) RegisterRule(name string, fn RuleFunc) {
+	if name == "" || fn == nil {
+		return
+	}
+	v.mu.Lock()
+	v.rules[name] = fn
+	v.mu.Unlock()
+	v.plans.Range(func(key, _ interface{}) bool {
+		v.plans.Delete(key)
+		return true
+	})
+}
+
+// Validate validates the struct, or pointer to struct, i. Failed rules are
+// returned as ValidationErrors.
+func (v *Validator) Validate(i interface{}) error {
+	val := reflect.ValueOf(i)
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return nil
+	}
+	var errs ValidationErrors
+	if err := v.validateStruct(val, "", &errs); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Check reports the unknown rules in the validate tags of the structs i and of
+// the structs they contain, e.g. to fail at startup. Unknown rules are ignored
+// by Validate.
+func (v *Validator) Check(i ...interface{}) error {
+	var msgs []string
+	seen := make(map[reflect.Type]bool)
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct || seen[t] {
+			return
+		}
+		seen[t] = true
+		if err := v.plan(t).err; err != nil {
+			msgs = append(msgs, err.Error())
+		}
+		for j := 0; j < t.NumField(); j++ {
+			if t.Field(j).PkgPath == "" {
+				walk(t.Field(j).Type)
+			}
+		}
+	}
+	for _, x := range i {
+		if x != nil {
+			walk(reflect.TypeOf(x))
+		}
+	}
+	if len(msgs) > 0 {
+		return errors.New(strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+func (v *Validator) plan(t reflect.Type) *validatePlan {
+	if p, ok := v.plans.Load(t); ok {
+		return p.(*validatePlan)
+	}
+	p, loaded := v.plans.LoadOrStore(t, v.newPlan(t))
+	plan := p.(*validatePlan)
+	if !loaded && plan.err != nil && v.report != nil {
+		v.report(plan.err)
+	}
+	return plan
+}
+
+// newPlan returns the fields of t to validate. Unknown rules are left out and
+// reported in the err of the plan.
+func (v *Validator) newPlan(t reflect.Type) *validatePlan {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	plan := &validatePlan{}
+	var unknown []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" { //unexported
+			continue
+		}
+		vf := validateField{index: i, name: field.Name}
+		tag := field.Tag.Get(validateTag)
+		if tag == "-" {
+			continue
+		}
+		rules := &vf.rules
+		for _, r := range strings.Split(tag, ",") {
+			r = strings.TrimSpace(r)
+			name, param := r, ""
+			if idx := strings.IndexByte(r, '='); idx >= 0 {
+				name, param = r[:idx], r[idx+1:]
+			}
+			switch name {
+			case "":
+				continue
+			case "required":
+				if rules == &vf.rules {
+					vf.required = true
+				} else {
+					vf.diveRequired = true
+				}
+				continue
+			case "omitempty":
+				if rules == &vf.rules {
+					vf.omitempty = true
+				}
+				continue
+			case "dive":
+				rules = &vf.dive
+				continue
+			}
+			fn, ok := v.rules[name]
+			if !ok {
+				unknown = append(unknown, fmt.Sprintf("%q on field %s.%s", name, t.Name(), field.Name))
+				continue
+			}
+			*rules = append(*rules, validateRule{name: name, param: param, fn: fn})
+		}
+		if tag == "" && !hasNestedStruct(field.Type) {
+			continue
+		}
+		plan.fields = append(plan.fields, vf)
+	}
+	if len(unknown) > 0 {
+		plan.err = errors.New("validate: unknown rule " + strings.Join(unknown, ", "))
+	}
+	return plan
+}
+
+func (v *Validator) validateStruct(val reflect.Value, path string, errs *ValidationErrors) error {
+	plan := v.plan(val.Type())
+	for i := range plan.fields {
+		vf := &plan.fields[i]
+		fieldVal := val.Field(vf.index)
+		name := vf.name
+		if path != "" {
+			name = path + "." + name
+		}
+		if isEmptyValue(fieldVal) {
+			if vf.required {
+				*errs = append(*errs, newValidationError(name, "required", "", fieldVal))
+			}
+			if vf.required || vf.omitempty || fieldVal.Kind() == reflect.Ptr {
+				continue
+			}
+		}
+		fieldVal = reflect.Indirect(fieldVal)
+		if !v.applyRules(fieldVal, val, name, vf.rules, errs) {
+			continue
+		}
+		if err := v.validateNested(fieldVal, val, name, vf, errs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *Validator) applyRules(fieldVal, parent reflect.Value, name string, rules []validateRule, errs *ValidationErrors) bool {
+	for _, r := range rules {
+		if !r.fn(fieldVal, r.param, parent) {
+			*errs = append(*errs, newValidationError(name, r.name, r.param, fieldVal))
+			return false
+		}
+	}
+	return true
+}
+
+// validateNested descends into structs and into the elements of slices, arrays and maps.
+func (v *Validator) validateNested(fieldVal, parent reflect.Value, name string, vf *validateField, errs *ValidationErrors) error {
+	dive := len(vf.dive) > 0 || vf.diveRequired
+	switch fieldVal.Kind() {
+	case reflect.Struct:
+		if fieldVal.Type() == timeType {
+			return nil
+		}
+		return v.validateStruct(fieldVal, name, errs)
+	case reflect.Slice, reflect.Array:
+		if !dive && !hasNestedStruct(fieldVal.Type().Elem()) {
+			return nil
+		}
+		for i := 0; i < fieldVal.Len(); i++ {
+			if err := v.validateElem(fieldVal.Index(i), parent, name+"["+strconv.Itoa(i)+"]", vf, errs); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if !dive && !hasNestedStruct(fieldVal.Type().Elem()) {
+			return nil
+		}
+		iter := fieldVal.MapRange()
+		for iter.Next() {
+			elemName := fmt.Sprintf("%s[%v]", name, iter.Key().Interface())
+			if err := v.validateElem(iter.Value(), parent, elemName, vf, errs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (v *Validator) validateElem(elem, parent reflect.Value, name string, vf *validateField, errs *ValidationErrors) error {
+	if isEmptyValue(elem) && vf.diveRequired {
+		*errs = append(*errs, newValidationError(name, "required", "", elem))
+		return nil
+	}
+	if elem.Kind() == reflect.Ptr || elem.Kind() == reflect.Interface {
+		if elem.IsNil() {
+			return nil
+		}
+		elem = elem.Elem()
+	}
+	if !v.applyRules(elem, parent, name, vf.dive, errs) {
+		return nil
+	}
+	if elem.Kind() == reflect.Struct && elem.Type() != timeType {
+		return v.validateStruct(elem, name, errs)
+	}
+	return nil
+}
+
+func hasNestedStruct(t reflect.Type) bool {
+	for {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+		case reflect.Struct:
+			return t != timeType
+		default:
+			return false
+		}
+	}
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return v.IsZero()
+}
+
+var ruleMessages = map[string]string{
+	"required":   "%s is required",
+	"min":        "%s must be at least %s",
+	"max":        "%s must be at most %s",
+	"len":        "%s must have length %s",
+	"eq":         "%s must be equal to %s",
+	"ne":         "%s must not be equal to %s",
+	"gt":         "%s must be greater than %s",
+	"gte":        "%s must be greater than or equal to %s",
+	"lt":         "%s must be less than %s",
+	"lte":        "%s must be less than or equal to %s",
+	"oneof":      "%s must be one of [%s]",
+	"email":      "%s must be a valid email address",
+	"url":        "%s must be a valid URL",
+	"ip":         "%s must be a valid IP address",
+	"uuid":       "%s must be a valid UUID",
+	"alpha":      "%s must contain only letters",
+	"alphanum":   "%s must contain only letters and digits",
+	"numeric":    "%s must be numeric",
+	"contains":   "%s must contain %s",
+	"excludes":   "%s must not contain %s",
+	"startswith": "%s must start with %s",
+	"endswith":   "%s must end with %s",
+	"eqfield":    "%s must be equal to %s",
+	"nefield":    "%s must not be equal to %s",
+	"gtfield":    "%s must be greater than %s",
+	"gtefield":   "%s must be greater than or equal to %s",
+	"ltfield":    "%s must be less than %s",
+	"ltefield":   "%s must be less than or equal to %s",
+}
+
+func newValidationError(field, rule, param string, value reflect.Value) *ValidationError {
+	var msg string
+	if format, ok := ruleMessages[rule]; ok {
+		if strings.Count(format, "%s") == 2 {
+			msg = fmt.Sprintf(format, field, param)
+		} else {
+			msg = fmt.Sprintf(format, field)
+		}
+	} else {
+		msg = fmt.Sprintf("%s failed on the %q rule", field, rule)
+	}
+	e := &ValidationError{
+		Field:   field,
+		Rule:    rule,
+		Param:   param,
+		Message: msg,
+	}
+	if value.IsValid() && value.CanInterface() {
+		e.Value = value.Interface()
+	}
+	return e
+}
+
+var (
+	uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+	builtinRules = map[string]RuleFunc{
+		"min":        sizeRule(func(c int) bool { return c >= 0 }),
+		"max":        sizeRule(func(c int) bool { return c <= 0 }),
+		"len":        sizeRule(func(c int) bool { return c == 0 }),
+		"eq":         valueRule(func(c int) bool { return c == 0 }),
+		"ne":         valueRule(func(c int) bool { return c != 0 }),
+		"gt":         sizeRule(func(c int) bool { return c > 0 }),
+		"gte":        sizeRule(func(c int) bool { return c >= 0 }),
+		"lt":         sizeRule(func(c int) bool { return c < 0 }),
+		"lte":        sizeRule(func(c int) bool { return c <= 0 }),
+		"eqfield":    fieldRule(func(c int) bool { return c == 0 }),
+		"nefield":    fieldRule(func(c int) bool { return c != 0 }),
+		"gtfield":    fieldRule(func(c int) bool { return c > 0 }),
+		"gtefield":   fieldRule(func(c int) bool { return c >= 0 }),
+		"ltfield":    fieldRule(func(c int) bool { return c < 0 }),
+		"ltefield":   fieldRule(func(c int) bool { return c <= 0 }),
+		"oneof":      ruleOneOf,
+		"email":      stringRule(isEmail),
+		"url":        stringRule(isURL),
+		"ip":         stringRule(func(s string) bool { return net.ParseIP(s) != nil }),
+		"uuid":       stringRule(uuidRegexp.MatchString),
+		"alpha":      stringRule(func(s string) bool { return isAll(s, unicode.IsLetter) }),
+		"alphanum":   stringRule(func(s string) bool { return isAll(s, isLetterOrDigit) }),
+		"numeric":    stringRule(isNumeric),
+		"contains":   stringParamRule(strings.Contains),
+		"excludes":   stringParamRule(func(s, p string) bool { return !strings.Contains(s, p) }),
+		"startswith": stringParamRule(strings.HasPrefix),
+		"endswith":   stringParamRule(strings.HasSuffix),
+	}
+)
+
+// sizeRule compares the length of strings, slices and maps, or the value of
+// numbers, with the rule parameter.
+func sizeRule(ok func(c int) bool) RuleFunc {
+	return func(field reflect.Value, param string, _ reflect.Value) bool {
+		switch field.Kind() {
+		case reflect.String:
+			return compareParam(float64(utf8.RuneCountInString(field.String())), param, ok)
+		case reflect.Slice, reflect.Map, reflect.Array:
+			return compareParam(float64(field.Len()), param, ok)
+		}
+		return valueRule(ok)(field, param, field)
+	}
+}
+
+// valueRule compares the value of strings and numbers with the rule parameter.
+func valueRule(ok func(c int) bool) RuleFunc {
+	return func(field reflect.Value, param string, _ reflect.Value) bool {
+		switch field.Kind() {
+		case reflect.String:
+			return ok(strings.Compare(field.String(), param))
+		case reflect.Slice, reflect.Map, reflect.Array:
+			return compareParam(float64(field.Len()), param, ok)
+		}
+		if f, isNum := numberOf(field); isNum {
+			return compareParam(f, param, ok)
+		}
+		return false
+	}
+}
+
+func compareParam(f float64, param string, ok func(c int) bool) bool {
+	p, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return false
+	}
+	return ok(compareFloat(f, p))
+}
+
+// fieldRule compares the field with the sibling field named by the rule parameter.
+func fieldRule(ok func(c int) bool) RuleFunc {
+	return func(field reflect.Value, param string, parent reflect.Value) bool {
+		other := reflect.Indirect(parent.FieldByName(param))
+		if !other.IsValid() {
+			return false
+		}
+		c, comparable := compareValues(field, other)
+		return comparable && ok(c)
+	}
+}
+
+func compareValues(a, b reflect.Value) (int, bool) {
+	if a.Type() == timeType && b.Type() == timeType {
+		ta, tb := a.Interface().(time.Time), b.Interface().(time.Time)
+		switch {
+		case ta.Before(tb):
+			return -1, true
+		case ta.After(tb):
+			return 1, true
+		}
+		return 0, true
+	}
+	if a.Kind() == reflect.String && b.Kind() == reflect.String {
+		return strings.Compare(a.String(), b.String()), true
+	}
+	if a.Kind() == reflect.Bool && b.Kind() == reflect.Bool {
+		if a.Bool() == b.Bool() {
+			return 0, true
+		}
+		return 1, true
+	}
+	fa, okA := numberOf(a)
+	fb, okB := numberOf(b)
+	if okA && okB {
+		return compareFloat(fa, fb), true
+	}
+	return 0, false
+}
+
+func numberOf(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func ruleOneOf(field reflect.Value, param string, _ reflect.Value) bool {
+	var s string
+	switch field.Kind() {
+	case reflect.String:
+		s = field.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s = strconv.FormatInt(field.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s = strconv.FormatUint(field.Uint(), 10)
+	default:
+		return false
+	}
+	for _, p := range strings.Fields(param) {
+		if p == s {
+			return true
+		}
+	}
+	return false
+}
+
+func stringRule(fn func(s string) bool) RuleFunc {
+	return func(field reflect.Value, _ string, _ reflect.Value) bool {
+		return field.Kind() == reflect.String && fn(field.String())
+	}
+}
+
+func stringParamRule(fn func(s, param string) bool) RuleFunc {
+	return func(field reflect.Value, param string, _ reflect.Value) bool {
+		return field.Kind() == reflect.String && fn(field.String(), param)
+	}
+}
+
+func isEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s && strings.Contains(s[strings.LastIndexByte(s, '@'):], ".")
+}
+
+func isURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != "" && (u.Host != "" || u.Opaque != "")
+}
+
+func isNumeric(s string) bool {
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
+
+func isLetterOrDigit(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isAll(s string, fn func(r rune) bool) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !fn(r) {
+			return false
+		}
+	}
+	return true
+}
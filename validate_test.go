@@ -0,0 +1,159 @@
+package bytego
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidator_Validate(t *testing.T) {
+	type address struct {
+		City string `validate:"required"`
+		Zip  string `validate:"omitempty,len=5,numeric"`
+	}
+	type user struct {
+		Name      string            `validate:"required,min=3,max=20"`
+		Email     string            `validate:"required,email"`
+		Role      string            `validate:"oneof=admin user"`
+		Age       int               `validate:"gte=18,lte=130"`
+		Password  string            `validate:"min=8"`
+		Confirm   string            `validate:"eqfield=Password"`
+		Tags      []string          `validate:"max=3,dive,alpha"`
+		Address   address           `validate:"required"`
+		Addresses []*address        `validate:"dive,required"`
+		Labels    map[string]string `validate:"dive,max=4"`
+		Nickname  *string           `validate:"omitempty,min=2"`
+	}
+	valid := func() *user {
+		return &user{
+			Name:      "bytego",
+			Email:     "dev@bytego.dev",
+			Role:      "admin",
+			Age:       30,
+			Password:  "secret-pw",
+			Confirm:   "secret-pw",
+			Tags:      []string{"go", "web"},
+			Address:   address{City: "Paris", Zip: "75001"},
+			Addresses: []*address{{City: "Lyon"}},
+			Labels:    map[string]string{"env": "prod"},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(u *user)
+		want   []string
+	}{
+		{"valid", func(u *user) {}, nil},
+		{"required", func(u *user) { u.Name = ""; u.Email = "" }, []string{"Name required", "Email required"}},
+		{"min max", func(u *user) { u.Name = "ab"; u.Age = 12 }, []string{"Name min", "Age gte"}},
+		{"email", func(u *user) { u.Email = "bytego" }, []string{"Email email"}},
+		{"oneof", func(u *user) { u.Role = "root" }, []string{"Role oneof"}},
+		{"cross field", func(u *user) { u.Confirm = "other-pw" }, []string{"Confirm eqfield"}},
+		{"slice", func(u *user) { u.Tags = []string{"go", "w3b"} }, []string{"Tags[1] alpha"}},
+		{"slice size", func(u *user) { u.Tags = []string{"a", "b", "c", "d"} }, []string{"Tags max"}},
+		{"nested struct", func(u *user) { u.Address.City = ""; u.Address.Zip = "1" }, []string{"Address.City required", "Address.Zip len"}},
+		{"nested slice", func(u *user) { u.Addresses = append(u.Addresses, nil, &address{}) },
+			[]string{"Addresses[1] required", "Addresses[2].City required"}},
+		{"map", func(u *user) { u.Labels["env"] = "production" }, []string{"Labels[env] max"}},
+		{"pointer", func(u *user) { s := "x"; u.Nickname = &s }, []string{"Nickname min"}},
+	}
+	v := NewValidator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := valid()
+			tt.modify(u)
+			err := v.Validate(u)
+			var got []string
+			if err != nil {
+				errs, ok := err.(ValidationErrors)
+				if !ok {
+					t.Fatalf("Validate() error = %v, want ValidationErrors", err)
+				}
+				for _, e := range errs {
+					got = append(got, e.Field+" "+e.Rule)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidator_RegisterRule(t *testing.T) {
+	type form struct {
+		Code string `validate:"even"`
+	}
+	v := NewValidator()
+	var reported []error
+	v.report = func(err error) {
+		reported = append(reported, err)
+	}
+	if err := v.Check([]*form{}); err == nil || !strings.Contains(err.Error(), `unknown rule "even" on field form.Code`) {
+		t.Fatalf("Check() error = %v, want unknown rule", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := v.Validate(&form{Code: "abc"}); err != nil {
+			t.Fatalf("Validate() error = %v, want unknown rule ignored", err)
+		}
+	}
+	if len(reported) != 1 {
+		t.Errorf("unknown rule reported %d times, want once", len(reported))
+	}
+	v.RegisterRule("even", func(field reflect.Value, _ string, _ reflect.Value) bool {
+		return field.Len()%2 == 0
+	})
+	if err := v.Validate(&form{Code: "ab"}); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if err := v.Validate(&form{Code: "abc"}); err == nil {
+		t.Error("Validate() error = nil, want even rule error")
+	}
+}
+
+func TestBind_validate(t *testing.T) {
+	type form struct {
+		Name string `query:"name" validate:"required,min=3"`
+	}
+	app := New()
+	app.SetValidator(app.Validator().Validate)
+	app.SetValidateTranslate(func(c *Ctx, err error) error {
+		if errs, ok := err.(ValidationErrors); ok {
+			for _, e := range errs {
+				e.Message = "invalid " + strings.ToLower(e.Field)
+			}
+		}
+		return err
+	})
+	rec := httptest.NewRecorder()
+	c := newTestCtx(app, httptest.NewRequest(http.MethodGet, "/?name=ab", nil))
+	c.writer = newResponseWriter(rec, app)
+	c.Response = c.writer
+	err := c.Bind(&form{})
+	if err == nil {
+		t.Fatal("Bind() error = nil")
+	}
+	c.HandleError(err)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), `"msg":"invalid name"`) {
+		t.Errorf("HandleError() = %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestBind_noValidator(t *testing.T) {
+	type form struct {
+		Name string `query:"name" validate:"required,min=3"`
+		ID   string `query:"id" validate:"uuid4_rfc4122"`
+	}
+	app := New()
+	c := newTestCtx(app, httptest.NewRequest(http.MethodGet, "/?id=1", nil))
+	v := &form{}
+	if err := c.Bind(v); err != nil {
+		t.Fatalf("Bind() error = %v, want no validation without SetValidator", err)
+	}
+	if v.ID != "1" {
+		t.Errorf("Bind() = %+v", v)
+	}
+}
@@ -0,0 +1,99 @@
+package websocket
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const dialTimeout = 10 * time.Second
+
+// Dial opens a client connection to a ws:// or wss:// URL. Subprotocols and
+// compression are requested with the Sec-WebSocket-Protocol and
+// Sec-WebSocket-Extensions headers. It is mainly meant for tests and tools.
+func Dial(rawURL string, header http.Header) (*Conn, *http.Response, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	host := u.Host
+	switch u.Scheme {
+	case "ws":
+		u.Scheme = "http"
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "80")
+		}
+	case "wss":
+		u.Scheme = "https"
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "443")
+		}
+	default:
+		return nil, nil, errors.New("websocket: bad scheme " + u.Scheme)
+	}
+
+	var netConn net.Conn
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	if u.Scheme == "https" {
+		netConn, err = tls.DialWithDialer(dialer, "tcp", host, &tls.Config{ServerName: u.Hostname()})
+	} else {
+		netConn, err = dialer.Dial("tcp", host)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var nonce [16]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		netConn.Close()
+		return nil, nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce[:])
+	req := &http.Request{
+		Method:     http.MethodGet,
+		URL:        u,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{},
+		Host:       u.Host,
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set(HeaderUpgrade, "websocket")
+	req.Header.Set(HeaderConnection, "Upgrade")
+	req.Header.Set(HeaderSecWebSocketKey, key)
+	req.Header.Set(HeaderSecWebSocketVersion, supportedVersion)
+
+	_ = netConn.SetDeadline(time.Now().Add(dialTimeout))
+	if err := req.Write(netConn); err != nil {
+		netConn.Close()
+		return nil, nil, err
+	}
+	br := bufio.NewReader(netConn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		netConn.Close()
+		return nil, nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols ||
+		!headerContainsToken(resp.Header, HeaderUpgrade, "websocket") ||
+		!headerContainsToken(resp.Header, HeaderConnection, "upgrade") ||
+		resp.Header.Get(HeaderSecWebSocketAccept) != computeAcceptKey(key) {
+		netConn.Close()
+		return nil, resp, errors.New("websocket: bad handshake")
+	}
+	_ = netConn.SetDeadline(time.Time{})
+
+	conn := newConn(netConn, br, false, DefaultConfig)
+	conn.subprotocol = resp.Header.Get(HeaderSecWebSocketProtocol)
+	conn.compress = offersCompression(resp.Header)
+	return conn, resp, nil
+}
@@ -0,0 +1,56 @@
+package websocket
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+	"strings"
+	"sync"
+)
+
+// deflateTail is removed from compressed messages and restored before
+// decompression (RFC 7692 7.2.1); the final empty block ends the stream.
+const (
+	deflateTail      = "\x00\x00\xff\xff"
+	deflateFinalTail = deflateTail + "\x01\x00\x00\xff\xff"
+)
+
+var flateWriterPool = sync.Pool{
+	New: func() interface{} {
+		w, _ := flate.NewWriter(nil, flate.BestSpeed)
+		return w
+	},
+}
+
+// compress deflates a message without context takeover.
+func compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	fw := flateWriterPool.Get().(*flate.Writer)
+	defer flateWriterPool.Put(fw)
+	fw.Reset(&buf)
+	if _, err := fw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := fw.Flush(); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte(deflateTail)), nil
+}
+
+// decompress inflates a message, failing with ErrReadLimit beyond limit bytes.
+func decompress(data []byte, limit int64) ([]byte, error) {
+	fr := flate.NewReader(io.MultiReader(bytes.NewReader(data), strings.NewReader(deflateFinalTail)))
+	defer fr.Close()
+	r := io.Reader(fr)
+	if limit > 0 {
+		r = io.LimitReader(fr, limit+1)
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && int64(len(b)) > limit {
+		return nil, ErrReadLimit
+	}
+	return b, nil
+}
@@ -0,0 +1,70 @@
+package websocket
+
+import (
+	"time"
+
+	"github.com/gostack-labs/bytego"
+)
+
+type Config struct {
+	// CheckOrigin reports whether the Origin of the handshake request is allowed.
+	// Default: the request has no Origin header or its host equals the request host.
+	CheckOrigin func(c *bytego.Ctx) bool
+
+	// Subprotocols lists the supported subprotocols in order of preference.
+	//
+	// Optional. Default value nil.
+	Subprotocols []string
+
+	// EnableCompression negotiates permessage-deflate when the client offers it.
+	//
+	// Optional. Default value false.
+	EnableCompression bool
+
+	// ReadLimit is the maximum size in bytes of a message read from the peer.
+	// Default: 32 MB
+	ReadLimit int64
+
+	// PingInterval is the interval of keepalive pings. Reads fail when no pong
+	// arrives within PongWait, so the handler must keep reading. Zero disables keepalive.
+	//
+	// Optional. Default value 0.
+	PingInterval time.Duration
+
+	// PongWait is how long to wait for a pong after a ping.
+	// Default: 2 * PingInterval
+	PongWait time.Duration
+
+	// WriteWait is the time allowed to write a control frame.
+	// Default: 10 seconds
+	WriteWait time.Duration
+}
+
+// defaultReadLimit bounds reads when no positive limit is set.
+const defaultReadLimit = 32 << 20
+
+var DefaultConfig = Config{
+	CheckOrigin: checkSameOrigin,
+	ReadLimit:   defaultReadLimit,
+	WriteWait:   10 * time.Second,
+}
+
+func configDefault(config ...Config) Config {
+	if len(config) == 0 {
+		return DefaultConfig
+	}
+	cfg := config[0]
+	if cfg.CheckOrigin == nil {
+		cfg.CheckOrigin = DefaultConfig.CheckOrigin
+	}
+	if cfg.ReadLimit <= 0 {
+		cfg.ReadLimit = DefaultConfig.ReadLimit
+	}
+	if cfg.WriteWait <= 0 {
+		cfg.WriteWait = DefaultConfig.WriteWait
+	}
+	if cfg.PingInterval > 0 && cfg.PongWait <= 0 {
+		cfg.PongWait = 2 * cfg.PingInterval
+	}
+	return cfg
+}
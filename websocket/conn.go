@@ -0,0 +1,543 @@
+package websocket
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Message types, the opcodes of RFC 6455 section 11.8.
+const (
+	continuationFrame = 0
+	TextMessage       = 1
+	BinaryMessage     = 2
+	CloseMessage      = 8
+	PingMessage       = 9
+	PongMessage       = 10
+)
+
+// Close codes of RFC 6455 section 7.4.1.
+const (
+	CloseNormalClosure      = 1000
+	CloseGoingAway          = 1001
+	CloseProtocolError      = 1002
+	CloseUnsupportedData    = 1003
+	CloseNoStatusReceived   = 1005
+	CloseAbnormalClosure    = 1006
+	CloseInvalidPayloadData = 1007
+	ClosePolicyViolation    = 1008
+	CloseMessageTooBig      = 1009
+	CloseInternalServerErr  = 1011
+)
+
+const (
+	finalBit = 1 << 7
+	rsv1Bit  = 1 << 6
+	rsv2Bit  = 1 << 5
+	rsv3Bit  = 1 << 4
+	maskBit  = 1 << 7
+
+	maxControlPayload = 125
+)
+
+var (
+	ErrCloseSent   = errors.New("websocket: close sent")
+	ErrReadLimit   = errors.New("websocket: read limit exceeded")
+	errInvalidUTF8 = errors.New("websocket: invalid UTF-8 in text message")
+)
+
+// CloseError is returned by read methods when the peer closes the connection.
+type CloseError struct {
+	Code int
+	Text string
+}
+
+func (e *CloseError) Error() string {
+	return "websocket: close " + strconv.Itoa(e.Code) + " " + e.Text
+}
+
+// IsCloseError reports whether err is a CloseError with one of the given codes.
+func IsCloseError(err error, codes ...int) bool {
+	var ce *CloseError
+	if !errors.As(err, &ce) {
+		return false
+	}
+	for _, code := range codes {
+		if ce.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Conn is a WebSocket connection. One goroutine may read while others write;
+// writes are serialized.
+type Conn struct {
+	conn        net.Conn
+	br          *bufio.Reader
+	isServer    bool
+	subprotocol string
+	compress    bool
+
+	readLimit   int64
+	readErr     error
+	pingHandler func(appData string) error
+	pongHandler func(appData string) error
+
+	writeMu       sync.Mutex
+	writeDeadline time.Time
+	writeWait     time.Duration
+	closeSent     bool
+
+	pingInterval time.Duration
+	pongWait     time.Duration
+	closeOnce    sync.Once
+	done         chan struct{}
+}
+
+func newConn(conn net.Conn, br *bufio.Reader, isServer bool, cfg Config) *Conn {
+	if br == nil {
+		br = bufio.NewReader(conn)
+	}
+	c := &Conn{
+		conn:         conn,
+		br:           br,
+		isServer:     isServer,
+		writeWait:    cfg.WriteWait,
+		pingInterval: cfg.PingInterval,
+		pongWait:     cfg.PongWait,
+		done:         make(chan struct{}),
+	}
+	c.SetReadLimit(cfg.ReadLimit)
+	c.pingHandler = c.defaultPingHandler
+	c.pongHandler = c.defaultPongHandler
+	return c
+}
+
+// startKeepalive pings the peer every pingInterval and expects a pong within pongWait.
+func (c *Conn) startKeepalive() {
+	if c.pingInterval <= 0 {
+		return
+	}
+	_ = c.conn.SetReadDeadline(time.Now().Add(c.pongWait))
+	go func() {
+		ticker := time.NewTicker(c.pingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := c.WriteControl(PingMessage, nil, time.Now().Add(c.writeWait)); err != nil {
+					return
+				}
+			case <-c.done:
+				return
+			}
+		}
+	}()
+}
+
+func (c *Conn) defaultPingHandler(appData string) error {
+	err := c.WriteControl(PongMessage, []byte(appData), time.Now().Add(c.writeWait))
+	if err == ErrCloseSent {
+		return nil
+	}
+	return err
+}
+
+func (c *Conn) defaultPongHandler(string) error {
+	if c.pongWait > 0 {
+		return c.conn.SetReadDeadline(time.Now().Add(c.pongWait))
+	}
+	return nil
+}
+
+func (c *Conn) Subprotocol() string {
+	return c.subprotocol
+}
+
+func (c *Conn) LocalAddr() net.Addr {
+	return c.conn.LocalAddr()
+}
+
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// NetConn returns the underlying connection.
+func (c *Conn) NetConn() net.Conn {
+	return c.conn
+}
+
+// SetReadLimit sets the maximum size in bytes of a message read from the peer.
+// A limit <= 0 restores the default of 32 MB.
+func (c *Conn) SetReadLimit(limit int64) {
+	if limit <= 0 {
+		limit = defaultReadLimit
+	}
+	c.readLimit = limit
+}
+
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+// SetWriteDeadline sets the deadline of data message writes.
+func (c *Conn) SetWriteDeadline(t time.Time) error {
+	c.writeMu.Lock()
+	c.writeDeadline = t
+	c.writeMu.Unlock()
+	return nil
+}
+
+// SetPingHandler sets the handler of ping messages, called from read methods.
+// The default handler answers with a pong.
+func (c *Conn) SetPingHandler(h func(appData string) error) {
+	if h == nil {
+		h = c.defaultPingHandler
+	}
+	c.pingHandler = h
+}
+
+// SetPongHandler sets the handler of pong messages, called from read methods.
+// The default handler extends the read deadline by PongWait when keepalive is on.
+func (c *Conn) SetPongHandler(h func(appData string) error) {
+	if h == nil {
+		h = c.defaultPongHandler
+	}
+	c.pongHandler = h
+}
+
+// EnableWriteCompression toggles compression of written messages when
+// permessage-deflate was negotiated.
+func (c *Conn) EnableWriteCompression(enable bool) {
+	c.writeMu.Lock()
+	c.compress = enable && c.compress
+	c.writeMu.Unlock()
+}
+
+type frameHeader struct {
+	fin    bool
+	rsv1   bool
+	opcode int
+	length int64
+	mask   [4]byte
+	masked bool
+}
+
+func (c *Conn) readFrameHeader() (frameHeader, error) {
+	var h frameHeader
+	var b [8]byte
+	if _, err := io.ReadFull(c.br, b[:2]); err != nil {
+		return h, err
+	}
+	h.fin = b[0]&finalBit != 0
+	h.rsv1 = b[0]&rsv1Bit != 0
+	h.opcode = int(b[0] & 0xf)
+	h.masked = b[1]&maskBit != 0
+	h.length = int64(b[1] & 0x7f)
+
+	if b[0]&(rsv2Bit|rsv3Bit) != 0 || (h.rsv1 && !c.compress) {
+		return h, c.protocolError("unexpected reserved bits")
+	}
+	if h.masked != c.isServer {
+		return h, c.protocolError("bad frame masking")
+	}
+	switch h.length {
+	case 126:
+		if _, err := io.ReadFull(c.br, b[:2]); err != nil {
+			return h, err
+		}
+		h.length = int64(binary.BigEndian.Uint16(b[:2]))
+	case 127:
+		if _, err := io.ReadFull(c.br, b[:8]); err != nil {
+			return h, err
+		}
+		h.length = int64(binary.BigEndian.Uint64(b[:8]))
+		if h.length < 0 {
+			return h, c.protocolError("invalid payload length")
+		}
+	}
+	if h.masked {
+		if _, err := io.ReadFull(c.br, h.mask[:]); err != nil {
+			return h, err
+		}
+	}
+	if h.opcode >= CloseMessage {
+		if !h.fin || h.length > maxControlPayload || h.rsv1 {
+			return h, c.protocolError("invalid control frame")
+		}
+	}
+	return h, nil
+}
+
+func (c *Conn) readPayload(h frameHeader) ([]byte, error) {
+	payload := make([]byte, h.length)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return nil, err
+	}
+	if h.masked {
+		maskBytes(h.mask, payload)
+	}
+	return payload, nil
+}
+
+// ReadMessage reads the next data message, handling control frames on the way.
+func (c *Conn) ReadMessage() (messageType int, p []byte, err error) {
+	if c.readErr != nil {
+		return 0, nil, c.readErr
+	}
+	messageType, p, err = c.readMessage()
+	if err != nil {
+		c.readErr = err
+	}
+	return
+}
+
+func (c *Conn) readMessage() (int, []byte, error) {
+	var (
+		messageType int
+		compressed  bool
+		message     []byte
+	)
+	for {
+		h, err := c.readFrameHeader()
+		if err != nil {
+			return 0, nil, err
+		}
+		if h.opcode < CloseMessage && int64(len(message))+h.length > c.readLimit {
+			c.writeCloseFrame(CloseMessageTooBig, "")
+			return 0, nil, ErrReadLimit
+		}
+		payload, err := c.readPayload(h)
+		if err != nil {
+			return 0, nil, err
+		}
+		switch h.opcode {
+		case PingMessage:
+			if err := c.pingHandler(string(payload)); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case PongMessage:
+			if err := c.pongHandler(string(payload)); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case CloseMessage:
+			return 0, nil, c.handleClose(payload)
+		case TextMessage, BinaryMessage:
+			if messageType != 0 {
+				return 0, nil, c.protocolError("expected continuation frame")
+			}
+			messageType = h.opcode
+			compressed = h.rsv1
+		case continuationFrame:
+			if messageType == 0 || h.rsv1 {
+				return 0, nil, c.protocolError("unexpected continuation frame")
+			}
+		default:
+			return 0, nil, c.protocolError("unknown opcode " + strconv.Itoa(h.opcode))
+		}
+		message = append(message, payload...)
+		if h.fin {
+			break
+		}
+	}
+	if compressed {
+		var err error
+		if message, err = decompress(message, c.readLimit); err != nil {
+			if err == ErrReadLimit {
+				c.writeCloseFrame(CloseMessageTooBig, "")
+			} else {
+				c.writeCloseFrame(CloseInvalidPayloadData, "")
+			}
+			return 0, nil, err
+		}
+	}
+	if messageType == TextMessage && !utf8.Valid(message) {
+		c.writeCloseFrame(CloseInvalidPayloadData, "")
+		return 0, nil, errInvalidUTF8
+	}
+	return messageType, message, nil
+}
+
+func (c *Conn) handleClose(payload []byte) error {
+	closeErr := &CloseError{Code: CloseNoStatusReceived}
+	if len(payload) == 1 {
+		return c.protocolError("invalid close payload")
+	}
+	if len(payload) >= 2 {
+		closeErr.Code = int(binary.BigEndian.Uint16(payload))
+		closeErr.Text = string(payload[2:])
+		if !validCloseCode(closeErr.Code) || !utf8.Valid(payload[2:]) {
+			return c.protocolError("invalid close payload")
+		}
+	}
+	if closeErr.Code == CloseNoStatusReceived {
+		c.writeCloseFrame(CloseNormalClosure, "")
+	} else {
+		c.writeCloseFrame(closeErr.Code, "")
+	}
+	return closeErr
+}
+
+func validCloseCode(code int) bool {
+	switch {
+	case code >= 1000 && code <= 1003, code >= 1007 && code <= 1011, code >= 3000 && code <= 4999:
+		return true
+	}
+	return false
+}
+
+func (c *Conn) protocolError(msg string) error {
+	c.writeCloseFrame(CloseProtocolError, "")
+	return errors.New("websocket: protocol error: " + msg)
+}
+
+// ReadJSON reads the next message and decodes it as JSON into v.
+func (c *Conn) ReadJSON(v interface{}) error {
+	_, p, err := c.ReadMessage()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(p, v)
+}
+
+// WriteMessage writes data as a single message of the given type.
+func (c *Conn) WriteMessage(messageType int, data []byte) error {
+	if messageType != TextMessage && messageType != BinaryMessage {
+		return c.WriteControl(messageType, data, time.Now().Add(c.writeWait))
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	rsv1 := false
+	if c.compress {
+		var err error
+		if data, err = compress(data); err != nil {
+			return err
+		}
+		rsv1 = true
+	}
+	return c.writeFrame(messageType, rsv1, data, c.writeDeadline)
+}
+
+// WriteJSON writes the JSON encoding of v as a text message.
+func (c *Conn) WriteJSON(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.WriteMessage(TextMessage, b)
+}
+
+// WriteControl writes a close, ping or pong message with the given deadline.
+func (c *Conn) WriteControl(messageType int, data []byte, deadline time.Time) error {
+	if messageType != CloseMessage && messageType != PingMessage && messageType != PongMessage {
+		return fmt.Errorf("websocket: invalid control message type %d", messageType)
+	}
+	if len(data) > maxControlPayload {
+		return errors.New("websocket: control payload too large")
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.writeFrame(messageType, false, data, deadline)
+}
+
+// WriteClose starts the closing handshake with the given code and reason.
+func (c *Conn) WriteClose(code int, text string) error {
+	return c.WriteControl(CloseMessage, closePayload(code, text), time.Now().Add(c.writeWait))
+}
+
+func (c *Conn) writeCloseFrame(code int, text string) {
+	_ = c.WriteClose(code, text)
+}
+
+func closePayload(code int, text string) []byte {
+	if code == CloseNoStatusReceived {
+		return nil
+	}
+	payload := make([]byte, 2+len(text))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	copy(payload[2:], text)
+	return payload
+}
+
+// writeFrame writes a final frame; c.writeMu must be held.
+func (c *Conn) writeFrame(opcode int, rsv1 bool, payload []byte, deadline time.Time) error {
+	if c.closeSent {
+		return ErrCloseSent
+	}
+	header := make([]byte, 2, 14)
+	header[0] = finalBit | byte(opcode)
+	if rsv1 {
+		header[0] |= rsv1Bit
+	}
+	length := len(payload)
+	switch {
+	case length <= 125:
+		header[1] = byte(length)
+	case length <= 0xffff:
+		header[1] = 126
+		header = append(header, byte(length>>8), byte(length))
+	default:
+		header[1] = 127
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], uint64(length))
+		header = append(header, b[:]...)
+	}
+	if !c.isServer {
+		// clients mask every frame with a fresh key (RFC 6455 5.3)
+		var key [4]byte
+		if _, err := rand.Read(key[:]); err != nil {
+			return err
+		}
+		header[1] |= maskBit
+		header = append(header, key[:]...)
+		masked := make([]byte, length)
+		copy(masked, payload)
+		maskBytes(key, masked)
+		payload = masked
+	}
+	if err := c.conn.SetWriteDeadline(deadline); err != nil {
+		return err
+	}
+	buffers := net.Buffers{header, payload}
+	if _, err := buffers.WriteTo(c.conn); err != nil {
+		return err
+	}
+	if opcode == CloseMessage {
+		c.closeSent = true
+	}
+	return nil
+}
+
+func maskBytes(key [4]byte, b []byte) {
+	for i := range b {
+		b[i] ^= key[i&3]
+	}
+}
+
+// Close sends a normal close message, when none was sent yet, and closes the
+// underlying connection.
+func (c *Conn) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.writeMu.Lock()
+		if !c.closeSent {
+			_ = c.writeFrame(CloseMessage, false, closePayload(CloseNormalClosure, ""), time.Now().Add(c.writeWait))
+		}
+		c.writeMu.Unlock()
+		err = c.conn.Close()
+	})
+	return err
+}
@@ -0,0 +1,176 @@
+// Package websocket implements the WebSocket protocol (RFC 6455) for bytego handlers.
+package websocket
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gostack-labs/bytego"
+	"github.com/gostack-labs/bytego/internal/respctl"
+)
+
+const (
+	HeaderUpgrade                = "Upgrade"
+	HeaderConnection             = "Connection"
+	HeaderSecWebSocketKey        = "Sec-WebSocket-Key"
+	HeaderSecWebSocketVersion    = "Sec-WebSocket-Version"
+	HeaderSecWebSocketAccept     = "Sec-WebSocket-Accept"
+	HeaderSecWebSocketProtocol   = "Sec-WebSocket-Protocol"
+	HeaderSecWebSocketExtensions = "Sec-WebSocket-Extensions"
+
+	acceptGUID         = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	compressExtension  = "permessage-deflate"
+	compressResponse   = "permessage-deflate; server_no_context_takeover; client_no_context_takeover"
+	supportedVersion   = "13"
+	handshakeWriteWait = 10 * time.Second
+)
+
+var (
+	ErrBadHandshake     = bytego.NewHTTPError(http.StatusBadRequest, "websocket: bad handshake")
+	ErrOriginNotAllowed = bytego.NewHTTPError(http.StatusForbidden, "websocket: origin not allowed")
+	ErrBadVersion       = bytego.NewHTTPError(http.StatusUpgradeRequired, "websocket: unsupported version")
+)
+
+// Handler serves an upgraded connection. The Ctx stays valid until it returns.
+type Handler func(c *bytego.Ctx, conn *Conn)
+
+// New returns a handler that upgrades the request and calls handler with the
+// connection, which is closed when handler returns. Handshake failures are
+// returned to the error handler.
+func New(handler Handler, config ...Config) bytego.HandlerFunc {
+	cfg := configDefault(config...)
+	return func(c *bytego.Ctx) error {
+		conn, err := upgrade(c, cfg)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		handler(c, conn)
+		return nil
+	}
+}
+
+// Upgrade upgrades the request of c to a WebSocket connection.
+func Upgrade(c *bytego.Ctx, config ...Config) (*Conn, error) {
+	return upgrade(c, configDefault(config...))
+}
+
+// IsWebSocketUpgrade reports whether the request asks for a WebSocket upgrade.
+func IsWebSocketUpgrade(c *bytego.Ctx) bool {
+	return headerContainsToken(c.Request.Header, HeaderConnection, "upgrade") &&
+		headerContainsToken(c.Request.Header, HeaderUpgrade, "websocket")
+}
+
+func upgrade(c *bytego.Ctx, cfg Config) (*Conn, error) {
+	if c.Request.Method != http.MethodGet || !IsWebSocketUpgrade(c) {
+		return nil, ErrBadHandshake
+	}
+	if c.Header(HeaderSecWebSocketVersion) != supportedVersion {
+		c.SetHeader(HeaderSecWebSocketVersion, supportedVersion)
+		return nil, ErrBadVersion
+	}
+	key := c.Header(HeaderSecWebSocketKey)
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		return nil, ErrBadHandshake
+	}
+	if !cfg.CheckOrigin(c) {
+		return nil, ErrOriginNotAllowed
+	}
+	subprotocol := selectSubprotocol(c.Request.Header, cfg.Subprotocols)
+	compress := cfg.EnableCompression && offersCompression(c.Request.Header)
+
+	netConn, brw, err := respctl.Hijack(c.Response)
+	if err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	b.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+	b.WriteString(HeaderSecWebSocketAccept + ": " + computeAcceptKey(key) + "\r\n")
+	if subprotocol != "" {
+		b.WriteString(HeaderSecWebSocketProtocol + ": " + subprotocol + "\r\n")
+	}
+	if compress {
+		b.WriteString(HeaderSecWebSocketExtensions + ": " + compressResponse + "\r\n")
+	}
+	b.WriteString("\r\n")
+	_ = netConn.SetWriteDeadline(time.Now().Add(handshakeWriteWait))
+	if _, err := netConn.Write([]byte(b.String())); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	_ = netConn.SetWriteDeadline(time.Time{})
+
+	conn := newConn(netConn, brw.Reader, true, cfg)
+	conn.subprotocol = subprotocol
+	conn.compress = compress
+	conn.startKeepalive()
+	return conn, nil
+}
+
+func computeAcceptKey(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func checkSameOrigin(c *bytego.Ctx) bool {
+	origin := c.Header(bytego.HeaderOrigin)
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, c.Request.Host)
+}
+
+func selectSubprotocol(header http.Header, supported []string) string {
+	offered := headerTokens(header, HeaderSecWebSocketProtocol)
+	for _, s := range supported {
+		for _, o := range offered {
+			if s == o {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+func offersCompression(header http.Header) bool {
+	for _, ext := range headerTokens(header, HeaderSecWebSocketExtensions) {
+		name := ext
+		if i := strings.IndexByte(ext, ';'); i >= 0 {
+			name = ext[:i]
+		}
+		if strings.EqualFold(strings.TrimSpace(name), compressExtension) {
+			return true
+		}
+	}
+	return false
+}
+
+func headerTokens(header http.Header, name string) []string {
+	var tokens []string
+	for _, v := range header[http.CanonicalHeaderKey(name)] {
+		for _, t := range strings.Split(v, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				tokens = append(tokens, t)
+			}
+		}
+	}
+	return tokens
+}
+
+func headerContainsToken(header http.Header, name, token string) bool {
+	for _, t := range headerTokens(header, name) {
+		if strings.EqualFold(t, token) {
+			return true
+		}
+	}
+	return false
+}
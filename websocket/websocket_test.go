@@ -0,0 +1,186 @@
+package websocket
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gostack-labs/bytego"
+)
+
+func echoHandler(c *bytego.Ctx, conn *Conn) {
+	for {
+		mt, p, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if err := conn.WriteMessage(mt, p); err != nil {
+			return
+		}
+	}
+}
+
+func newServer(t *testing.T, handler Handler, config ...Config) (*httptest.Server, string) {
+	app := bytego.New()
+	app.GET("/ws", New(handler, config...))
+	srv := httptest.NewServer(app.Handler())
+	t.Cleanup(srv.Close)
+	return srv, "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws"
+}
+
+func TestEcho(t *testing.T) {
+	_, url := newServer(t, echoHandler, Config{
+		Subprotocols:      []string{"chat.v2", "chat.v1"},
+		EnableCompression: true,
+	})
+	tests := []struct {
+		name   string
+		header http.Header
+	}{
+		{"plain", nil},
+		{"subprotocol", http.Header{HeaderSecWebSocketProtocol: {"chat.v1, chat.v2"}}},
+		{"compression", http.Header{HeaderSecWebSocketExtensions: {"permessage-deflate; client_max_window_bits"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, _, err := Dial(url, tt.header)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			if tt.header.Get(HeaderSecWebSocketProtocol) != "" && conn.Subprotocol() != "chat.v2" {
+				t.Errorf("Subprotocol() = %q", conn.Subprotocol())
+			}
+			if tt.header.Get(HeaderSecWebSocketExtensions) != "" && !conn.compress {
+				t.Error("compression not negotiated")
+			}
+			large := bytes.Repeat([]byte("bytego "), 20000)
+			for _, msg := range []struct {
+				mt   int
+				data []byte
+			}{{TextMessage, []byte("hello")}, {BinaryMessage, []byte{0, 1, 2}}, {BinaryMessage, large}} {
+				if err := conn.WriteMessage(msg.mt, msg.data); err != nil {
+					t.Fatal(err)
+				}
+				mt, p, err := conn.ReadMessage()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if mt != msg.mt || !bytes.Equal(p, msg.data) {
+					t.Errorf("ReadMessage() = %d %d bytes, want %d %d bytes", mt, len(p), msg.mt, len(msg.data))
+				}
+			}
+		})
+	}
+}
+
+func TestHandshakeErrors(t *testing.T) {
+	srv, url := newServer(t, echoHandler)
+	resp, err := http.Get(srv.URL + "/ws")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("plain GET status = %d", resp.StatusCode)
+	}
+
+	_, resp, err = Dial(url, http.Header{bytego.HeaderOrigin: {"https://evil.example"}})
+	if err == nil || resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Errorf("cross origin Dial() = %v, %v", resp, err)
+	}
+}
+
+func TestCloseAndReadLimit(t *testing.T) {
+	errc := make(chan error, 1)
+	_, url := newServer(t, func(c *bytego.Ctx, conn *Conn) {
+		_, _, err := conn.ReadMessage()
+		errc <- err
+	}, Config{ReadLimit: 16})
+
+	conn, _, err := Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.WriteClose(CloseGoingAway, "bye"); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errc; !IsCloseError(err, CloseGoingAway) {
+		t.Errorf("server ReadMessage() error = %v", err)
+	}
+	conn.Close()
+
+	conn, _, err = Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.WriteMessage(TextMessage, bytes.Repeat([]byte("x"), 17))
+	if err := <-errc; err != ErrReadLimit {
+		t.Errorf("server ReadMessage() error = %v, want ErrReadLimit", err)
+	}
+	if _, _, err := conn.ReadMessage(); !IsCloseError(err, CloseMessageTooBig) {
+		t.Errorf("client ReadMessage() error = %v", err)
+	}
+}
+
+func TestReadLimit_Default(t *testing.T) {
+	errc := make(chan error, 1)
+	_, url := newServer(t, func(c *bytego.Ctx, conn *Conn) {
+		conn.SetReadLimit(0)
+		_, _, err := conn.ReadMessage()
+		errc <- err
+	})
+	conn, _, err := Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	// a masked text frame announcing 1 TB, which must be refused before any allocation
+	header := []byte{finalBit | TextMessage, maskBit | 127, 0, 0, 1, 0, 0, 0, 0, 0, 1, 2, 3, 4}
+	if _, err := conn.NetConn().Write(header); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errc; err != ErrReadLimit {
+		t.Errorf("server ReadMessage() error = %v, want ErrReadLimit", err)
+	}
+	if _, _, err := conn.ReadMessage(); !IsCloseError(err, CloseMessageTooBig) {
+		t.Errorf("client ReadMessage() error = %v", err)
+	}
+}
+
+func TestKeepalive(t *testing.T) {
+	_, url := newServer(t, func(c *bytego.Ctx, conn *Conn) {
+		// pongs are processed by the read loop
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			_ = conn.WriteMessage(TextMessage, []byte("done"))
+		}()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}, Config{PingInterval: 10 * time.Millisecond})
+
+	conn, _, err := Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	pings := 0
+	conn.SetPingHandler(func(appData string) error {
+		pings++
+		return conn.WriteControl(PongMessage, []byte(appData), time.Now().Add(time.Second))
+	})
+	_, p, err := conn.ReadMessage()
+	if err != nil || string(p) != "done" {
+		t.Fatalf("ReadMessage() = %q, %v", p, err)
+	}
+	if pings == 0 {
+		t.Error("no keepalive ping received")
+	}
+}